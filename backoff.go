@@ -1,6 +1,16 @@
 package retry
 
 import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
@@ -21,18 +31,395 @@ func (b BackoffFunc) Next() (time.Duration, bool) {
 	return b()
 }
 
+// Resettable is a Backoff that can be rewound to its initial state, allowing a
+// single instance to be reused across independent operations. The built-in
+// stateful backoffs implement Resettable, and the built-in middleware
+// propagates Reset to the backoff it wraps.
+type Resettable interface {
+	Backoff
+
+	// Reset returns the backoff to its initial state.
+	Reset()
+}
+
+// Cloneable is a Backoff that can be duplicated into an independent instance.
+// The built-in backoffs and middleware implement Cloneable.
+type Cloneable interface {
+	Backoff
+
+	// Clone returns an independent copy of the backoff, including its current
+	// state.
+	Clone() Backoff
+}
+
+// Clone returns an independent copy of b, including every backoff in a
+// middleware chain, so that the copy can be used without affecting b. This is
+// useful for handing the same configured backoff to multiple concurrent retry
+// loops. Clone copies the current state (such as the attempt count), so cloning
+// a fresh backoff is usually the intent.
+//
+// If b does not implement Cloneable, such as a BackoffFunc, it is returned
+// as-is, since any state it captures cannot be copied.
+func Clone(b Backoff) Backoff {
+	if c, ok := b.(Cloneable); ok {
+		return c.Clone()
+	}
+	return b
+}
+
+// Default returns a new backoff with defaults that suit most remote calls, so
+// it can be used as retry.Do(ctx, retry.Default(), f). It is equivalent to:
+//
+//	b, _ := NewFullJitter(100*time.Millisecond, 10*time.Second)
+//	b = WithMaxRetries(10, b)
+//
+// That is, each wait is a random value between zero and an exponentially
+// growing ceiling starting at 100ms and capped at 10s, and the function is
+// invoked at most 11 times. Each call returns a new, independent instance, so
+// the result can be used by a single retry loop without sharing state.
+func Default() Backoff {
+	b, err := NewFullJitter(100*time.Millisecond, 10*time.Second)
+	if err != nil {
+		panic(err)
+	}
+	return WithMaxRetries(10, b)
+}
+
+var (
+	_ Resettable = (*resettableBackoff)(nil)
+	_ Cloneable  = (*resettableBackoff)(nil)
+)
+
+// resettableBackoff is a BackoffFunc with functions to reset, clone, and
+// describe its state, and optionally to observe the error that triggered the
+// next call to Next and the deadline of the retry loop's context, and to report
+// the maximum number of attempts it allows.
+type resettableBackoff struct {
+	next     BackoffFunc
+	reset    func()
+	clone    func() Backoff
+	str      func() string
+	observe  func(err error)
+	deadline func(deadline time.Time)
+	limit    func() int
+}
+
+// errorObserver is implemented by backoffs that need to know which error
+// triggered a retry. The retry loop calls observeError with the error returned
+// by the function right before it calls Next.
+type errorObserver interface {
+	observeError(err error)
+}
+
+var _ errorObserver = (*resettableBackoff)(nil)
+
+// observeError passes err to b if it implements errorObserver.
+func observeError(b Backoff, err error) {
+	if o, ok := b.(errorObserver); ok {
+		o.observeError(err)
+	}
+}
+
+// observeError implements errorObserver.
+func (b *resettableBackoff) observeError(err error) {
+	if b.observe != nil {
+		b.observe(err)
+	}
+}
+
+// deadlineObserver is implemented by backoffs that need to know the deadline
+// of the retry loop's context. The retry loop calls observeDeadline with it
+// right before it calls Next, passing the zero time if the context has no
+// deadline, so a backoff shared between loops never keeps a stale one.
+type deadlineObserver interface {
+	observeDeadline(deadline time.Time)
+}
+
+var _ deadlineObserver = (*resettableBackoff)(nil)
+
+// observeDeadline passes deadline to b if it implements deadlineObserver.
+func observeDeadline(b Backoff, deadline time.Time) {
+	if o, ok := b.(deadlineObserver); ok {
+		o.observeDeadline(deadline)
+	}
+}
+
+// observeDeadline implements deadlineObserver.
+func (b *resettableBackoff) observeDeadline(deadline time.Time) {
+	if b.deadline != nil {
+		b.deadline(deadline)
+	}
+}
+
+// attemptLimiter is implemented by backoffs that know the maximum number of
+// attempts, including the first, that a retry loop using them can make. It
+// returns -1 if the number is unknown or unlimited.
+type attemptLimiter interface {
+	maxAttempts() int
+}
+
+var _ attemptLimiter = (*resettableBackoff)(nil)
+
+// maxAttemptsOf returns the maximum number of attempts b allows, or -1 if b
+// does not implement attemptLimiter.
+func maxAttemptsOf(b Backoff) int {
+	if l, ok := b.(attemptLimiter); ok {
+		return l.maxAttempts()
+	}
+	return -1
+}
+
+// maxAttempts implements attemptLimiter.
+func (b *resettableBackoff) maxAttempts() int {
+	if b.limit == nil {
+		return -1
+	}
+	return b.limit()
+}
+
+// unlimited is a limit func for middleware that can allow any number of
+// attempts, whatever the backoff it wraps allows.
+func unlimited() int {
+	return -1
+}
+
+// extendLimit returns a limit func that adds extra to the maximum number of
+// attempts b allows, if it is known.
+func extendLimit(b Backoff, extra int) func() int {
+	return func() int {
+		n := maxAttemptsOf(b)
+		if n < 0 || n > math.MaxInt-extra {
+			return -1
+		}
+		return n + extra
+	}
+}
+
+// withLimit replaces the maximum number of attempts reported by b, which must
+// have been returned by withReset.
+func withLimit(b Backoff, limit func() int) Backoff {
+	b.(*resettableBackoff).limit = limit
+	return b
+}
+
+// withNext returns a backoff that calls next on Next and forwards the hooks of
+// the retry loop to inner. Do variants that intercept calls to Next use it so
+// that middleware relying on those hooks keeps working.
+func withNext(inner Backoff, next BackoffFunc) Backoff {
+	return &nextBackoff{inner: inner, next: next}
+}
+
+type nextBackoff struct {
+	inner Backoff
+	next  BackoffFunc
+}
+
+// Next implements Backoff.
+func (b *nextBackoff) Next() (time.Duration, bool) {
+	return b.next()
+}
+
+// observeError implements errorObserver.
+func (b *nextBackoff) observeError(err error) {
+	observeError(b.inner, err)
+}
+
+// observeDeadline implements deadlineObserver.
+func (b *nextBackoff) observeDeadline(deadline time.Time) {
+	observeDeadline(b.inner, deadline)
+}
+
+// maxAttempts implements attemptLimiter.
+func (b *nextBackoff) maxAttempts() int {
+	return maxAttemptsOf(b.inner)
+}
+
+// Next implements Backoff.
+func (b *resettableBackoff) Next() (time.Duration, bool) {
+	return b.next()
+}
+
+// Reset implements Resettable.
+func (b *resettableBackoff) Reset() {
+	b.reset()
+}
+
+// Clone implements Cloneable.
+func (b *resettableBackoff) Clone() Backoff {
+	return b.clone()
+}
+
+// String implements fmt.Stringer.
+func (b *resettableBackoff) String() string {
+	return b.str()
+}
+
+// withReset returns a backoff that calls next on Next, reset followed by
+// resetting inner on Reset, and clone on Clone. It is described as
+// name(args, inner), or name(inner) if args is empty.
+func withReset(name, args string, inner Backoff, next BackoffFunc, reset func(), clone func() Backoff) Backoff {
+	return &resettableBackoff{
+		next: next,
+		reset: func() {
+			if reset != nil {
+				reset()
+			}
+			resetBackoff(inner)
+		},
+		clone: clone,
+		observe: func(err error) {
+			observeError(inner, err)
+		},
+		deadline: func(deadline time.Time) {
+			observeDeadline(inner, deadline)
+		},
+		limit: func() int {
+			return maxAttemptsOf(inner)
+		},
+		str: func() string {
+			if args == "" {
+				return name + "(" + describe(inner) + ")"
+			}
+			return name + "(" + args + ", " + describe(inner) + ")"
+		},
+	}
+}
+
+// describe returns the String of b if it implements fmt.Stringer, or its type
+// otherwise.
+func describe(b Backoff) string {
+	if s, ok := b.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprintf("%T", b)
+}
+
+// describeAll describes each of backoffs, separated by commas.
+func describeAll(backoffs ...Backoff) string {
+	strs := make([]string, len(backoffs))
+	for i, b := range backoffs {
+		strs[i] = describe(b)
+	}
+	return strings.Join(strs, ", ")
+}
+
+// resetBackoff resets b if it implements Resettable.
+func resetBackoff(b Backoff) {
+	if r, ok := b.(Resettable); ok {
+		r.Reset()
+	}
+}
+
 // WithJitter wraps a backoff function and adds the specified jitter. j can be
 // interpreted as "+/- j". For example, if j were 5 seconds and the backoff
 // returned 20s, the value could be between 15 and 25 seconds. The value can
 // never be less than 0.
 func WithJitter(j time.Duration, next Backoff) Backoff {
 	r := newLockedRandom(time.Now().UnixNano())
+	return withJitter(j, r.Int63n, next)
+}
+
+// WithJitterRand is like WithJitter, but draws random values from r instead of
+// an internal source seeded with the current time. This is useful for
+// producing a reproducible sequence in tests. Access to r is guarded by a
+// mutex, but r should not be used elsewhere while the backoff is in use.
+func WithJitterRand(j time.Duration, r *rand.Rand, next Backoff) Backoff {
+	var l sync.Mutex
+
+	return withJitter(j, func(n int64) int64 {
+		l.Lock()
+		defer l.Unlock()
+		return r.Int63n(n)
+	}, next)
+}
+
+func withJitter(j time.Duration, int63n func(n int64) int64, next Backoff) Backoff {
+	return withReset("Jitter", j.String(), next, func() (time.Duration, bool) {
+		val, stop := next.Next()
+		if stop {
+			return 0, true
+		}
+
+		diff := time.Duration(int63n(int64(j)*2) - int64(j))
+		val = val + diff
+		if val < 0 {
+			val = 0
+		}
+		return val, false
+	}, nil, func() Backoff {
+		// The random source is safe for concurrent use, so it is shared.
+		return withJitter(j, int63n, Clone(next))
+	})
+}
+
+// JitterReaderBytes is the number of bytes WithJitterReader reads from its
+// reader on each call to Next that adds jitter.
+const JitterReaderBytes = 8
+
+// WithJitterReader is like WithJitter, but draws randomness from r, such as
+// crypto/rand.Reader or an audited entropy pool, instead of math/rand. Each call
+// to Next that adds jitter reads exactly JitterReaderBytes bytes from r, so the
+// entropy consumed per retry is fixed and known; a j of 0 (or less) returns the
+// value unchanged without reading. The bytes are mapped onto the jitter range
+// without rejection sampling, which keeps the consumption fixed at the cost of a
+// bias smaller than 2j/2^64. Reads are serialized with a mutex.
+//
+// Since Next cannot return an error, the backoff signals stop if r returns an
+// error or fewer than JitterReaderBytes bytes, ending the retry loop as if the
+// backoff were exhausted rather than silently retrying without jitter.
+func WithJitterReader(r io.Reader, j time.Duration, next Backoff) Backoff {
+	var l sync.Mutex
+	return withJitterReader(r, &l, j, next)
+}
+
+func withJitterReader(r io.Reader, l *sync.Mutex, j time.Duration, next Backoff) Backoff {
+	return withReset("JitterReader", j.String(), next, func() (time.Duration, bool) {
+		val, stop := next.Next()
+		if stop {
+			return 0, true
+		}
+		if j <= 0 {
+			return val, false
+		}
+
+		var buf [JitterReaderBytes]byte
+		l.Lock()
+		_, err := io.ReadFull(r, buf[:])
+		l.Unlock()
+		if err != nil {
+			return 0, true
+		}
+
+		// Scale the random value onto [0, 2j) with a 128-bit multiplication.
+		hi, _ := bits.Mul64(binary.BigEndian.Uint64(buf[:]), uint64(j)*2)
+		val = val + time.Duration(hi) - j
+		if val < 0 {
+			val = 0
+		}
+		return val, false
+	}, nil, func() Backoff {
+		// The reader is guarded by the shared mutex, so it is shared.
+		return withJitterReader(r, l, j, Clone(next))
+	})
+}
+
+// WithJitterAbove is like WithJitter, but only adds jitter when the value
+// returned by next is greater than threshold. Shorter delays are returned
+// unchanged, so fast retries stay predictable while long, expensive ones are
+// spread out. A jitter of 0 (or less) returns every value unchanged.
+func WithJitterAbove(threshold, j time.Duration, next Backoff) Backoff {
+	return withJitterAbove(threshold, j, newLockedRandom(time.Now().UnixNano()), next)
+}
 
-	return BackoffFunc(func() (time.Duration, bool) {
+func withJitterAbove(threshold, j time.Duration, r *lockedSource, next Backoff) Backoff {
+	return withReset("JitterAbove", threshold.String()+", "+j.String(), next, func() (time.Duration, bool) {
 		val, stop := next.Next()
 		if stop {
 			return 0, true
 		}
+		if val <= threshold || j <= 0 {
+			return val, false
+		}
 
 		diff := time.Duration(r.Int63n(int64(j)*2) - int64(j))
 		val = val + diff
@@ -40,24 +427,60 @@ func WithJitter(j time.Duration, next Backoff) Backoff {
 			val = 0
 		}
 		return val, false
+	}, nil, func() Backoff {
+		return withJitterAbove(threshold, j, r, Clone(next))
+	})
+}
+
+// WithCenteredJitter wraps a backoff function and returns a value drawn
+// uniformly from [d-spread, d+spread] inclusive, where d is the value returned
+// by next, so the jitter is unbiased and the mean delay stays at d. Like
+// WithJitter, the value can never be less than 0, which biases the mean upward
+// when spread is larger than d. Unlike WithJitter, a spread of 0 (or less)
+// returns the value unchanged. The stop signal is passed through untouched.
+func WithCenteredJitter(spread time.Duration, next Backoff) Backoff {
+	return withCenteredJitter(spread, newLockedRandom(time.Now().UnixNano()), next)
+}
+
+func withCenteredJitter(spread time.Duration, r *lockedSource, next Backoff) Backoff {
+	return withReset("CenteredJitter", spread.String(), next, func() (time.Duration, bool) {
+		val, stop := next.Next()
+		if stop {
+			return 0, true
+		}
+		if spread <= 0 {
+			return val, false
+		}
+
+		diff := time.Duration(r.Int63n(int64(spread)*2+1)) - spread
+		val = val + diff
+		if val < 0 {
+			val = 0
+		}
+		return val, false
+	}, nil, func() Backoff {
+		return withCenteredJitter(spread, r, Clone(next))
 	})
 }
 
 // WithJitterPercent wraps a backoff function and adds the specified jitter
 // percentage. j can be interpreted as "+/- j%". For example, if j were 5 and
-// the backoff returned 20s, the value could be between 19 and 21 seconds. The
-// value can never be less than 0 or greater than 100.
+// the backoff returned 20s, the value could be between 19 and 21 seconds,
+// inclusive. The value can never be less than 0, and a j of 0 returns the
+// value unchanged. The stop signal is passed through untouched.
 func WithJitterPercent(j uint64, next Backoff) Backoff {
-	r := newLockedRandom(time.Now().UnixNano())
+	return withJitterPercent(j, newLockedRandom(time.Now().UnixNano()), next)
+}
 
-	return BackoffFunc(func() (time.Duration, bool) {
+func withJitterPercent(j uint64, r *lockedSource, next Backoff) Backoff {
+	return withReset("JitterPercent", strconv.FormatUint(j, 10), next, func() (time.Duration, bool) {
 		val, stop := next.Next()
 		if stop {
 			return 0, true
 		}
 
-		// Get a value between -j and j, the convert to a percentage
-		top := r.Int63n(int64(j)*2) - int64(j)
+		// Get a value between -j and j inclusive, then convert to a percentage
+		top := r.Int63n(int64(j)*2+1) - int64(j)
 		pct := 1 - float64(top)/100.0
 
 		val = time.Duration(float64(val) * pct)
@@ -65,15 +488,36 @@ func WithJitterPercent(j uint64, next Backoff) Backoff {
 			val = 0
 		}
 		return val, false
+	}, nil, func() Backoff {
+		return withJitterPercent(j, r, Clone(next))
 	})
 }
 
 // WithMaxRetries executes the backoff function up until the maximum attempts.
+// Note that max is the number of _retries_, not attempts: the function is
+// invoked at most max+1 times, and a max of 0 means the function is invoked
+// exactly once. A max of 0 never means unlimited; to retry without a limit,
+// do not wrap the backoff. See WithMaxAttempts to count attempts instead.
 func WithMaxRetries(max uint64, next Backoff) Backoff {
+	return withMaxRetries(max, 0, next)
+}
+
+// WithMaxAttempts is like WithMaxRetries, but n is the total number of
+// _attempts_, including the first: WithMaxAttempts(1, b) invokes the function
+// exactly once, and WithMaxAttempts(n, b) is equivalent to
+// WithMaxRetries(n-1, b). Since the first attempt always runs, an n of 0 also
+// invokes the function exactly once; it never means unlimited.
+func WithMaxAttempts(n uint64, next Backoff) Backoff {
+	if n == 0 {
+		n = 1
+	}
+	return withMaxRetries(n-1, 0, next)
+}
+
+func withMaxRetries(max, attempt uint64, next Backoff) Backoff {
 	var l sync.Mutex
-	var attempt uint64
 
-	return BackoffFunc(func() (time.Duration, bool) {
+	return withLimit(withReset("MaxRetries", strconv.FormatUint(max, 10), next, func() (time.Duration, bool) {
 		l.Lock()
 		defer l.Unlock()
 
@@ -88,15 +532,214 @@ func WithMaxRetries(max uint64, next Backoff) Backoff {
 		}
 
 		return val, false
+	}, func() {
+		l.Lock()
+		defer l.Unlock()
+		attempt = 0
+	}, func() Backoff {
+		l.Lock()
+		defer l.Unlock()
+		return withMaxRetries(max, attempt, Clone(next))
+	}), func() int {
+		limit := -1
+		if max < math.MaxInt-1 {
+			limit = int(max) + 1
+		}
+		if inner := maxAttemptsOf(next); inner >= 0 && (limit < 0 || inner < limit) {
+			limit = inner
+		}
+		return limit
 	})
 }
 
+// WithMaxRetriesByError is like WithMaxRetries, but keeps a separate retry
+// count for each class of error. Each key of limits is a class, and an error
+// belongs to it if errors.Is(err, key) reports true, so wrapped errors match
+// too; if an error matches more than one key, the smallest limit applies.
+// Errors that match no key share a single count limited by fallback. The
+// backoff stops as soon as one more retry would take a class past its limit.
+// For example, with limits of {ErrRateLimited: 1} and a fallback of 5, a
+// rate-limited error is retried once but other errors up to 5 times.
+//
+// The error is supplied by Do, DoWithData, and the variants built on them. It
+// reaches this backoff through the built-in middleware that wraps a single
+// backoff, but not through combinators such as Join or Max, nor through custom
+// middleware; when no error is supplied, retries count towards fallback. The
+// limits map is copied.
+func WithMaxRetriesByError(limits map[error]int, fallback int, next Backoff) Backoff {
+	l := make(map[error]int, len(limits))
+	for k, v := range limits {
+		l[k] = v
+	}
+	return withMaxRetriesByError(l, fallback, make(map[error]int), next)
+}
+
+// fallbackClass is the key under which withMaxRetriesByError counts errors that
+// match none of the limits.
+var fallbackClass = errors.New("fallback")
+
+func withMaxRetriesByError(limits map[error]int, fallback int, counts map[error]int, next Backoff) Backoff {
+	var l sync.Mutex
+	var lastErr error
+
+	return &resettableBackoff{
+		next: func() (time.Duration, bool) {
+			l.Lock()
+			defer l.Unlock()
+
+			class, limit := fallbackClass, fallback
+			matched := false
+			for key, max := range limits {
+				if errors.Is(lastErr, key) && (!matched || max < limit) {
+					class, limit, matched = key, max, true
+				}
+			}
+			lastErr = nil
+
+			if counts[class] >= limit {
+				return 0, true
+			}
+
+			val, stop := next.Next()
+			if stop {
+				return 0, true
+			}
+			counts[class]++
+			return val, false
+		},
+		reset: func() {
+			l.Lock()
+			defer l.Unlock()
+
+			for k := range counts {
+				delete(counts, k)
+			}
+			resetBackoff(next)
+		},
+		clone: func() Backoff {
+			l.Lock()
+			defer l.Unlock()
+
+			c := make(map[error]int, len(counts))
+			for k, v := range counts {
+				c[k] = v
+			}
+			return withMaxRetriesByError(limits, fallback, c, Clone(next))
+		},
+		str: func() string {
+			// Map order is random, so sort the limits to give a stable string.
+			parts := make([]string, 0, len(limits)+2)
+			for key, max := range limits {
+				parts = append(parts, strconv.Quote(fmt.Sprint(key))+": "+strconv.Itoa(max))
+			}
+			sort.Strings(parts)
+			parts = append(parts, "fallback="+strconv.Itoa(fallback), describe(next))
+			return "MaxRetriesByError(" + strings.Join(parts, ", ") + ")"
+		},
+		observe: func(err error) {
+			l.Lock()
+			defer l.Unlock()
+
+			lastErr = err
+			observeError(next, err)
+		},
+		deadline: func(deadline time.Time) {
+			observeDeadline(next, deadline)
+		},
+		limit: func() int {
+			return maxAttemptsOf(next)
+		},
+	}
+}
+
+// WithEscalateOnRepeat only advances next when the same error is returned
+// twice in a row, and otherwise keeps the delay flat by repeating the previous
+// value. This keeps retries fast while the error keeps changing, and backs off
+// once a repeating error signals a deeper problem. Two errors are the same if
+// errors.Is reports true or if their messages are equal. The first retry always
+// advances next. The stop signal of next is passed through untouched.
+//
+// Like WithMaxRetriesByError, the error is supplied by the retry loop; see its
+// documentation for which wrappers it passes through. When no error is
+// supplied, every call counts as a repeat, so next advances as usual.
+func WithEscalateOnRepeat(next Backoff) Backoff {
+	return withEscalateOnRepeat(nil, nil, 0, false, next)
+}
+
+func withEscalateOnRepeat(prevErr, lastErr error, last time.Duration, started bool, next Backoff) Backoff {
+	var l sync.Mutex
+
+	return &resettableBackoff{
+		next: func() (time.Duration, bool) {
+			l.Lock()
+			defer l.Unlock()
+
+			err := lastErr
+			lastErr = nil
+			repeated := err == nil || sameError(err, prevErr)
+			prevErr = err
+
+			if started && !repeated {
+				return last, false
+			}
+
+			val, stop := next.Next()
+			if stop {
+				return 0, true
+			}
+			last, started = val, true
+			return val, false
+		},
+		reset: func() {
+			l.Lock()
+			defer l.Unlock()
+
+			prevErr, lastErr, last, started = nil, nil, 0, false
+			resetBackoff(next)
+		},
+		clone: func() Backoff {
+			l.Lock()
+			defer l.Unlock()
+			return withEscalateOnRepeat(prevErr, lastErr, last, started, Clone(next))
+		},
+		str: func() string {
+			return "EscalateOnRepeat(" + describe(next) + ")"
+		},
+		observe: func(err error) {
+			l.Lock()
+			defer l.Unlock()
+
+			lastErr = err
+			observeError(next, err)
+		},
+		deadline: func(deadline time.Time) {
+			observeDeadline(next, deadline)
+		},
+		limit: func() int {
+			return maxAttemptsOf(next)
+		},
+	}
+}
+
+// sameError reports whether err and prev are the same error, by identity or by
+// message.
+func sameError(err, prev error) bool {
+	if prev == nil {
+		return false
+	}
+	return errors.Is(err, prev) || err.Error() == prev.Error()
+}
+
 // WithCappedDuration sets a maximum on the duration returned from the next
 // backoff. This is NOT a total backoff time, but rather a cap on the maximum
 // value a backoff can return. Without another middleware, the backoff will
 // continue infinitely.
+//
+// The cap is not validated against the base of next. A cap below the base
+// silently turns any backoff into a constant backoff of cap. Use
+// WithCappedDurationStrict to reject that misconfiguration.
 func WithCappedDuration(cap time.Duration, next Backoff) Backoff {
-	return BackoffFunc(func() (time.Duration, bool) {
+	return withReset("CappedDuration", cap.String(), next, func() (time.Duration, bool) {
 		val, stop := next.Next()
 		if stop {
 			return 0, true
@@ -106,6 +749,212 @@ func WithCappedDuration(cap time.Duration, next Backoff) Backoff {
 			val = cap
 		}
 		return val, false
+	}, nil, func() Backoff {
+		return WithCappedDuration(cap, Clone(next))
+	})
+}
+
+// WithCappedDurationStrict is like WithCappedDuration, but returns an error if
+// cap is less than or equal to zero, or if cap is less than min. Since
+// middleware does not know the base of the backoff it wraps, min should be the
+// base the backoff was constructed with.
+func WithCappedDurationStrict(cap, min time.Duration, next Backoff) (Backoff, error) {
+	if cap <= 0 {
+		return nil, fmt.Errorf("cap must be greater than 0")
+	}
+	if cap < min {
+		return nil, fmt.Errorf("cap %s must not be less than the base %s", cap, min)
+	}
+	return WithCappedDuration(cap, next), nil
+}
+
+// WithDeadlineFraction bounds each value returned from the next backoff to at
+// most fraction of the time remaining until the deadline of the retry loop's
+// context, so sleeps tighten naturally as the deadline approaches. For example,
+// a fraction of 0.25 never sleeps for more than a quarter of the remaining time.
+// If the context has no deadline, or the backoff is used outside of the retry
+// loop, values are passed through unchanged.
+//
+// The retry loop separately shortens a sleep that would reach the deadline to
+// leave time for one final attempt. That clamp is applied after this one, so
+// with a fraction less than 1 it has nothing left to do.
+//
+// It panics if fraction is not greater than 0 and less than or equal to 1.
+func WithDeadlineFraction(fraction float64, next Backoff) Backoff {
+	if !(fraction > 0 && fraction <= 1) {
+		panic("fraction must be greater than 0 and less than or equal to 1")
+	}
+
+	var l sync.Mutex
+	var deadline time.Time
+
+	return &resettableBackoff{
+		next: func() (time.Duration, bool) {
+			val, stop := next.Next()
+			if stop {
+				return 0, true
+			}
+
+			l.Lock()
+			d := deadline
+			l.Unlock()
+			if d.IsZero() {
+				return val, false
+			}
+
+			remaining := time.Until(d)
+			if remaining < 0 {
+				remaining = 0
+			}
+			if max := time.Duration(fraction * float64(remaining)); val > max {
+				val = max
+			}
+			return val, false
+		},
+		reset: func() {
+			l.Lock()
+			deadline = time.Time{}
+			l.Unlock()
+			resetBackoff(next)
+		},
+		clone: func() Backoff {
+			return WithDeadlineFraction(fraction, Clone(next))
+		},
+		str: func() string {
+			return "DeadlineFraction(" + strconv.FormatFloat(fraction, 'g', -1, 64) + ", " + describe(next) + ")"
+		},
+		observe: func(err error) {
+			observeError(next, err)
+		},
+		deadline: func(d time.Time) {
+			l.Lock()
+			deadline = d
+			l.Unlock()
+			observeDeadline(next, d)
+		},
+		limit: func() int {
+			return maxAttemptsOf(next)
+		},
+	}
+}
+
+// WithMinDuration sets a minimum on the duration returned from the next
+// backoff. Any value less than min is raised to min, while the stop signal is
+// passed through untouched. To ensure jitter never drops a value below the
+// floor, add it after WithJitter.
+func WithMinDuration(min time.Duration, next Backoff) Backoff {
+	return withReset("MinDuration", min.String(), next, func() (time.Duration, bool) {
+		val, stop := next.Next()
+		if stop {
+			return 0, true
+		}
+
+		if val < min {
+			val = min
+		}
+		return val, false
+	}, nil, func() Backoff {
+		return WithMinDuration(min, Clone(next))
+	})
+}
+
+// WithMonotonic ensures the values returned by the backoff never decrease: each
+// value is raised to at least the previous value returned. This keeps a chain
+// with jitter or a dynamic factor from going backward between attempts. The
+// stop signal is passed through untouched, and Reset forgets the previous
+// value.
+func WithMonotonic(next Backoff) Backoff {
+	return withMonotonic(0, next)
+}
+
+func withMonotonic(last time.Duration, next Backoff) Backoff {
+	var l sync.Mutex
+
+	return withReset("Monotonic", "", next, func() (time.Duration, bool) {
+		l.Lock()
+		defer l.Unlock()
+
+		val, stop := next.Next()
+		if stop {
+			return 0, true
+		}
+
+		if val < last {
+			val = last
+		}
+		last = val
+		return val, false
+	}, func() {
+		l.Lock()
+		defer l.Unlock()
+		last = 0
+	}, func() Backoff {
+		l.Lock()
+		defer l.Unlock()
+		return withMonotonic(last, Clone(next))
+	})
+}
+
+// WithImmediateFirst returns 0 on the first call to Next, so the first retry
+// happens immediately, and delegates to next afterwards. The inner sequence is
+// not advanced by the first call, so the second retry gets the first delay of
+// next. For example, with an exponential backoff the delays are 0, base,
+// 2*base, and so on.
+//
+// The immediate retry is in addition to those next allows. To count it against
+// a limit, wrap the result, for example in WithMaxRetries.
+func WithImmediateFirst(next Backoff) Backoff {
+	return withImmediateFirst(false, next)
+}
+
+func withImmediateFirst(started bool, next Backoff) Backoff {
+	var l sync.Mutex
+
+	return withLimit(withReset("ImmediateFirst", "", next, func() (time.Duration, bool) {
+		l.Lock()
+		first := !started
+		started = true
+		l.Unlock()
+
+		if first {
+			return 0, false
+		}
+		return next.Next()
+	}, func() {
+		l.Lock()
+		defer l.Unlock()
+		started = false
+	}, func() Backoff {
+		l.Lock()
+		defer l.Unlock()
+		return withImmediateFirst(started, Clone(next))
+	}), extendLimit(next, 1))
+}
+
+// WithDynamicFactor multiplies the duration returned from the next backoff by
+// the current value of factor on every call to Next. This lets an external
+// controller, such as a pressure gauge read atomically during an incident,
+// stretch or shrink all active backoffs in real time. A negative or NaN factor
+// yields 0, and the result saturates at the maximum time.Duration instead of
+// overflowing. The stop signal is passed through untouched.
+func WithDynamicFactor(factor func() float64, next Backoff) Backoff {
+	return withReset("DynamicFactor", "", next, func() (time.Duration, bool) {
+		val, stop := next.Next()
+		if stop {
+			return 0, true
+		}
+
+		scaled := float64(val) * factor()
+		switch {
+		case !(scaled > 0):
+			return 0, false
+		case scaled >= math.MaxInt64:
+			return math.MaxInt64, false
+		default:
+			return time.Duration(scaled), false
+		}
+	}, nil, func() Backoff {
+		return WithDynamicFactor(factor, Clone(next))
 	})
 }
 
@@ -113,10 +962,17 @@ func WithCappedDuration(cap time.Duration, next Backoff) Backoff {
 // execute. It's best-effort, and should not be used to guarantee an exact
 // amount of time.
 func WithMaxDuration(timeout time.Duration, next Backoff) Backoff {
-	start := time.Now()
+	return withMaxDuration(timeout, time.Now(), next)
+}
 
-	return BackoffFunc(func() (time.Duration, bool) {
+func withMaxDuration(timeout time.Duration, start time.Time, next Backoff) Backoff {
+	var l sync.Mutex
+
+	return withReset("MaxDuration", timeout.String(), next, func() (time.Duration, bool) {
+		l.Lock()
 		diff := timeout - time.Since(start)
+		l.Unlock()
+
 		if diff <= 0 {
 			return 0, true
 		}
@@ -130,5 +986,694 @@ func WithMaxDuration(timeout time.Duration, next Backoff) Backoff {
 			val = diff
 		}
 		return val, false
+	}, func() {
+		l.Lock()
+		defer l.Unlock()
+		start = time.Now()
+	}, func() Backoff {
+		l.Lock()
+		defer l.Unlock()
+		return withMaxDuration(timeout, start, Clone(next))
+	})
+}
+
+// WithMaxElapsedTime sets a maximum on the total wall-clock time a backoff
+// should execute, starting from the first call to Next. Unlike
+// WithMaxDuration, it never shortens the returned value to fit the remaining
+// time; if sleeping for the next value would exceed the budget, it stops
+// instead.
+func WithMaxElapsedTime(timeout time.Duration, next Backoff) Backoff {
+	return withMaxElapsedTime(timeout, time.Time{}, next)
+}
+
+func withMaxElapsedTime(timeout time.Duration, start time.Time, next Backoff) Backoff {
+	var l sync.Mutex
+
+	return withReset("MaxElapsedTime", timeout.String(), next, func() (time.Duration, bool) {
+		l.Lock()
+		if start.IsZero() {
+			start = time.Now()
+		}
+		elapsed := time.Since(start)
+		l.Unlock()
+
+		if elapsed >= timeout {
+			return 0, true
+		}
+
+		val, stop := next.Next()
+		if stop {
+			return 0, true
+		}
+
+		if elapsed+val > timeout {
+			return 0, true
+		}
+		return val, false
+	}, func() {
+		l.Lock()
+		defer l.Unlock()
+		start = time.Time{}
+	}, func() Backoff {
+		l.Lock()
+		defer l.Unlock()
+		return withMaxElapsedTime(timeout, start, Clone(next))
 	})
 }
+
+// WithDeadlineTime stops the backoff once the clock reaches t, and shortens any
+// value that would sleep past t to end at t, so giving up can be aligned with
+// an absolute time, such as the end of a maintenance window. Unlike
+// WithMaxElapsedTime, the limit does not depend on when retrying started, and
+// unlike a context deadline, it never interrupts an attempt in progress.
+func WithDeadlineTime(t time.Time, next Backoff) Backoff {
+	return withReset("DeadlineTime", t.Format(time.RFC3339), next, func() (time.Duration, bool) {
+		remaining := time.Until(t)
+		if remaining <= 0 {
+			return 0, true
+		}
+
+		val, stop := next.Next()
+		if stop {
+			return 0, true
+		}
+
+		if val > remaining {
+			val = remaining
+		}
+		return val, false
+	}, nil, func() Backoff {
+		return WithDeadlineTime(t, Clone(next))
+	})
+}
+
+// WithMaxCumulativeDelay sets a maximum on the sum of all durations returned
+// by the backoff. Once returning the next value would push the sum past total,
+// it stops. Unlike WithMaxElapsedTime, it only counts time spent sleeping and
+// ignores the execution time of the function, which makes the total retry
+// window predictable regardless of the growth curve.
+func WithMaxCumulativeDelay(total time.Duration, next Backoff) Backoff {
+	return withMaxCumulativeDelay(total, 0, next)
+}
+
+func withMaxCumulativeDelay(total, sum time.Duration, next Backoff) Backoff {
+	var l sync.Mutex
+
+	return withReset("MaxCumulativeDelay", total.String(), next, func() (time.Duration, bool) {
+		l.Lock()
+		defer l.Unlock()
+
+		val, stop := next.Next()
+		if stop {
+			return 0, true
+		}
+
+		if val > total-sum {
+			return 0, true
+		}
+		sum += val
+		return val, false
+	}, func() {
+		l.Lock()
+		defer l.Unlock()
+		sum = 0
+	}, func() Backoff {
+		l.Lock()
+		defer l.Unlock()
+		return withMaxCumulativeDelay(total, sum, Clone(next))
+	})
+}
+
+// WithGrace extends the backoff with a grace period: once next signals stop,
+// it returns graceDelay extra more times before finally stopping. This gives an
+// important operation a few more chances after its regular strategy is done.
+// Once next has stopped, it is not consulted again until the backoff is reset.
+// An extra of 0 (or less) passes the stop signal through untouched.
+func WithGrace(extra int, graceDelay time.Duration, next Backoff) Backoff {
+	return withGrace(extra, graceDelay, false, 0, next)
+}
+
+func withGrace(extra int, graceDelay time.Duration, exhausted bool, used int, next Backoff) Backoff {
+	var l sync.Mutex
+
+	return withLimit(withReset("Grace", strconv.Itoa(extra)+", "+graceDelay.String(), next, func() (time.Duration, bool) {
+		l.Lock()
+		defer l.Unlock()
+
+		if !exhausted {
+			val, stop := next.Next()
+			if !stop {
+				return val, false
+			}
+			exhausted = true
+		}
+
+		if used >= extra {
+			return 0, true
+		}
+		used++
+		return graceDelay, false
+	}, func() {
+		l.Lock()
+		defer l.Unlock()
+		exhausted, used = false, 0
+	}, func() Backoff {
+		l.Lock()
+		defer l.Unlock()
+		return withGrace(extra, graceDelay, exhausted, used, Clone(next))
+	}), extendLimit(next, extra))
+}
+
+// WithRecoveryWindow returns window instead of the value of next on every
+// everyN-th call to Next, which inserts an occasional long pause to give a
+// recovering downstream breathing room before normal retries resume. next is
+// still consulted on those calls, so its stop signal and the rest of its
+// sequence are preserved. It panics if everyN is less than 1 or window is less
+// than zero.
+func WithRecoveryWindow(everyN int, window time.Duration, next Backoff) Backoff {
+	if everyN < 1 {
+		panic("everyN must be greater than 0")
+	}
+	if window < 0 {
+		panic("window must be greater than or equal to 0")
+	}
+
+	return withRecoveryWindow(everyN, window, 0, next)
+}
+
+func withRecoveryWindow(everyN int, window time.Duration, calls int, next Backoff) Backoff {
+	var l sync.Mutex
+
+	return withReset("RecoveryWindow", strconv.Itoa(everyN)+", "+window.String(), next, func() (time.Duration, bool) {
+		val, stop := next.Next()
+		if stop {
+			return 0, true
+		}
+
+		l.Lock()
+		calls++
+		pause := calls%everyN == 0
+		l.Unlock()
+
+		if pause {
+			return window, false
+		}
+		return val, false
+	}, func() {
+		l.Lock()
+		defer l.Unlock()
+		calls = 0
+	}, func() Backoff {
+		l.Lock()
+		defer l.Unlock()
+		return withRecoveryWindow(everyN, window, calls, Clone(next))
+	})
+}
+
+// WithStopOverride passes every value and stop signal returned by inner through
+// fn, and returns what fn returns instead. This is an escape hatch for
+// site-specific policies, such as continuing past a stop during business hours
+// or stopping early when a global kill switch is set. When inner signals stop,
+// fn receives a duration of 0; if fn overrides the stop, the duration it
+// returns is used. inner is consulted on every call, even after it stopped.
+func WithStopOverride(fn func(next time.Duration, stop bool) (time.Duration, bool), inner Backoff) Backoff {
+	return withLimit(withReset("StopOverride", "", inner, func() (time.Duration, bool) {
+		val, stop := inner.Next()
+		if stop {
+			val = 0
+		}
+		return fn(val, stop)
+	}, nil, func() Backoff {
+		return WithStopOverride(fn, Clone(inner))
+	}), unlimited)
+}
+
+// Join chains multiple backoffs into phases. It returns values from the first
+// backoff until it signals stop, then moves on to the next, and so on. It only
+// signals stop once the last backoff signals stop. The stop signal of an
+// intermediate phase is never returned. Resetting the joined backoff resets all
+// phases and starts again from the first.
+func Join(backoffs ...Backoff) Backoff {
+	return join(0, backoffs)
+}
+
+func join(i int, backoffs []Backoff) Backoff {
+	var l sync.Mutex
+
+	return &resettableBackoff{
+		next: func() (time.Duration, bool) {
+			l.Lock()
+			defer l.Unlock()
+
+			for ; i < len(backoffs); i++ {
+				val, stop := backoffs[i].Next()
+				if !stop {
+					return val, false
+				}
+			}
+			return 0, true
+		},
+		reset: func() {
+			l.Lock()
+			defer l.Unlock()
+
+			i = 0
+			for _, b := range backoffs {
+				resetBackoff(b)
+			}
+		},
+		clone: func() Backoff {
+			l.Lock()
+			defer l.Unlock()
+
+			clones := make([]Backoff, len(backoffs))
+			for j, b := range backoffs {
+				clones[j] = Clone(b)
+			}
+			return join(i, clones)
+		},
+		str: func() string {
+			return "Join(" + describeAll(backoffs...) + ")"
+		},
+	}
+}
+
+// Sequence chains multiple backoffs into failover phases. Like Join, it returns
+// values from the first backoff until it signals stop, then moves on to the
+// next, and only signals stop once the last backoff signals stop. Unlike Join,
+// which drains each backoff as-is, Sequence resets each backoff (if it
+// implements Resettable) when it becomes active, so every phase starts from
+// its initial state even if the backoff was used before.
+func Sequence(backoffs ...Backoff) Backoff {
+	return sequence(-1, backoffs)
+}
+
+// sequence tracks the active phase in i, which is -1 before the first call to
+// Next.
+func sequence(i int, backoffs []Backoff) Backoff {
+	var l sync.Mutex
+
+	return &resettableBackoff{
+		next: func() (time.Duration, bool) {
+			l.Lock()
+			defer l.Unlock()
+
+			if i < 0 {
+				i = 0
+				if len(backoffs) > 0 {
+					resetBackoff(backoffs[0])
+				}
+			}
+
+			for i < len(backoffs) {
+				val, stop := backoffs[i].Next()
+				if !stop {
+					return val, false
+				}
+
+				i++
+				if i < len(backoffs) {
+					resetBackoff(backoffs[i])
+				}
+			}
+			return 0, true
+		},
+		reset: func() {
+			l.Lock()
+			defer l.Unlock()
+
+			i = -1
+		},
+		clone: func() Backoff {
+			l.Lock()
+			defer l.Unlock()
+
+			clones := make([]Backoff, len(backoffs))
+			for j, b := range backoffs {
+				clones[j] = Clone(b)
+			}
+			return sequence(i, clones)
+		},
+		str: func() string {
+			return "Sequence(" + describeAll(backoffs...) + ")"
+		},
+	}
+}
+
+// WithAutoReset wraps a backoff and resets it (if it implements Resettable)
+// when more than stable has elapsed since the previous call to Next. This keeps
+// a connection that was stable for a long time and then fails from jumping
+// straight to the large delays of its previous outage. The first call to Next
+// never resets.
+func WithAutoReset(stable time.Duration, next Backoff) Backoff {
+	return withAutoReset(stable, time.Time{}, next)
+}
+
+func withAutoReset(stable time.Duration, last time.Time, next Backoff) Backoff {
+	var l sync.Mutex
+
+	return withLimit(withReset("AutoReset", stable.String(), next, func() (time.Duration, bool) {
+		l.Lock()
+		defer l.Unlock()
+
+		now := time.Now()
+		if !last.IsZero() && now.Sub(last) > stable {
+			resetBackoff(next)
+		}
+		last = now
+
+		return next.Next()
+	}, func() {
+		l.Lock()
+		defer l.Unlock()
+		last = time.Time{}
+	}, func() Backoff {
+		l.Lock()
+		defer l.Unlock()
+		return withAutoReset(stable, last, Clone(next))
+	}), unlimited)
+}
+
+// WithLogger wraps a backoff and logs the result of each call to Next, the
+// returned duration and stop flag, with logf. It does not change the result,
+// and is meant as a diagnostic tool for debugging backoff chains. logf matches
+// the signature of log.Printf, so the standard library logger (or any
+// compatible logger) can be used without adding a dependency.
+func WithLogger(logf func(format string, args ...any), next Backoff) Backoff {
+	return withReset("Logger", "", next, func() (time.Duration, bool) {
+		val, stop := next.Next()
+		logf("retry: backoff next=%s stop=%t", val, stop)
+		return val, stop
+	}, nil, func() Backoff {
+		return WithLogger(logf, Clone(next))
+	})
+}
+
+// StopMode controls when a combination of backoffs, such as Max or Min, signals
+// stop.
+type StopMode int
+
+const (
+	// StopOnAny stops as soon as either backoff signals stop.
+	StopOnAny StopMode = iota
+
+	// StopOnAll stops only once both backoffs signal stop. Until then, the value
+	// of the backoff that has not stopped is used alone.
+	StopOnAll
+)
+
+// String implements fmt.Stringer.
+func (m StopMode) String() string {
+	switch m {
+	case StopOnAny:
+		return "StopOnAny"
+	case StopOnAll:
+		return "StopOnAll"
+	default:
+		return "StopMode(" + strconv.Itoa(int(m)) + ")"
+	}
+}
+
+// Max combines two backoffs by advancing both on each call to Next and
+// returning the larger duration. It stops as soon as either backoff signals
+// stop; use MaxMode with StopOnAll to keep going until both stop.
+func Max(a, b Backoff) Backoff {
+	return MaxMode(StopOnAny, a, b)
+}
+
+// MaxMode is like Max, but uses mode to decide when to stop.
+func MaxMode(mode StopMode, a, b Backoff) Backoff {
+	return combine("Max", mode, a, b, func(x, y time.Duration) time.Duration {
+		if x > y {
+			return x
+		}
+		return y
+	})
+}
+
+// Min combines two backoffs by advancing both on each call to Next and
+// returning the smaller duration. It stops as soon as either backoff signals
+// stop; use MinMode with StopOnAll to keep going until both stop.
+func Min(a, b Backoff) Backoff {
+	return MinMode(StopOnAny, a, b)
+}
+
+// MinMode is like Min, but uses mode to decide when to stop.
+func MinMode(mode StopMode, a, b Backoff) Backoff {
+	return combine("Min", mode, a, b, func(x, y time.Duration) time.Duration {
+		if x < y {
+			return x
+		}
+		return y
+	})
+}
+
+func combine(name string, mode StopMode, a, b Backoff, pick func(x, y time.Duration) time.Duration) Backoff {
+	return &resettableBackoff{
+		next: func() (time.Duration, bool) {
+			x, xstop := a.Next()
+			y, ystop := b.Next()
+
+			switch {
+			case xstop && ystop:
+				return 0, true
+			case xstop || ystop:
+				if mode == StopOnAny {
+					return 0, true
+				}
+				if xstop {
+					return y, false
+				}
+				return x, false
+			default:
+				return pick(x, y), false
+			}
+		},
+		reset: func() {
+			resetBackoff(a)
+			resetBackoff(b)
+		},
+		clone: func() Backoff {
+			return combine(name, mode, Clone(a), Clone(b), pick)
+		},
+		str: func() string {
+			if mode == StopOnAny {
+				return name + "(" + describeAll(a, b) + ")"
+			}
+			return name + "(" + mode.String() + ", " + describeAll(a, b) + ")"
+		},
+	}
+}
+
+// Alternate interleaves two backoffs: odd calls to Next (the first, third, and
+// so on) return a.Next(), and even calls return b.Next(). Only the backoff that
+// is consulted is advanced, so each sees every other call. It stops as soon as
+// either backoff signals stop and keeps signaling stop until it is reset.
+func Alternate(a, b Backoff) Backoff {
+	return alternate(0, false, a, b)
+}
+
+func alternate(calls uint64, stopped bool, a, b Backoff) Backoff {
+	var l sync.Mutex
+
+	return &resettableBackoff{
+		next: func() (time.Duration, bool) {
+			l.Lock()
+			defer l.Unlock()
+
+			if stopped {
+				return 0, true
+			}
+
+			next := a
+			if calls%2 == 1 {
+				next = b
+			}
+			calls++
+
+			val, stop := next.Next()
+			if stop {
+				stopped = true
+				return 0, true
+			}
+			return val, false
+		},
+		reset: func() {
+			l.Lock()
+			defer l.Unlock()
+
+			calls, stopped = 0, false
+			resetBackoff(a)
+			resetBackoff(b)
+		},
+		clone: func() Backoff {
+			l.Lock()
+			defer l.Unlock()
+
+			return alternate(calls, stopped, Clone(a), Clone(b))
+		},
+		str: func() string {
+			return "Alternate(" + describeAll(a, b) + ")"
+		},
+	}
+}
+
+// WeightedBackoff is a Backoff with a relative weight, for use with Weighted.
+type WeightedBackoff struct {
+	// Weight is the relative likelihood of selecting Backoff. A weight of 0
+	// means the backoff is never selected.
+	Weight float64
+
+	// Backoff is the backoff to delegate to when selected.
+	Backoff Backoff
+}
+
+// Weighted randomly selects one of choices on each call to Next, with a
+// probability proportional to its weight, and returns the value of the
+// selected backoff. Only the selected backoff is advanced. It stops when the
+// selected backoff signals stop. This is useful for experimenting with mixed
+// retry policies. It panics if any weight is negative or NaN, or if the weights
+// do not add up to more than 0.
+func Weighted(choices []WeightedBackoff) Backoff {
+	r := newLockedRandom(time.Now().UnixNano())
+	return weighted(choices, r.Int63n)
+}
+
+// WeightedRand is like Weighted, but draws random values from r instead of an
+// internal source seeded with the current time. This is useful for producing a
+// reproducible sequence in tests. Access to r is guarded by a mutex, but r
+// should not be used elsewhere while the backoff is in use.
+func WeightedRand(r *rand.Rand, choices []WeightedBackoff) Backoff {
+	var l sync.Mutex
+
+	return weighted(choices, func(n int64) int64 {
+		l.Lock()
+		defer l.Unlock()
+		return r.Int63n(n)
+	})
+}
+
+func weighted(choices []WeightedBackoff, int63n func(n int64) int64) Backoff {
+	var total float64
+	for _, c := range choices {
+		if c.Weight < 0 || math.IsNaN(c.Weight) {
+			panic("weight must be greater than or equal to 0")
+		}
+		total += c.Weight
+	}
+	if !(total > 0) || math.IsInf(total, 0) {
+		panic("weights must add up to a finite value greater than 0")
+	}
+
+	choices = append([]WeightedBackoff(nil), choices...)
+	return &resettableBackoff{
+		next: func() (time.Duration, bool) {
+			// Draw a value uniformly in [0, total) with 53 bits of precision.
+			target := float64(int63n(1<<53)) / (1 << 53) * total
+
+			selected := -1
+			for i, c := range choices {
+				if c.Weight <= 0 {
+					continue
+				}
+				selected = i
+				if target < c.Weight {
+					break
+				}
+				target -= c.Weight
+			}
+			return choices[selected].Backoff.Next()
+		},
+		reset: func() {
+			for _, c := range choices {
+				resetBackoff(c.Backoff)
+			}
+		},
+		clone: func() Backoff {
+			// The random source is safe for concurrent use, so it is shared.
+			clones := make([]WeightedBackoff, len(choices))
+			for i, c := range choices {
+				clones[i] = WeightedBackoff{Weight: c.Weight, Backoff: Clone(c.Backoff)}
+			}
+			return weighted(clones, int63n)
+		},
+		str: func() string {
+			parts := make([]string, len(choices))
+			for i, c := range choices {
+				parts[i] = strconv.FormatFloat(c.Weight, 'g', -1, 64) + ": " + describe(c.Backoff)
+			}
+			return "Weighted(" + strings.Join(parts, ", ") + ")"
+		},
+	}
+}
+
+// Simulate calls Next on b until it signals stop or maxSteps values have been
+// collected, and returns the durations without sleeping. It is useful for
+// previewing or testing a backoff configuration. Since it consumes the state of
+// b, it should be given a fresh or reset backoff.
+func Simulate(b Backoff, maxSteps int) []time.Duration {
+	var results []time.Duration
+	for i := 0; i < maxSteps; i++ {
+		val, stop := b.Next()
+		if stop {
+			break
+		}
+		results = append(results, val)
+	}
+	return results
+}
+
+// Sync wraps b so that calls to Next, and Reset if b is Resettable, are
+// serialized with a mutex. The built-in backoffs and middleware are already
+// safe for concurrent use, with these exceptions:
+//
+//   - A BackoffFunc, or a backoff from NewFunc, is only as safe as its
+//     function. Sync it if the function mutates captured state.
+//   - Adapters around third-party backoffs, such as retrycenkalti.FromCenkalti,
+//     are only as safe as the backoff they wrap, and most are not. Sync them
+//     before sharing them.
+//   - NewChannelBackoff is safe to call concurrently, but loops that share it
+//     split the controller's values between them. Do not Sync it: its Next
+//     blocks on the channel while holding the mutex, which stalls every other
+//     caller, including Reset.
+//
+// Custom backoffs that are shared across goroutines need Sync too.
+func Sync(b Backoff) Backoff {
+	var l sync.Mutex
+
+	return &resettableBackoff{
+		next: func() (time.Duration, bool) {
+			l.Lock()
+			defer l.Unlock()
+			return b.Next()
+		},
+		reset: func() {
+			l.Lock()
+			defer l.Unlock()
+			resetBackoff(b)
+		},
+		clone: func() Backoff {
+			l.Lock()
+			defer l.Unlock()
+			return Sync(Clone(b))
+		},
+		observe: func(err error) {
+			l.Lock()
+			defer l.Unlock()
+			observeError(b, err)
+		},
+		deadline: func(deadline time.Time) {
+			l.Lock()
+			defer l.Unlock()
+			observeDeadline(b, deadline)
+		},
+		limit: func() int {
+			l.Lock()
+			defer l.Unlock()
+			return maxAttemptsOf(b)
+		},
+		str: func() string {
+			return "Sync(" + describe(b) + ")"
+		},
+	}
+}
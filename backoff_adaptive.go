@@ -0,0 +1,130 @@
+package retry
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// AdaptiveBackoff is a backoff whose delays follow the recent response
+// latencies of a downstream service: the slower the service, the longer the
+// delay. Record latencies with RecordLatency, typically from inside the
+// RetryFunc. It is safe for concurrent use.
+type AdaptiveBackoff struct {
+	base       time.Duration
+	multiplier float64
+
+	l       sync.Mutex
+	samples []time.Duration
+	i       int
+	full    bool
+}
+
+var (
+	_ Resettable = (*AdaptiveBackoff)(nil)
+	_ Cloneable  = (*AdaptiveBackoff)(nil)
+)
+
+// NewAdaptive creates a new adaptive backoff that returns the 95th percentile
+// of the last window recorded latencies times multiplier, and never less than
+// base. Until a latency is recorded, it returns base.
+//
+// It returns an error if base is less than or equal to zero, window is less
+// than 1, or multiplier is less than or equal to zero.
+func NewAdaptive(base time.Duration, window int, multiplier float64) (*AdaptiveBackoff, error) {
+	if base <= 0 {
+		return nil, fmt.Errorf("base must be greater than 0")
+	}
+	if window < 1 {
+		return nil, fmt.Errorf("window must be at least 1")
+	}
+	if !(multiplier > 0) {
+		return nil, fmt.Errorf("multiplier must be greater than 0")
+	}
+
+	return &AdaptiveBackoff{
+		base:       base,
+		multiplier: multiplier,
+		samples:    make([]time.Duration, window),
+	}, nil
+}
+
+// RecordLatency adds an observed latency to the sliding window, evicting the
+// oldest one once the window is full.
+func (b *AdaptiveBackoff) RecordLatency(d time.Duration) {
+	b.l.Lock()
+	defer b.l.Unlock()
+
+	b.samples[b.i] = d
+	b.i++
+	if b.i == len(b.samples) {
+		b.i = 0
+		b.full = true
+	}
+}
+
+// Next implements Backoff. It is safe for concurrent use.
+func (b *AdaptiveBackoff) Next() (time.Duration, bool) {
+	b.l.Lock()
+	n := b.i
+	if b.full {
+		n = len(b.samples)
+	}
+	sorted := make([]time.Duration, n)
+	copy(sorted, b.samples[:n])
+	b.l.Unlock()
+
+	if n == 0 {
+		return b.base, false
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+	p95 := sorted[int(math.Ceil(0.95*float64(n)))-1]
+
+	next := float64(p95) * b.multiplier
+	switch {
+	case next >= math.MaxInt64:
+		return math.MaxInt64, false
+	case next < float64(b.base):
+		return b.base, false
+	default:
+		return time.Duration(next), false
+	}
+}
+
+// Reset implements Resettable by discarding all recorded latencies.
+func (b *AdaptiveBackoff) Reset() {
+	b.l.Lock()
+	defer b.l.Unlock()
+
+	b.i = 0
+	b.full = false
+}
+
+// Clone implements Cloneable. The clone has its own copy of the recorded
+// latencies.
+func (b *AdaptiveBackoff) Clone() Backoff {
+	b.l.Lock()
+	defer b.l.Unlock()
+
+	samples := make([]time.Duration, len(b.samples))
+	copy(samples, b.samples)
+
+	return &AdaptiveBackoff{
+		base:       b.base,
+		multiplier: b.multiplier,
+		samples:    samples,
+		i:          b.i,
+		full:       b.full,
+	}
+}
+
+// String implements fmt.Stringer.
+func (b *AdaptiveBackoff) String() string {
+	return "Adaptive(base=" + b.base.String() + ", window=" + strconv.Itoa(len(b.samples)) + ", multiplier=" + strconv.FormatFloat(b.multiplier, 'g', -1, 64) + ")"
+}
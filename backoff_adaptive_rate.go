@@ -0,0 +1,117 @@
+package retry
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// AdaptiveRateBackoff is a backoff whose delay follows the recent failure rate:
+// it grows additively while failures continue and shrinks multiplicatively as
+// successes accumulate, in the style of AIMD congestion control. Record each
+// outcome with RecordOutcome. It is safe for concurrent use.
+type AdaptiveRateBackoff struct {
+	base time.Duration
+	max  time.Duration
+
+	l sync.Mutex
+
+	// multiplier scales base, and is kept between 1 and max/base.
+	multiplier float64
+}
+
+var (
+	_ Resettable = (*AdaptiveRateBackoff)(nil)
+	_ Cloneable  = (*AdaptiveRateBackoff)(nil)
+)
+
+// NewAdaptiveRate creates a new adaptive rate backoff that returns base times
+// an internal multiplier, clamped to [base, max]. The multiplier starts at 1.
+// Each recorded failure adds 1 to it, and each recorded success halves it, so a
+// burst of failures slows retries down linearly and a run of successes brings
+// the delay back to base quickly.
+//
+// It returns an error if base is less than or equal to zero, or max is less
+// than base.
+func NewAdaptiveRate(base, max time.Duration) (*AdaptiveRateBackoff, error) {
+	if base <= 0 {
+		return nil, fmt.Errorf("base must be greater than 0")
+	}
+	if max < base {
+		return nil, fmt.Errorf("max must be greater than or equal to base")
+	}
+
+	return &AdaptiveRateBackoff{
+		base:       base,
+		max:        max,
+		multiplier: 1,
+	}, nil
+}
+
+// RecordOutcome records the outcome of an attempt, typically from inside the
+// RetryFunc or after the retry loop returns.
+func (b *AdaptiveRateBackoff) RecordOutcome(success bool) {
+	b.l.Lock()
+	defer b.l.Unlock()
+
+	if success {
+		b.multiplier = math.Max(b.multiplier/2, 1)
+		return
+	}
+
+	// Once base*multiplier reaches max, further failures have no effect, which
+	// keeps a long outage from taking as long to recover from.
+	b.multiplier = math.Min(b.multiplier+1, math.Max(float64(b.max)/float64(b.base), 1))
+}
+
+// Next implements Backoff. It is safe for concurrent use.
+func (b *AdaptiveRateBackoff) Next() (time.Duration, bool) {
+	b.l.Lock()
+	m := b.multiplier
+	b.l.Unlock()
+
+	next := float64(b.base) * m
+	switch {
+	case next >= float64(b.max):
+		return b.max, false
+	case next < float64(b.base):
+		return b.base, false
+	default:
+		return time.Duration(next), false
+	}
+}
+
+// Multiplier returns the current multiplier.
+func (b *AdaptiveRateBackoff) Multiplier() float64 {
+	b.l.Lock()
+	defer b.l.Unlock()
+
+	return b.multiplier
+}
+
+// Reset implements Resettable by returning the multiplier to 1.
+func (b *AdaptiveRateBackoff) Reset() {
+	b.l.Lock()
+	defer b.l.Unlock()
+
+	b.multiplier = 1
+}
+
+// Clone implements Cloneable. The clone starts with the current multiplier and
+// adjusts it independently.
+func (b *AdaptiveRateBackoff) Clone() Backoff {
+	b.l.Lock()
+	defer b.l.Unlock()
+
+	return &AdaptiveRateBackoff{
+		base:       b.base,
+		max:        b.max,
+		multiplier: b.multiplier,
+	}
+}
+
+// String implements fmt.Stringer.
+func (b *AdaptiveRateBackoff) String() string {
+	return "AdaptiveRate(base=" + b.base.String() + ", max=" + b.max.String() + ")"
+}
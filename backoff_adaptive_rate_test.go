@@ -0,0 +1,143 @@
+package retry_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestAdaptiveRateBackoff(t *testing.T) {
+	t.Parallel()
+
+	t.Run("aimd", func(t *testing.T) {
+		t.Parallel()
+
+		b, err := retry.NewAdaptiveRate(100*time.Millisecond, 1*time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		steps := []struct {
+			success bool
+			want    time.Duration
+		}{
+			// Additive increase on failure.
+			{false, 200 * time.Millisecond},
+			{false, 300 * time.Millisecond},
+			{false, 400 * time.Millisecond},
+			{false, 500 * time.Millisecond},
+
+			// Multiplicative decrease on success, down to base.
+			{true, 250 * time.Millisecond},
+			{true, 125 * time.Millisecond},
+			{true, 100 * time.Millisecond},
+			{true, 100 * time.Millisecond},
+
+			// And back up again.
+			{false, 200 * time.Millisecond},
+		}
+
+		if val, _ := b.Next(); val != 100*time.Millisecond {
+			t.Errorf("expected %v to be %v", val, 100*time.Millisecond)
+		}
+		for i, s := range steps {
+			b.RecordOutcome(s.success)
+			if val, stop := b.Next(); stop || val != s.want {
+				t.Errorf("step %d: expected (%v, false), got (%v, %t)", i, s.want, val, stop)
+			}
+		}
+	})
+
+	t.Run("max", func(t *testing.T) {
+		t.Parallel()
+
+		b, err := retry.NewAdaptiveRate(100*time.Millisecond, 350*time.Millisecond)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 100; i++ {
+			b.RecordOutcome(false)
+		}
+
+		if val, _ := b.Next(); val != 350*time.Millisecond {
+			t.Errorf("expected %v to be %v", val, 350*time.Millisecond)
+		}
+
+		// The multiplier stopped growing at max, so one success is enough to
+		// come back below it.
+		if got, want := b.Multiplier(), 3.5; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		b.RecordOutcome(true)
+		if val, _ := b.Next(); val != 175*time.Millisecond {
+			t.Errorf("expected %v to be %v", val, 175*time.Millisecond)
+		}
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		t.Parallel()
+
+		b, err := retry.NewAdaptiveRate(100*time.Millisecond, 1*time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.RecordOutcome(false)
+		b.Reset()
+
+		if val, _ := b.Next(); val != 100*time.Millisecond {
+			t.Errorf("expected %v to be %v", val, 100*time.Millisecond)
+		}
+	})
+
+	t.Run("clone", func(t *testing.T) {
+		t.Parallel()
+
+		b, err := retry.NewAdaptiveRate(100*time.Millisecond, 1*time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.RecordOutcome(false)
+
+		c := retry.Clone(b)
+		b.RecordOutcome(false)
+
+		if val, _ := c.Next(); val != 200*time.Millisecond {
+			t.Errorf("expected %v to be %v", val, 200*time.Millisecond)
+		}
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		t.Parallel()
+
+		b, err := retry.NewAdaptiveRate(1*time.Millisecond, 1*time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					b.RecordOutcome((i+j)%2 == 0)
+					b.Next()
+				}
+			}(i)
+		}
+		wg.Wait()
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		if _, err := retry.NewAdaptiveRate(0, 1); err == nil {
+			t.Errorf("expected error")
+		}
+		if _, err := retry.NewAdaptiveRate(2, 1); err == nil {
+			t.Errorf("expected error")
+		}
+	})
+}
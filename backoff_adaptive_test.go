@@ -0,0 +1,123 @@
+package retry_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestAdaptiveBackoff(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no_samples", func(t *testing.T) {
+		t.Parallel()
+
+		b, err := retry.NewAdaptive(10*time.Millisecond, 10, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if val, _ := b.Next(); val != 10*time.Millisecond {
+			t.Errorf("expected %v to be %v", val, 10*time.Millisecond)
+		}
+	})
+
+	t.Run("p95", func(t *testing.T) {
+		t.Parallel()
+
+		b, err := retry.NewAdaptive(1*time.Millisecond, 100, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 1; i <= 100; i++ {
+			b.RecordLatency(time.Duration(i) * time.Millisecond)
+		}
+
+		if val, _ := b.Next(); val != 190*time.Millisecond {
+			t.Errorf("expected %v to be %v", val, 190*time.Millisecond)
+		}
+	})
+
+	t.Run("sliding_window", func(t *testing.T) {
+		t.Parallel()
+
+		b, err := retry.NewAdaptive(1*time.Millisecond, 2, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.RecordLatency(1 * time.Second)
+		b.RecordLatency(20 * time.Millisecond)
+		b.RecordLatency(10 * time.Millisecond)
+
+		// The 1s sample was evicted.
+		if val, _ := b.Next(); val != 20*time.Millisecond {
+			t.Errorf("expected %v to be %v", val, 20*time.Millisecond)
+		}
+	})
+
+	t.Run("floor", func(t *testing.T) {
+		t.Parallel()
+
+		b, err := retry.NewAdaptive(100*time.Millisecond, 10, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.RecordLatency(1 * time.Millisecond)
+
+		if val, _ := b.Next(); val != 100*time.Millisecond {
+			t.Errorf("expected %v to be %v", val, 100*time.Millisecond)
+		}
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		t.Parallel()
+
+		b, err := retry.NewAdaptive(1*time.Millisecond, 10, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.RecordLatency(1 * time.Second)
+		b.Reset()
+
+		if val, _ := b.Next(); val != 1*time.Millisecond {
+			t.Errorf("expected %v to be %v", val, 1*time.Millisecond)
+		}
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		t.Parallel()
+
+		b, err := retry.NewAdaptive(1*time.Millisecond, 16, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					b.RecordLatency(time.Duration(j) * time.Millisecond)
+					b.Next()
+				}
+			}()
+		}
+		wg.Wait()
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		if _, err := retry.NewAdaptive(0, 10, 1); err == nil {
+			t.Errorf("expected error")
+		}
+		if _, err := retry.NewAdaptive(1, 0, 1); err == nil {
+			t.Errorf("expected error")
+		}
+		if _, err := retry.NewAdaptive(1, 10, 0); err == nil {
+			t.Errorf("expected error")
+		}
+	})
+}
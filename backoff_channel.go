@@ -0,0 +1,37 @@
+package retry
+
+import (
+	"time"
+)
+
+// NewChannelBackoff creates a new backoff that receives each delay from ch,
+// which decouples the timing policy entirely from this package so an external
+// controller, such as a central scheduler, can drive retries. A closed channel
+// signals stop.
+//
+// Next blocks until a value is received or ch is closed. The retry loop calls
+// Next after an attempt fails and before it starts waiting, so while Next is
+// blocked the loop does not observe context cancellation. The controller
+// should close ch, or keep sending, when retries are no longer wanted.
+func NewChannelBackoff(ch <-chan time.Duration) Backoff {
+	return &channelBackoff{ch}
+}
+
+type channelBackoff struct {
+	ch <-chan time.Duration
+}
+
+// Next implements Backoff. It blocks until a value is received or the channel
+// is closed.
+func (b *channelBackoff) Next() (time.Duration, bool) {
+	next, ok := <-b.ch
+	if !ok {
+		return 0, true
+	}
+	return next, false
+}
+
+// String implements fmt.Stringer.
+func (b *channelBackoff) String() string {
+	return "Channel"
+}
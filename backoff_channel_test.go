@@ -0,0 +1,59 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestChannelBackoff(t *testing.T) {
+	t.Parallel()
+
+	t.Run("receives", func(t *testing.T) {
+		t.Parallel()
+
+		ch := make(chan time.Duration, 3)
+		ch <- 1 * time.Second
+		ch <- 2 * time.Second
+		ch <- 3 * time.Second
+		close(ch)
+
+		b := retry.NewChannelBackoff(ch)
+
+		exp := []time.Duration{1 * time.Second, 2 * time.Second, 3 * time.Second}
+		if got := retry.Simulate(b, 10); !reflect.DeepEqual(got, exp) {
+			t.Errorf("expected %v to be %v", got, exp)
+		}
+	})
+
+	t.Run("blocks_until_sent", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+
+		ch := make(chan time.Duration)
+		go func() {
+			for i := 0; i < 2; i++ {
+				time.Sleep(5 * time.Millisecond)
+				ch <- 1 * time.Nanosecond
+			}
+			close(ch)
+		}()
+
+		var calls int
+		err := retry.Do(ctx, retry.NewChannelBackoff(ch), func(_ context.Context) error {
+			calls++
+			return retry.RetryableError(errors.New("oops"))
+		})
+		if !errors.Is(err, retry.ErrBackoffStopped) {
+			t.Errorf("expected %v to be %v", err, retry.ErrBackoffStopped)
+		}
+		if got, want := calls, 3; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
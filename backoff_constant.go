@@ -2,6 +2,8 @@ package retry
 
 import (
 	"context"
+	"fmt"
+	"math"
 	"time"
 )
 
@@ -19,7 +21,63 @@ func NewConstant(t time.Duration) Backoff {
 		panic("t must be greater than 0")
 	}
 
-	return BackoffFunc(func() (time.Duration, bool) {
-		return t, false
-	})
+	return constantBackoff(t)
+}
+
+type constantBackoff time.Duration
+
+// Next implements Backoff. It is safe for concurrent use.
+func (b constantBackoff) Next() (time.Duration, bool) {
+	return time.Duration(b), false
+}
+
+// String implements fmt.Stringer.
+func (b constantBackoff) String() string {
+	return "Constant(t=" + time.Duration(b).String() + ")"
+}
+
+type constantJitteredBackoff struct {
+	interval time.Duration
+	jitter   time.Duration
+	r        *lockedSource
+}
+
+// NewConstantJittered creates a new backoff that returns interval +/- a random
+// value up to jitter (inclusive) on each call, and never stops on its own. The
+// value can never be less than 0. This suits polling at a roughly fixed
+// interval, and is equivalent to wrapping NewConstant with WithCenteredJitter.
+// It returns an error if interval is less than or equal to zero, if jitter is
+// less than zero, or if interval plus jitter overflows.
+func NewConstantJittered(interval, jitter time.Duration) (Backoff, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("interval must be greater than 0")
+	}
+
+	if jitter < 0 {
+		return nil, fmt.Errorf("jitter must be greater than or equal to 0")
+	}
+
+	if jitter > (math.MaxInt64-interval)/2 {
+		return nil, fmt.Errorf("interval plus jitter must not overflow")
+	}
+
+	return &constantJitteredBackoff{
+		interval: interval,
+		jitter:   jitter,
+		r:        newLockedRandom(time.Now().UnixNano()),
+	}, nil
+}
+
+// Next implements Backoff. It is safe for concurrent use.
+func (b *constantJitteredBackoff) Next() (time.Duration, bool) {
+	val := b.interval + time.Duration(b.r.Int63n(int64(b.jitter)*2+1)) - b.jitter
+	if val < 0 {
+		val = 0
+	}
+	return val, false
+}
+
+// String implements fmt.Stringer.
+func (b *constantJitteredBackoff) String() string {
+	return "ConstantJittered(interval=" + b.interval.String() + ", jitter=" + b.jitter.String() + ")"
 }
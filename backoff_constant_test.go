@@ -112,3 +112,69 @@ func ExampleNewConstant() {
 	// 1s
 	// 1s
 }
+
+func TestNewConstantJittered(t *testing.T) {
+	t.Parallel()
+
+	t.Run("band", func(t *testing.T) {
+		t.Parallel()
+
+		b, err := retry.NewConstantJittered(5*time.Second, 500*time.Millisecond)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		const n = 100_000
+		var below, above int
+		var sum time.Duration
+		for i := 0; i < n; i++ {
+			val, stop := b.Next()
+			if stop {
+				t.Fatal("should not stop")
+			}
+			if min, max := 4500*time.Millisecond, 5500*time.Millisecond; val < min || val > max {
+				t.Fatalf("expected %v to be between %v and %v", val, min, max)
+			}
+
+			switch {
+			case val < 5*time.Second:
+				below++
+			case val > 5*time.Second:
+				above++
+			}
+			sum += val
+		}
+
+		// The jitter is centered, so values fall on both sides of the interval
+		// about equally often and the mean stays close to it.
+		if min := n * 45 / 100; below < min || above < min {
+			t.Errorf("expected at least %d values on each side, got %d below and %d above", min, below, above)
+		}
+		if mean, min, max := sum/n, 4990*time.Millisecond, 5010*time.Millisecond; mean < min || mean > max {
+			t.Errorf("expected mean %v to be between %v and %v", mean, min, max)
+		}
+	})
+
+	t.Run("no_jitter", func(t *testing.T) {
+		t.Parallel()
+
+		b, err := retry.NewConstantJittered(1*time.Second, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := retry.Simulate(b, 3), []time.Duration{1 * time.Second, 1 * time.Second, 1 * time.Second}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		if _, err := retry.NewConstantJittered(0, 1*time.Second); err == nil {
+			t.Error("expected error for zero interval")
+		}
+		if _, err := retry.NewConstantJittered(1*time.Second, -1); err == nil {
+			t.Error("expected error for negative jitter")
+		}
+	})
+}
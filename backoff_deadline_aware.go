@@ -0,0 +1,109 @@
+package retry
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// deadlineAwareFallback is the constant delay NewDeadlineAware uses when the
+// context has no deadline.
+const deadlineAwareFallback = 1 * time.Second
+
+type deadlineAwareBackoff struct {
+	base    time.Duration
+	window  time.Duration
+	retries uint64
+	attempt uint64
+}
+
+// NewDeadlineAware creates a new exponential backoff whose delays are planned to
+// fit about targetAttempts attempts before the deadline of ctx. The delays
+// double on each retry, and the base is chosen so that the first
+// targetAttempts-1 delays add up to the time remaining until the deadline when
+// NewDeadlineAware is called. For example, with 30s remaining and 5 attempts,
+// the delays are 2s, 4s, 8s, and 16s. After that, the last delay is repeated;
+// the backoff never stops on its own, since the deadline ends the retry loop.
+//
+// Time spent executing the function is not accounted for, so fewer attempts may
+// fit in practice, but the retry loop always shortens the last sleep to leave
+// time for a final attempt before the deadline. If ctx has no deadline, the
+// backoff returns a constant 1s. If the deadline has already passed, it
+// returns 0.
+//
+// It panics if targetAttempts is less than 2.
+func NewDeadlineAware(ctx context.Context, targetAttempts int) Backoff {
+	if targetAttempts < 2 {
+		panic("targetAttempts must be at least 2")
+	}
+
+	retries := uint64(targetAttempts - 1)
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return &deadlineAwareBackoff{
+			base:    deadlineAwareFallback,
+			retries: 0,
+		}
+	}
+
+	window := time.Until(deadline)
+	if window < 0 {
+		window = 0
+	}
+
+	// The sum of base * 2^i for i in [0, retries) is base * (2^retries - 1).
+	base := time.Duration(math.Round(float64(window) / (math.Pow(2, float64(retries)) - 1)))
+	if window > 0 && base < 1 {
+		base = 1
+	}
+
+	return &deadlineAwareBackoff{
+		base:    base,
+		window:  window,
+		retries: retries,
+	}
+}
+
+// Next implements Backoff. It is safe for concurrent use.
+func (b *deadlineAwareBackoff) Next() (time.Duration, bool) {
+	attempt := atomic.AddUint64(&b.attempt, 1) - 1
+	if attempt >= b.retries {
+		atomic.AddUint64(&b.attempt, ^uint64(0))
+		if b.retries == 0 {
+			return b.base, false
+		}
+		attempt = b.retries - 1
+	}
+
+	// Saturate instead of wrapping when the doubling would overflow.
+	if attempt >= 63 || b.base > math.MaxInt64>>attempt {
+		return math.MaxInt64, false
+	}
+	return b.base << attempt, false
+}
+
+// Reset implements Resettable. It is safe for concurrent use.
+func (b *deadlineAwareBackoff) Reset() {
+	atomic.StoreUint64(&b.attempt, 0)
+}
+
+// Clone implements Cloneable. It is safe for concurrent use.
+func (b *deadlineAwareBackoff) Clone() Backoff {
+	return &deadlineAwareBackoff{
+		base:    b.base,
+		window:  b.window,
+		retries: b.retries,
+		attempt: atomic.LoadUint64(&b.attempt),
+	}
+}
+
+// String implements fmt.Stringer.
+func (b *deadlineAwareBackoff) String() string {
+	if b.retries == 0 {
+		return "DeadlineAware(constant=" + b.base.String() + ")"
+	}
+	return "DeadlineAware(window=" + b.window.String() + ", attempts=" + strconv.FormatUint(b.retries+1, 10) + ")"
+}
@@ -0,0 +1,98 @@
+package retry_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestDeadlineAwareBackoff(t *testing.T) {
+	t.Parallel()
+
+	t.Run("fits_window", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		b := retry.NewDeadlineAware(ctx, 5)
+		delays := retry.Simulate(b, 6)
+
+		var sum time.Duration
+		for i, d := range delays[:4] {
+			sum += d
+			if i > 0 && d != 2*delays[i-1] {
+				t.Errorf("expected %v to double %v", d, delays[i-1])
+			}
+		}
+		if min, max := 29*time.Second, 30*time.Second; sum < min || sum > max {
+			t.Errorf("expected %v to be between %v and %v", sum, min, max)
+		}
+
+		// The last planned delay is repeated.
+		if got, want := delays[4:], []time.Duration{delays[3], delays[3]}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+
+		b.(retry.Resettable).Reset()
+		if got, want := retry.Simulate(b, 6), delays; !reflect.DeepEqual(got, want) {
+			t.Errorf("after reset: expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("no_deadline", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.NewDeadlineAware(context.Background(), 5)
+		if got, want := retry.Simulate(b, 3), []time.Duration{1 * time.Second, 1 * time.Second, 1 * time.Second}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-1*time.Second))
+		defer cancel()
+
+		b := retry.NewDeadlineAware(ctx, 3)
+		if got, want := retry.Simulate(b, 2), []time.Duration{0, 0}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("retries", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+		defer cancel()
+
+		var calls int
+		err := retry.Do(ctx, retry.NewDeadlineAware(ctx, 4), func(_ context.Context) error {
+			calls++
+			return retry.RetryableError(context.DeadlineExceeded)
+		})
+		if err == nil {
+			t.Fatal("expected error")
+		}
+
+		// Execution time is negligible, so all attempts fit before the deadline.
+		if min, max := 3, 5; calls < min || calls > max {
+			t.Errorf("expected %v to be between %v and %v", calls, min, max)
+		}
+	})
+
+	t.Run("panics", func(t *testing.T) {
+		t.Parallel()
+
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("expected panic")
+			}
+		}()
+		retry.NewDeadlineAware(context.Background(), 1)
+	})
+}
@@ -0,0 +1,101 @@
+package retry_test
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestDecorrelatedJitterBackoff(t *testing.T) {
+	t.Parallel()
+
+	t.Run("bounds", func(t *testing.T) {
+		t.Parallel()
+
+		base, cap := 10*time.Millisecond, 1*time.Second
+
+		b, err := retry.NewDecorrelatedJitter(base, cap)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		prev := base
+		for i := 0; i < 100_000; i++ {
+			val, stop := b.Next()
+			if stop {
+				t.Errorf("should not stop")
+			}
+
+			max := prev * 3
+			if max > cap {
+				max = cap
+			}
+			if val < base || val > max {
+				t.Errorf("expected %v to be between %v and %v", val, base, max)
+			}
+			prev = val
+		}
+	})
+
+	t.Run("overflow", func(t *testing.T) {
+		t.Parallel()
+
+		b, err := retry.NewDecorrelatedJitter(math.MaxInt64/2, math.MaxInt64)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i := 0; i < 100; i++ {
+			val, _ := b.Next()
+			if val < math.MaxInt64/2 {
+				t.Errorf("expected %v to be at least %v", val, time.Duration(math.MaxInt64/2))
+			}
+		}
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		t.Parallel()
+
+		b, err := retry.NewDecorrelatedJitter(1*time.Millisecond, 1*time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 100; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				b.Next()
+			}()
+		}
+		wg.Wait()
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		if _, err := retry.NewDecorrelatedJitter(0, 1*time.Second); err == nil {
+			t.Errorf("expected error for zero base")
+		}
+		if _, err := retry.NewDecorrelatedJitter(2*time.Second, 1*time.Second); err == nil {
+			t.Errorf("expected error for cap less than base")
+		}
+	})
+}
+
+func ExampleNewDecorrelatedJitter() {
+	b, err := retry.NewDecorrelatedJitter(100*time.Millisecond, 10*time.Second)
+	if err != nil {
+		// handle error
+	}
+
+	for i := 0; i < 5; i++ {
+		val, _ := b.Next()
+		fmt.Printf("%v\n", val)
+	}
+}
@@ -0,0 +1,84 @@
+package retry
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type decreasingBackoff struct {
+	start  time.Duration
+	floor  time.Duration
+	factor float64
+
+	lock sync.Mutex
+	next time.Duration
+}
+
+// NewDecreasing creates a new backoff that starts long and shrinks toward floor
+// by multiplying each delay by factor: start, start*factor, start*factor^2, and
+// so on. Once a delay would drop below floor, it returns floor from then on,
+// and it never stops on its own. This models optimistic polling of a degraded
+// service that tightens as conditions improve.
+//
+// It returns an error if factor is not between 0 and 1, exclusive, if floor is
+// less than or equal to zero, or if start is less than floor.
+func NewDecreasing(start, floor time.Duration, factor float64) (Backoff, error) {
+	if !(factor > 0 && factor < 1) {
+		return nil, fmt.Errorf("factor must be greater than 0 and less than 1")
+	}
+	if floor <= 0 {
+		return nil, fmt.Errorf("floor must be greater than 0")
+	}
+	if start < floor {
+		return nil, fmt.Errorf("start must be greater than or equal to floor")
+	}
+
+	return &decreasingBackoff{
+		start:  start,
+		floor:  floor,
+		factor: factor,
+		next:   start,
+	}, nil
+}
+
+// Next implements Backoff. It is safe for concurrent use.
+func (b *decreasingBackoff) Next() (time.Duration, bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	val := b.next
+	if next := time.Duration(float64(b.next) * b.factor); next > b.floor {
+		b.next = next
+	} else {
+		b.next = b.floor
+	}
+	return val, false
+}
+
+// Reset implements Resettable. It is safe for concurrent use.
+func (b *decreasingBackoff) Reset() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.next = b.start
+}
+
+// Clone implements Cloneable. It is safe for concurrent use.
+func (b *decreasingBackoff) Clone() Backoff {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return &decreasingBackoff{
+		start:  b.start,
+		floor:  b.floor,
+		factor: b.factor,
+		next:   b.next,
+	}
+}
+
+// String implements fmt.Stringer.
+func (b *decreasingBackoff) String() string {
+	return "Decreasing(start=" + b.start.String() + ", floor=" + b.floor.String() + ", factor=" + strconv.FormatFloat(b.factor, 'g', -1, 64) + ")"
+}
@@ -0,0 +1,104 @@
+package retry_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestDecreasingBackoff(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name   string
+		start  time.Duration
+		floor  time.Duration
+		factor float64
+		exp    []time.Duration
+		err    bool
+	}{
+		{
+			name:   "hits_and_holds_floor",
+			start:  8 * time.Second,
+			floor:  1500 * time.Millisecond,
+			factor: 0.5,
+			exp: []time.Duration{
+				8 * time.Second,
+				4 * time.Second,
+				2 * time.Second,
+				1500 * time.Millisecond,
+				1500 * time.Millisecond,
+				1500 * time.Millisecond,
+			},
+		},
+		{
+			name:   "start_is_floor",
+			start:  1 * time.Second,
+			floor:  1 * time.Second,
+			factor: 0.9,
+			exp:    []time.Duration{1 * time.Second, 1 * time.Second, 1 * time.Second},
+		},
+		{
+			name:   "factor_one",
+			start:  2 * time.Second,
+			floor:  1 * time.Second,
+			factor: 1,
+			err:    true,
+		},
+		{
+			name:   "factor_zero",
+			start:  2 * time.Second,
+			floor:  1 * time.Second,
+			factor: 0,
+			err:    true,
+		},
+		{
+			name:   "factor_nan",
+			start:  2 * time.Second,
+			floor:  1 * time.Second,
+			factor: math.NaN(),
+			err:    true,
+		},
+		{
+			name:   "zero_floor",
+			start:  2 * time.Second,
+			factor: 0.5,
+			err:    true,
+		},
+		{
+			name:   "start_below_floor",
+			start:  1 * time.Second,
+			floor:  2 * time.Second,
+			factor: 0.5,
+			err:    true,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			b, err := retry.NewDecreasing(tc.start, tc.floor, tc.factor)
+			if (err != nil) != tc.err {
+				t.Fatalf("expected error to be %t, got %v", tc.err, err)
+			}
+			if err != nil {
+				return
+			}
+
+			if got := retry.Simulate(b, len(tc.exp)); !reflect.DeepEqual(got, tc.exp) {
+				t.Errorf("expected %v to be %v", got, tc.exp)
+			}
+
+			b.(retry.Resettable).Reset()
+			if got := retry.Simulate(b, len(tc.exp)); !reflect.DeepEqual(got, tc.exp) {
+				t.Errorf("after reset: expected %v to be %v", got, tc.exp)
+			}
+		})
+	}
+}
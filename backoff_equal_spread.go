@@ -0,0 +1,65 @@
+package retry
+
+import (
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+type equalSpreadBackoff struct {
+	interval time.Duration
+	retries  uint64
+	attempt  uint64
+}
+
+// NewEqualSpread creates a new backoff that spreads the given number of
+// attempts evenly across total, returning total/(attempts-1) between each
+// attempt and stopping after the last one. For example, 5 attempts over 10
+// seconds waits 2.5s between attempts. This is useful when the total window and
+// the number of attempts matter, but the growth curve does not.
+//
+// It returns an error if attempts is less than 2 or total is less than or equal
+// to zero.
+func NewEqualSpread(attempts int, total time.Duration) (Backoff, error) {
+	if attempts < 2 {
+		return nil, fmt.Errorf("attempts must be at least 2")
+	}
+	if total <= 0 {
+		return nil, fmt.Errorf("total must be greater than 0")
+	}
+
+	retries := uint64(attempts - 1)
+	return &equalSpreadBackoff{
+		interval: total / time.Duration(retries),
+		retries:  retries,
+	}, nil
+}
+
+// Next implements Backoff. It is safe for concurrent use.
+func (b *equalSpreadBackoff) Next() (time.Duration, bool) {
+	if atomic.AddUint64(&b.attempt, 1) > b.retries {
+		atomic.AddUint64(&b.attempt, ^uint64(0))
+		return 0, true
+	}
+	return b.interval, false
+}
+
+// Reset implements Resettable. It is safe for concurrent use.
+func (b *equalSpreadBackoff) Reset() {
+	atomic.StoreUint64(&b.attempt, 0)
+}
+
+// Clone implements Cloneable. It is safe for concurrent use.
+func (b *equalSpreadBackoff) Clone() Backoff {
+	return &equalSpreadBackoff{
+		interval: b.interval,
+		retries:  b.retries,
+		attempt:  atomic.LoadUint64(&b.attempt),
+	}
+}
+
+// String implements fmt.Stringer.
+func (b *equalSpreadBackoff) String() string {
+	return "EqualSpread(attempts=" + strconv.FormatUint(b.retries+1, 10) + ", interval=" + b.interval.String() + ")"
+}
@@ -0,0 +1,77 @@
+package retry_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestEqualSpreadBackoff(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name     string
+		attempts int
+		total    time.Duration
+		exp      []time.Duration
+		err      bool
+	}{
+		{
+			name:     "two",
+			attempts: 2,
+			total:    1 * time.Second,
+			exp:      []time.Duration{1 * time.Second},
+		},
+		{
+			name:     "many",
+			attempts: 5,
+			total:    10 * time.Second,
+			exp: []time.Duration{
+				2500 * time.Millisecond,
+				2500 * time.Millisecond,
+				2500 * time.Millisecond,
+				2500 * time.Millisecond,
+			},
+		},
+		{
+			name:     "one_attempt",
+			attempts: 1,
+			total:    1 * time.Second,
+			err:      true,
+		},
+		{
+			name:     "zero_total",
+			attempts: 3,
+			total:    0,
+			err:      true,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			b, err := retry.NewEqualSpread(tc.attempts, tc.total)
+			if tc.err {
+				if err == nil {
+					t.Errorf("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := retry.Simulate(b, 100); !reflect.DeepEqual(got, tc.exp) {
+				t.Errorf("expected %v to be %v", got, tc.exp)
+			}
+			if _, stop := b.Next(); !stop {
+				t.Errorf("should stop")
+			}
+		})
+	}
+}
@@ -2,7 +2,9 @@ package retry
 
 import (
 	"context"
+	"fmt"
 	"math"
+	"strconv"
 	"sync/atomic"
 	"time"
 )
@@ -12,6 +14,12 @@ type exponentialBackoff struct {
 	attempt uint64
 }
 
+type multiplierBackoff struct {
+	base       time.Duration
+	multiplier float64
+	attempt    uint64
+}
+
 // Exponential is a wrapper around Retry that uses an exponential backoff. See
 // NewExponential.
 func Exponential(ctx context.Context, base time.Duration, f RetryFunc) error {
@@ -26,22 +34,152 @@ func Exponential(ctx context.Context, base time.Duration, f RetryFunc) error {
 //
 // It panics if the given base is less than zero.
 func NewExponential(base time.Duration) Backoff {
+	b, err := NewExponentialBase(base, 2)
+	if err != nil {
+		panic(err.Error())
+	}
+	return b
+}
+
+// NewExponentialBase creates a new exponential backoff using the starting value
+// of base and multiplying by multiplier on each failure. For example, a
+// multiplier of 1.5 produces (1, 1.5, 2.25, 3.375, 5.0625...). Values are
+// rounded to the nearest nanosecond.
+//
+// Once it overflows, the function constantly returns the maximum time.Duration
+// for a 64-bit integer.
+//
+// It returns an error if the given base is less than or equal to zero, or if
+// the multiplier is less than or equal to one.
+func NewExponentialBase(base time.Duration, multiplier float64) (Backoff, error) {
 	if base <= 0 {
-		panic("base must be greater than 0")
+		return nil, fmt.Errorf("base must be greater than 0")
 	}
 
-	return &exponentialBackoff{
-		base: base,
+	if !(multiplier > 1) {
+		return nil, fmt.Errorf("multiplier must be greater than 1")
 	}
+
+	// Doubling is common enough to warrant exact, allocation-free bit shifts.
+	if multiplier == 2 {
+		return &exponentialBackoff{
+			base: base,
+		}, nil
+	}
+
+	return &multiplierBackoff{
+		base:       base,
+		multiplier: multiplier,
+	}, nil
 }
 
 // Next implements Backoff. It is safe for concurrent use.
 func (b *exponentialBackoff) Next() (time.Duration, bool) {
-	next := b.base << (atomic.AddUint64(&b.attempt, 1) - 1)
-	if next <= 0 {
+	attempt := atomic.AddUint64(&b.attempt, 1) - 1
+
+	// Saturate instead of wrapping when the next doubling would overflow.
+	if attempt >= 63 || b.base > math.MaxInt64>>attempt {
+		atomic.AddUint64(&b.attempt, ^uint64(0))
+		return math.MaxInt64, false
+	}
+
+	return b.base << attempt, false
+}
+
+// Reset implements Resettable. It is safe for concurrent use.
+func (b *exponentialBackoff) Reset() {
+	atomic.StoreUint64(&b.attempt, 0)
+}
+
+// Clone implements Cloneable. It is safe for concurrent use.
+func (b *exponentialBackoff) Clone() Backoff {
+	return &exponentialBackoff{
+		base:    b.base,
+		attempt: atomic.LoadUint64(&b.attempt),
+	}
+}
+
+// MarshalState implements StateMarshaler. It is safe for concurrent use.
+func (b *exponentialBackoff) MarshalState() ([]byte, error) {
+	return marshalState(backoffState{
+		Kind:    "exponential",
+		Base:    b.base,
+		Attempt: atomic.LoadUint64(&b.attempt),
+	})
+}
+
+// RestoreState implements StateMarshaler. It is safe for concurrent use.
+func (b *exponentialBackoff) RestoreState(data []byte) error {
+	s, err := unmarshalState(data, "exponential", b.base)
+	if err != nil {
+		return err
+	}
+	if s.Multiplier != 0 {
+		return fmt.Errorf("state is for multiplier %g, not 2", s.Multiplier)
+	}
+
+	atomic.StoreUint64(&b.attempt, s.Attempt)
+	return nil
+}
+
+// Next implements Backoff. It is safe for concurrent use.
+func (b *multiplierBackoff) Next() (time.Duration, bool) {
+	attempt := atomic.AddUint64(&b.attempt, 1) - 1
+
+	// Saturate instead of wrapping when the next value would overflow.
+	next := math.Round(float64(b.base) * math.Pow(b.multiplier, float64(attempt)))
+	if next >= math.MaxInt64 {
 		atomic.AddUint64(&b.attempt, ^uint64(0))
-		next = math.MaxInt64
+		return math.MaxInt64, false
 	}
 
-	return next, false
+	return time.Duration(next), false
+}
+
+// Reset implements Resettable. It is safe for concurrent use.
+func (b *multiplierBackoff) Reset() {
+	atomic.StoreUint64(&b.attempt, 0)
+}
+
+// Clone implements Cloneable. It is safe for concurrent use.
+func (b *multiplierBackoff) Clone() Backoff {
+	return &multiplierBackoff{
+		base:       b.base,
+		multiplier: b.multiplier,
+		attempt:    atomic.LoadUint64(&b.attempt),
+	}
+}
+
+// MarshalState implements StateMarshaler. It is safe for concurrent use.
+func (b *multiplierBackoff) MarshalState() ([]byte, error) {
+	return marshalState(backoffState{
+		Kind:       "exponential",
+		Base:       b.base,
+		Multiplier: b.multiplier,
+		Attempt:    atomic.LoadUint64(&b.attempt),
+	})
+}
+
+// RestoreState implements StateMarshaler. It is safe for concurrent use.
+func (b *multiplierBackoff) RestoreState(data []byte) error {
+	s, err := unmarshalState(data, "exponential", b.base)
+	if err != nil {
+		return err
+	}
+	if s.Multiplier != b.multiplier {
+		return fmt.Errorf("state is for multiplier %g, not %g", s.Multiplier, b.multiplier)
+	}
+
+	atomic.StoreUint64(&b.attempt, s.Attempt)
+	return nil
+}
+
+// String implements fmt.Stringer.
+func (b *exponentialBackoff) String() string {
+	return "Exponential(base=" + b.base.String() + ")"
+}
+
+// String implements fmt.Stringer.
+func (b *multiplierBackoff) String() string {
+	return "Exponential(base=" + b.base.String() + ", multiplier=" + strconv.FormatFloat(b.multiplier, 'g', -1, 64) + ")"
 }
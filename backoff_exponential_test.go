@@ -104,6 +104,108 @@ func TestExponentialBackoff(t *testing.T) {
 	}
 }
 
+func TestExponentialBackoff_saturates(t *testing.T) {
+	t.Parallel()
+
+	for _, base := range []time.Duration{1, 3, 5, 7 * time.Millisecond, 1 * time.Second} {
+		b := retry.NewExponential(base)
+
+		var prev time.Duration
+		for i := 0; i < 70; i++ {
+			val, stop := b.Next()
+			if stop {
+				t.Errorf("should not stop")
+			}
+			if val <= 0 {
+				t.Fatalf("base %v: attempt %d: expected %v to be positive", base, i, val)
+			}
+			if val < prev {
+				t.Fatalf("base %v: attempt %d: expected %v to be at least %v", base, i, val, prev)
+			}
+			prev = val
+		}
+
+		if got, want := prev, time.Duration(math.MaxInt64); got != want {
+			t.Errorf("base %v: expected %v to be %v", base, got, want)
+		}
+	}
+}
+
+func TestExponentialBaseBackoff(t *testing.T) {
+	t.Parallel()
+
+	t.Run("gentle", func(t *testing.T) {
+		t.Parallel()
+
+		b, err := retry.NewExponentialBase(100*time.Millisecond, 1.5)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		exp := []time.Duration{
+			100 * time.Millisecond,
+			150 * time.Millisecond,
+			225 * time.Millisecond,
+			337_500 * time.Microsecond,
+			506_250 * time.Microsecond,
+			759_375 * time.Microsecond,
+		}
+		if got := retry.Simulate(b, len(exp)); !reflect.DeepEqual(got, exp) {
+			t.Errorf("expected %v to be %v", got, exp)
+		}
+	})
+
+	t.Run("rounds", func(t *testing.T) {
+		t.Parallel()
+
+		b, err := retry.NewExponentialBase(1*time.Nanosecond, 1.5)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// 1, 1.5, 2.25, 3.375, 5.0625
+		exp := []time.Duration{1, 2, 2, 3, 5}
+		if got := retry.Simulate(b, len(exp)); !reflect.DeepEqual(got, exp) {
+			t.Errorf("expected %v to be %v", got, exp)
+		}
+	})
+
+	t.Run("saturates", func(t *testing.T) {
+		t.Parallel()
+
+		b, err := retry.NewExponentialBase(1*time.Second, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var prev time.Duration
+		for i := 0; i < 100; i++ {
+			val, _ := b.Next()
+			if val < prev {
+				t.Fatalf("attempt %d: expected %v to be at least %v", i, val, prev)
+			}
+			prev = val
+		}
+		if got, want := prev, time.Duration(math.MaxInt64); got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		if _, err := retry.NewExponentialBase(0, 2); err == nil {
+			t.Errorf("expected error for zero base")
+		}
+		if _, err := retry.NewExponentialBase(1*time.Second, 1); err == nil {
+			t.Errorf("expected error for multiplier of 1")
+		}
+		if _, err := retry.NewExponentialBase(1*time.Second, math.NaN()); err == nil {
+			t.Errorf("expected error for NaN multiplier")
+		}
+	})
+}
+
 func ExampleNewExponential() {
 	b := retry.NewExponential(1 * time.Second)
 
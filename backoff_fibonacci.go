@@ -2,6 +2,7 @@ package retry
 
 import (
 	"context"
+	"fmt"
 	"math"
 	"sync/atomic"
 	"time"
@@ -11,6 +12,7 @@ import (
 type state [2]time.Duration
 
 type fibonacciBackoff struct {
+	base  time.Duration
 	state unsafe.Pointer
 }
 
@@ -34,6 +36,7 @@ func NewFibonacci(base time.Duration) Backoff {
 	}
 
 	return &fibonacciBackoff{
+		base:  base,
 		state: unsafe.Pointer(&state{0, base}),
 	}
 }
@@ -43,14 +46,59 @@ func (b *fibonacciBackoff) Next() (time.Duration, bool) {
 	for {
 		curr := atomic.LoadPointer(&b.state)
 		currState := (*state)(curr)
-		next := currState[0] + currState[1]
 
-		if next <= 0 {
+		// Saturate instead of wrapping when the next sum would overflow.
+		if currState[1] > math.MaxInt64-currState[0] {
 			return math.MaxInt64, false
 		}
+		next := currState[0] + currState[1]
 
 		if atomic.CompareAndSwapPointer(&b.state, curr, unsafe.Pointer(&state{currState[1], next})) {
 			return next, false
 		}
 	}
 }
+
+// Reset implements Resettable. It is safe for concurrent use.
+func (b *fibonacciBackoff) Reset() {
+	atomic.StorePointer(&b.state, unsafe.Pointer(&state{0, b.base}))
+}
+
+// Clone implements Cloneable. It is safe for concurrent use.
+func (b *fibonacciBackoff) Clone() Backoff {
+	currState := *(*state)(atomic.LoadPointer(&b.state))
+	return &fibonacciBackoff{
+		base:  b.base,
+		state: unsafe.Pointer(&currState),
+	}
+}
+
+// MarshalState implements StateMarshaler. It is safe for concurrent use.
+func (b *fibonacciBackoff) MarshalState() ([]byte, error) {
+	currState := *(*state)(atomic.LoadPointer(&b.state))
+	return marshalState(backoffState{
+		Kind: "fibonacci",
+		Base: b.base,
+		Prev: currState[0],
+		Curr: currState[1],
+	})
+}
+
+// RestoreState implements StateMarshaler. It is safe for concurrent use.
+func (b *fibonacciBackoff) RestoreState(data []byte) error {
+	s, err := unmarshalState(data, "fibonacci", b.base)
+	if err != nil {
+		return err
+	}
+	if s.Prev < 0 || s.Curr < b.base || s.Prev > s.Curr {
+		return fmt.Errorf("invalid fibonacci state %s, %s", s.Prev, s.Curr)
+	}
+
+	atomic.StorePointer(&b.state, unsafe.Pointer(&state{s.Prev, s.Curr}))
+	return nil
+}
+
+// String implements fmt.Stringer.
+func (b *fibonacciBackoff) String() string {
+	return "Fibonacci(base=" + b.base.String() + ")"
+}
@@ -116,6 +116,33 @@ func TestFibonacciBackoff(t *testing.T) {
 	}
 }
 
+func TestFibonacciBackoff_saturates(t *testing.T) {
+	t.Parallel()
+
+	for _, base := range []time.Duration{1, 7 * time.Millisecond, 1 * time.Second} {
+		b := retry.NewFibonacci(base)
+
+		var prev time.Duration
+		for i := 0; i < 100; i++ {
+			val, stop := b.Next()
+			if stop {
+				t.Errorf("should not stop")
+			}
+			if val <= 0 {
+				t.Fatalf("base %v: attempt %d: expected %v to be positive", base, i, val)
+			}
+			if val < prev {
+				t.Fatalf("base %v: attempt %d: expected %v to be at least %v", base, i, val, prev)
+			}
+			prev = val
+		}
+
+		if got, want := prev, time.Duration(math.MaxInt64); got != want {
+			t.Errorf("base %v: expected %v to be %v", base, got, want)
+		}
+	}
+}
+
 func ExampleNewFibonacci() {
 	b := retry.NewFibonacci(1 * time.Second)
 
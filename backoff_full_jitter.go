@@ -0,0 +1,95 @@
+package retry
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+type fullJitterBackoff struct {
+	base time.Duration
+	cap  time.Duration
+
+	// ceil is min(cap, base * 2^attempt) for the current attempt.
+	ceil time.Duration
+
+	r *lockedSource
+	l sync.Mutex
+}
+
+// NewFullJitter creates a new full jitter backoff, following the "Full Jitter"
+// algorithm from the AWS Architecture Blog post "Exponential Backoff And
+// Jitter". Each wait time is a random value between zero and an exponentially
+// growing ceiling, capped at cap:
+//
+//	next = random_between(0, min(cap, base * 2^attempt))
+//
+// where attempt starts at 0. This differs from composing NewExponential with
+// WithCappedDuration and a jitter middleware, which jitters around the capped
+// value rather than below it. Unlike NewDecorrelatedJitter, each value depends
+// only on the attempt number and not on the previous value, and it may be as
+// low as zero. It returns an error if base is less than or equal to zero, or if
+// cap is less than base.
+func NewFullJitter(base, cap time.Duration) (Backoff, error) {
+	if base <= 0 {
+		return nil, fmt.Errorf("base must be greater than 0")
+	}
+
+	if cap < base {
+		return nil, fmt.Errorf("cap must be greater than or equal to base")
+	}
+
+	return &fullJitterBackoff{
+		base: base,
+		cap:  cap,
+		ceil: base,
+		r:    newLockedRandom(time.Now().UnixNano()),
+	}, nil
+}
+
+// Next implements Backoff. It is safe for concurrent use.
+func (b *fullJitterBackoff) Next() (time.Duration, bool) {
+	b.l.Lock()
+	defer b.l.Unlock()
+
+	ceil := b.ceil
+
+	// Double the ceiling for the next attempt, stopping at the cap so it never
+	// overflows.
+	if b.ceil > b.cap/2 {
+		b.ceil = b.cap
+	} else {
+		b.ceil *= 2
+	}
+
+	if ceil == math.MaxInt64 {
+		return time.Duration(b.r.Int63()), false
+	}
+	return time.Duration(b.r.Int63n(int64(ceil) + 1)), false
+}
+
+// Reset implements Resettable. It is safe for concurrent use.
+func (b *fullJitterBackoff) Reset() {
+	b.l.Lock()
+	defer b.l.Unlock()
+	b.ceil = b.base
+}
+
+// Clone implements Cloneable. It is safe for concurrent use.
+func (b *fullJitterBackoff) Clone() Backoff {
+	b.l.Lock()
+	defer b.l.Unlock()
+
+	return &fullJitterBackoff{
+		base: b.base,
+		cap:  b.cap,
+		ceil: b.ceil,
+		r:    b.r,
+	}
+}
+
+// String implements fmt.Stringer.
+func (b *fullJitterBackoff) String() string {
+	return "FullJitter(base=" + b.base.String() + ", cap=" + b.cap.String() + ")"
+}
@@ -0,0 +1,95 @@
+package retry_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestFullJitterBackoff(t *testing.T) {
+	t.Parallel()
+
+	t.Run("bounds", func(t *testing.T) {
+		t.Parallel()
+
+		base, cap := 10*time.Millisecond, 1*time.Second
+
+		b, err := retry.NewFullJitter(base, cap)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ceil := base
+		var sum time.Duration
+		for i := 0; i < 100_000; i++ {
+			val, stop := b.Next()
+			if stop {
+				t.Errorf("should not stop")
+			}
+
+			if val < 0 || val > ceil {
+				t.Fatalf("attempt %d: expected %v to be between 0 and %v", i, val, ceil)
+			}
+			if i >= 10 {
+				sum += val
+			}
+
+			if ceil *= 2; ceil > cap {
+				ceil = cap
+			}
+		}
+
+		// Once capped, values are uniform in [0, cap], so the mean is about
+		// cap/2.
+		mean := sum / (100_000 - 10)
+		if diff := mean - cap/2; diff < -10*time.Millisecond || diff > 10*time.Millisecond {
+			t.Errorf("expected mean %v to be about %v", mean, cap/2)
+		}
+	})
+
+	t.Run("overflow", func(t *testing.T) {
+		t.Parallel()
+
+		b, err := retry.NewFullJitter(math.MaxInt64/2, math.MaxInt64)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i := 0; i < 100; i++ {
+			if val, _ := b.Next(); val < 0 {
+				t.Errorf("expected %v to be at least 0", val)
+			}
+		}
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		t.Parallel()
+
+		base := 1 * time.Millisecond
+		b, err := retry.NewFullJitter(base, 1*time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 20; i++ {
+			b.Next()
+		}
+
+		b.(retry.Resettable).Reset()
+		if val, _ := b.Next(); val > base {
+			t.Errorf("expected %v to be at most %v", val, base)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		if _, err := retry.NewFullJitter(0, 1*time.Second); err == nil {
+			t.Errorf("expected error")
+		}
+		if _, err := retry.NewFullJitter(2*time.Second, 1*time.Second); err == nil {
+			t.Errorf("expected error")
+		}
+	})
+}
@@ -0,0 +1,53 @@
+package retry
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+type funcBackoff struct {
+	fn      func(attempt int) (time.Duration, bool)
+	attempt int64
+}
+
+// NewFunc creates a new backoff whose delays are computed by fn from the
+// 0-based index of the call to Next, which the backoff tracks itself. This
+// makes it easy to express a closed-form curve, such as a square root or a
+// logarithm, without keeping state in a closure as a BackoffFunc would. fn
+// signals stop by returning true, and is called concurrently if Next is.
+//
+// Reset rewinds the index to 0. It panics if fn is nil.
+func NewFunc(fn func(attempt int) (time.Duration, bool)) Backoff {
+	if fn == nil {
+		panic("fn must not be nil")
+	}
+
+	return &funcBackoff{
+		fn: fn,
+	}
+}
+
+// Next implements Backoff. It is safe for concurrent use if fn is.
+func (b *funcBackoff) Next() (time.Duration, bool) {
+	attempt := atomic.AddInt64(&b.attempt, 1) - 1
+	return b.fn(int(attempt))
+}
+
+// Reset implements Resettable. It is safe for concurrent use.
+func (b *funcBackoff) Reset() {
+	atomic.StoreInt64(&b.attempt, 0)
+}
+
+// Clone implements Cloneable. It is safe for concurrent use. The clone shares
+// fn, but tracks its index independently.
+func (b *funcBackoff) Clone() Backoff {
+	return &funcBackoff{
+		fn:      b.fn,
+		attempt: atomic.LoadInt64(&b.attempt),
+	}
+}
+
+// String implements fmt.Stringer.
+func (b *funcBackoff) String() string {
+	return "Func()"
+}
@@ -0,0 +1,94 @@
+package retry_test
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestFuncBackoff(t *testing.T) {
+	t.Parallel()
+
+	t.Run("attempts", func(t *testing.T) {
+		t.Parallel()
+
+		var attempts []int
+		b := retry.NewFunc(func(attempt int) (time.Duration, bool) {
+			attempts = append(attempts, attempt)
+			return time.Duration(attempt) * time.Second, attempt == 3
+		})
+
+		got := retry.Simulate(b, 10)
+		if want := []time.Duration{0, 1 * time.Second, 2 * time.Second}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if want := []int{0, 1, 2, 3}; !reflect.DeepEqual(attempts, want) {
+			t.Errorf("expected %v to be %v", attempts, want)
+		}
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.NewFunc(func(attempt int) (time.Duration, bool) {
+			return time.Duration(attempt), false
+		})
+		retry.Simulate(b, 5)
+		b.(retry.Resettable).Reset()
+
+		if val, _ := b.Next(); val != 0 {
+			t.Errorf("expected %v to be %v", val, 0)
+		}
+	})
+
+	t.Run("clone", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.NewFunc(func(attempt int) (time.Duration, bool) {
+			return time.Duration(attempt), false
+		})
+		b.Next()
+
+		c := retry.Clone(b)
+		b.Next()
+		if val, _ := c.Next(); val != 1 {
+			t.Errorf("expected %v to be %v", val, 1)
+		}
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		t.Parallel()
+
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("expected panic")
+			}
+		}()
+		retry.NewFunc(nil)
+	})
+}
+
+func ExampleNewFunc() {
+	// A logarithmic backoff: the delay grows quickly at first, then levels off.
+	b := retry.NewFunc(func(attempt int) (time.Duration, bool) {
+		d := time.Duration(math.Log2(float64(attempt)+2) * float64(time.Second))
+		return d.Round(time.Millisecond), false
+	})
+
+	for i := 0; i < 7; i++ {
+		val, _ := b.Next()
+		fmt.Printf("%v\n", val)
+	}
+	// Output:
+	// 1s
+	// 1.585s
+	// 2s
+	// 2.322s
+	// 2.585s
+	// 2.807s
+	// 3s
+}
@@ -0,0 +1,61 @@
+package retry
+
+import (
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+type linearBackoff struct {
+	base    time.Duration
+	attempt uint64
+}
+
+// NewLinear creates a new linear backoff using the starting value of base and
+// adding base on each failure (1, 2, 3, 4, 5, 6, 7...).
+//
+// Once it overflows, the function constantly returns the maximum time.Duration
+// for a 64-bit integer.
+//
+// It returns an error if the given base is less than or equal to zero.
+func NewLinear(base time.Duration) (Backoff, error) {
+	if base <= 0 {
+		return nil, fmt.Errorf("base must be greater than 0")
+	}
+
+	return &linearBackoff{
+		base: base,
+	}, nil
+}
+
+// Next implements Backoff. It is safe for concurrent use.
+func (b *linearBackoff) Next() (time.Duration, bool) {
+	attempt := atomic.AddUint64(&b.attempt, 1)
+
+	// Saturate instead of wrapping when the next step would overflow.
+	if attempt > uint64(math.MaxInt64/b.base) {
+		atomic.AddUint64(&b.attempt, ^uint64(0))
+		return math.MaxInt64, false
+	}
+
+	return b.base * time.Duration(attempt), false
+}
+
+// Reset implements Resettable. It is safe for concurrent use.
+func (b *linearBackoff) Reset() {
+	atomic.StoreUint64(&b.attempt, 0)
+}
+
+// Clone implements Cloneable. It is safe for concurrent use.
+func (b *linearBackoff) Clone() Backoff {
+	return &linearBackoff{
+		base:    b.base,
+		attempt: atomic.LoadUint64(&b.attempt),
+	}
+}
+
+// String implements fmt.Stringer.
+func (b *linearBackoff) String() string {
+	return "Linear(base=" + b.base.String() + ")"
+}
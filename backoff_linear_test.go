@@ -0,0 +1,104 @@
+package retry_test
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestLinearBackoff(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name  string
+		base  time.Duration
+		tries int
+		exp   []time.Duration
+	}{
+		{
+			name:  "single",
+			base:  1 * time.Nanosecond,
+			tries: 1,
+			exp: []time.Duration{
+				1 * time.Nanosecond,
+			},
+		},
+		{
+			name:  "many",
+			base:  3 * time.Nanosecond,
+			tries: 5,
+			exp: []time.Duration{
+				3 * time.Nanosecond,
+				6 * time.Nanosecond,
+				9 * time.Nanosecond,
+				12 * time.Nanosecond,
+				15 * time.Nanosecond,
+			},
+		},
+		{
+			name:  "overflow",
+			base:  math.MaxInt64 / 3,
+			tries: 5,
+			exp: []time.Duration{
+				math.MaxInt64 / 3,
+				math.MaxInt64 / 3 * 2,
+				math.MaxInt64 / 3 * 3,
+				math.MaxInt64,
+				math.MaxInt64,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			b, err := retry.NewLinear(tc.base)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			results := make([]time.Duration, tc.tries)
+			for i := 0; i < tc.tries; i++ {
+				results[i], _ = b.Next()
+			}
+
+			if !reflect.DeepEqual(results, tc.exp) {
+				t.Errorf("expected \n\n%v\n\n to be \n\n%v\n\n", results, tc.exp)
+			}
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		if _, err := retry.NewLinear(0); err == nil {
+			t.Errorf("expected error")
+		}
+	})
+}
+
+func ExampleNewLinear() {
+	b, err := retry.NewLinear(1 * time.Second)
+	if err != nil {
+		// handle error
+	}
+	b = retry.WithCappedDuration(3*time.Second, b)
+
+	for i := 0; i < 5; i++ {
+		val, _ := b.Next()
+		fmt.Printf("%v\n", val)
+	}
+	// Output:
+	// 1s
+	// 2s
+	// 3s
+	// 3s
+	// 3s
+}
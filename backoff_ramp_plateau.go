@@ -0,0 +1,102 @@
+package retry
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type rampPlateauBackoff struct {
+	base    time.Duration
+	cap     time.Duration
+	plateau int
+
+	lock sync.Mutex
+
+	// next is the next ramp delay, and n is the number of delays returned at
+	// the cap.
+	next time.Duration
+	n    int
+}
+
+// NewRampPlateau creates a new backoff that ramps up exponentially from base,
+// doubling each time, until the delay would reach cap. It then returns cap
+// plateauAttempts more times before it stops. For example, a base of 1s, a cap
+// of 5s, and 2 plateau attempts return 1s, 2s, 4s, 5s, 5s, and then stop. Only
+// delays below cap count as the ramp, so a delay that lands exactly on cap
+// is the first of the plateau.
+//
+// Unlike wrapping an exponential backoff in WithCappedDuration and
+// WithMaxRetries, the number of attempts at the cap does not depend on how
+// long the ramp takes.
+//
+// It returns an error if base is less than or equal to zero, cap is less than
+// base, or plateauAttempts is less than zero.
+func NewRampPlateau(base, cap time.Duration, plateauAttempts int) (Backoff, error) {
+	if base <= 0 {
+		return nil, fmt.Errorf("base must be greater than 0")
+	}
+	if cap < base {
+		return nil, fmt.Errorf("cap must be greater than or equal to base")
+	}
+	if plateauAttempts < 0 {
+		return nil, fmt.Errorf("plateauAttempts must be greater than or equal to 0")
+	}
+
+	return &rampPlateauBackoff{
+		base:    base,
+		cap:     cap,
+		plateau: plateauAttempts,
+		next:    base,
+	}, nil
+}
+
+// Next implements Backoff. It is safe for concurrent use.
+func (b *rampPlateauBackoff) Next() (time.Duration, bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.next < b.cap {
+		val := b.next
+		if b.next > b.cap/2 {
+			b.next = b.cap
+		} else {
+			b.next *= 2
+		}
+		return val, false
+	}
+
+	if b.n >= b.plateau {
+		return 0, true
+	}
+	b.n++
+	return b.cap, false
+}
+
+// Reset implements Resettable. It is safe for concurrent use.
+func (b *rampPlateauBackoff) Reset() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.next, b.n = b.base, 0
+}
+
+// Clone implements Cloneable. It is safe for concurrent use.
+func (b *rampPlateauBackoff) Clone() Backoff {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return &rampPlateauBackoff{
+		base:    b.base,
+		cap:     b.cap,
+		plateau: b.plateau,
+		next:    b.next,
+		n:       b.n,
+	}
+}
+
+// String implements fmt.Stringer.
+func (b *rampPlateauBackoff) String() string {
+	return "RampPlateau(base=" + b.base.String() + ", cap=" + b.cap.String() + ", plateau=" + strconv.Itoa(b.plateau) + ")"
+}
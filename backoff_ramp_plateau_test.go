@@ -0,0 +1,119 @@
+package retry_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestRampPlateauBackoff(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		base    time.Duration
+		cap     time.Duration
+		plateau int
+		exp     []time.Duration
+		err     bool
+	}{
+		{
+			name:    "ramp_then_plateau",
+			base:    1 * time.Second,
+			cap:     5 * time.Second,
+			plateau: 3,
+			exp: []time.Duration{
+				1 * time.Second,
+				2 * time.Second,
+				4 * time.Second,
+				5 * time.Second,
+				5 * time.Second,
+				5 * time.Second,
+			},
+		},
+		{
+			name:    "exact_cap",
+			base:    1 * time.Second,
+			cap:     4 * time.Second,
+			plateau: 2,
+			exp: []time.Duration{
+				1 * time.Second,
+				2 * time.Second,
+				4 * time.Second,
+				4 * time.Second,
+			},
+		},
+		{
+			name:    "no_plateau",
+			base:    1 * time.Second,
+			cap:     3 * time.Second,
+			plateau: 0,
+			exp:     []time.Duration{1 * time.Second, 2 * time.Second},
+		},
+		{
+			name:    "base_is_cap",
+			base:    1 * time.Second,
+			cap:     1 * time.Second,
+			plateau: 2,
+			exp:     []time.Duration{1 * time.Second, 1 * time.Second},
+		},
+		{
+			name:    "large_cap",
+			base:    1 * time.Nanosecond,
+			cap:     time.Duration(1<<62 + 1),
+			plateau: 1,
+			exp: func() []time.Duration {
+				var exp []time.Duration
+				for i := 0; i <= 62; i++ {
+					exp = append(exp, time.Duration(1)<<i)
+				}
+				return append(exp, time.Duration(1<<62+1))
+			}(),
+		},
+		{
+			name: "zero_base",
+			cap:  1 * time.Second,
+			err:  true,
+		},
+		{
+			name: "cap_below_base",
+			base: 2 * time.Second,
+			cap:  1 * time.Second,
+			err:  true,
+		},
+		{
+			name:    "negative_plateau",
+			base:    1 * time.Second,
+			cap:     2 * time.Second,
+			plateau: -1,
+			err:     true,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			b, err := retry.NewRampPlateau(tc.base, tc.cap, tc.plateau)
+			if (err != nil) != tc.err {
+				t.Fatalf("expected error to be %t, got %v", tc.err, err)
+			}
+			if err != nil {
+				return
+			}
+
+			if got := retry.Simulate(b, 100); !reflect.DeepEqual(got, tc.exp) {
+				t.Errorf("expected %v to be %v", got, tc.exp)
+			}
+
+			b.(retry.Resettable).Reset()
+			if got := retry.Simulate(b, 100); !reflect.DeepEqual(got, tc.exp) {
+				t.Errorf("after reset: expected %v to be %v", got, tc.exp)
+			}
+		})
+	}
+}
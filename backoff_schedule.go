@@ -0,0 +1,75 @@
+package retry
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type scheduleBackoff struct {
+	lock  sync.Mutex
+	times []time.Time
+	i     int
+}
+
+// NewSchedule creates a new backoff that schedules retries at the given
+// wall-clock times instead of after relative delays. Each call to Next returns
+// the duration from now until the next scheduled time, and the backoff stops
+// once all times have been used. If a scheduled time is already in the past
+// when it is reached, Next returns 0 so the attempt fires immediately.
+//
+// The times are copied and sorted, so the caller may reuse the slice.
+func NewSchedule(times []time.Time) Backoff {
+	sorted := make([]time.Time, len(times))
+	copy(sorted, times)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Before(sorted[j])
+	})
+
+	return &scheduleBackoff{
+		times: sorted,
+	}
+}
+
+// Next implements Backoff. It is safe for concurrent use.
+func (b *scheduleBackoff) Next() (time.Duration, bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.i >= len(b.times) {
+		return 0, true
+	}
+
+	d := time.Until(b.times[b.i])
+	b.i++
+
+	if d < 0 {
+		d = 0
+	}
+	return d, false
+}
+
+// Reset implements Resettable. It is safe for concurrent use.
+func (b *scheduleBackoff) Reset() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.i = 0
+}
+
+// Clone implements Cloneable. It is safe for concurrent use.
+func (b *scheduleBackoff) Clone() Backoff {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return &scheduleBackoff{
+		times: b.times,
+		i:     b.i,
+	}
+}
+
+// String implements fmt.Stringer.
+func (b *scheduleBackoff) String() string {
+	return "Schedule(times=" + strconv.Itoa(len(b.times)) + ")"
+}
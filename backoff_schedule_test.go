@@ -0,0 +1,75 @@
+package retry_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestScheduleBackoff(t *testing.T) {
+	t.Parallel()
+
+	t.Run("future", func(t *testing.T) {
+		t.Parallel()
+
+		now := time.Now()
+		b := retry.NewSchedule([]time.Time{
+			now.Add(2 * time.Hour),
+			now.Add(1 * time.Hour),
+		})
+
+		for _, want := range []time.Duration{1 * time.Hour, 2 * time.Hour} {
+			val, stop := b.Next()
+			if stop {
+				t.Fatalf("expected not to stop")
+			}
+			if val > want || val < want-time.Minute {
+				t.Errorf("expected %v to be about %v", val, want)
+			}
+		}
+
+		if _, stop := b.Next(); !stop {
+			t.Errorf("expected to stop")
+		}
+	})
+
+	t.Run("past", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.NewSchedule([]time.Time{
+			time.Now().Add(-1 * time.Hour),
+		})
+
+		val, stop := b.Next()
+		if stop {
+			t.Fatalf("expected not to stop")
+		}
+		if got, want := val, time.Duration(0); got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.NewSchedule(nil)
+		if _, stop := b.Next(); !stop {
+			t.Errorf("expected to stop")
+		}
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.NewSchedule([]time.Time{
+			time.Now().Add(-1 * time.Hour),
+		})
+		b.Next()
+
+		b.(retry.Resettable).Reset()
+		if _, stop := b.Next(); stop {
+			t.Errorf("expected not to stop")
+		}
+	})
+}
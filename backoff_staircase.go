@@ -0,0 +1,97 @@
+package retry
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// StaircaseStep is a step of a staircase backoff: Delay is returned Count times
+// before moving on to the next step.
+type StaircaseStep struct {
+	Delay time.Duration
+	Count int
+}
+
+type staircaseBackoff struct {
+	lock  sync.Mutex
+	steps []StaircaseStep
+
+	// i is the index of the current step, and n is the number of times its
+	// delay has been returned.
+	i int
+	n int
+}
+
+// NewStaircase creates a new backoff that returns the delay of each step the
+// configured number of times before moving on to the next step, and stops after
+// the last step. For example, steps of {100ms, 3}, {500ms, 3}, and {2s, 3}
+// tolerate a few quick failures before escalating. The steps are copied, so the
+// caller may reuse the slice.
+//
+// It returns an error if there are no steps, if any count is less than or
+// equal to zero, or if any delay is less than zero.
+func NewStaircase(steps []StaircaseStep) (Backoff, error) {
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("steps must not be empty")
+	}
+
+	for i, s := range steps {
+		if s.Count <= 0 {
+			return nil, fmt.Errorf("step %d: count must be greater than 0", i)
+		}
+		if s.Delay < 0 {
+			return nil, fmt.Errorf("step %d: delay must be greater than or equal to 0", i)
+		}
+	}
+
+	s := make([]StaircaseStep, len(steps))
+	copy(s, steps)
+
+	return &staircaseBackoff{
+		steps: s,
+	}, nil
+}
+
+// Next implements Backoff. It is safe for concurrent use.
+func (b *staircaseBackoff) Next() (time.Duration, bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.i < len(b.steps) && b.n >= b.steps[b.i].Count {
+		b.i++
+		b.n = 0
+	}
+	if b.i >= len(b.steps) {
+		return 0, true
+	}
+
+	b.n++
+	return b.steps[b.i].Delay, false
+}
+
+// Reset implements Resettable. It is safe for concurrent use.
+func (b *staircaseBackoff) Reset() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.i, b.n = 0, 0
+}
+
+// Clone implements Cloneable. It is safe for concurrent use.
+func (b *staircaseBackoff) Clone() Backoff {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return &staircaseBackoff{
+		steps: b.steps,
+		i:     b.i,
+		n:     b.n,
+	}
+}
+
+// String implements fmt.Stringer.
+func (b *staircaseBackoff) String() string {
+	return "Staircase(steps=" + strconv.Itoa(len(b.steps)) + ")"
+}
@@ -0,0 +1,84 @@
+package retry_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestStaircaseBackoff(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name  string
+		steps []retry.StaircaseStep
+		exp   []time.Duration
+		err   bool
+	}{
+		{
+			name: "steps",
+			steps: []retry.StaircaseStep{
+				{Delay: 100 * time.Millisecond, Count: 3},
+				{Delay: 500 * time.Millisecond, Count: 2},
+				{Delay: 2 * time.Second, Count: 1},
+			},
+			exp: []time.Duration{
+				100 * time.Millisecond,
+				100 * time.Millisecond,
+				100 * time.Millisecond,
+				500 * time.Millisecond,
+				500 * time.Millisecond,
+				2 * time.Second,
+			},
+		},
+		{
+			name: "zero_delay",
+			steps: []retry.StaircaseStep{
+				{Delay: 0, Count: 2},
+				{Delay: 1 * time.Second, Count: 1},
+			},
+			exp: []time.Duration{0, 0, 1 * time.Second},
+		},
+		{
+			name: "empty",
+			err:  true,
+		},
+		{
+			name:  "zero_count",
+			steps: []retry.StaircaseStep{{Delay: 1 * time.Second, Count: 0}},
+			err:   true,
+		},
+		{
+			name:  "negative_delay",
+			steps: []retry.StaircaseStep{{Delay: -1, Count: 1}},
+			err:   true,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			b, err := retry.NewStaircase(tc.steps)
+			if (err != nil) != tc.err {
+				t.Fatalf("expected error to be %t, got %v", tc.err, err)
+			}
+			if err != nil {
+				return
+			}
+
+			if got := retry.Simulate(b, 100); !reflect.DeepEqual(got, tc.exp) {
+				t.Errorf("expected %v to be %v", got, tc.exp)
+			}
+
+			b.(retry.Resettable).Reset()
+			if got := retry.Simulate(b, 100); !reflect.DeepEqual(got, tc.exp) {
+				t.Errorf("after reset: expected %v to be %v", got, tc.exp)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package retry
+
+import (
+	"sync"
+	"time"
+)
+
+type steadyTickerBackoff struct {
+	interval time.Duration
+
+	lock  sync.Mutex
+	start time.Time
+}
+
+// NewSteadyTicker creates a new backoff that keeps attempts on a steady cadence
+// of interval, regardless of how long each attempt takes. Each call to Next
+// returns interval minus the time elapsed since the last attempt started,
+// clamped to zero, so attempts happen at t0, t0+interval, t0+2*interval, and so
+// on instead of drifting by the cumulative execution time. It never stops on
+// its own. If an attempt takes longer than interval, the next one starts
+// immediately and the cadence continues from there.
+//
+// The first attempt is assumed to start when the backoff is created or Reset,
+// so create it right before the retry loop. It panics if interval is less than
+// or equal to zero.
+func NewSteadyTicker(interval time.Duration) Backoff {
+	if interval <= 0 {
+		panic("interval must be greater than 0")
+	}
+
+	return &steadyTickerBackoff{
+		interval: interval,
+		start:    time.Now(),
+	}
+}
+
+// Next implements Backoff. It is safe for concurrent use.
+func (b *steadyTickerBackoff) Next() (time.Duration, bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	now := time.Now()
+	val := b.interval - now.Sub(b.start)
+	if val < 0 {
+		val = 0
+	}
+	b.start = now.Add(val)
+	return val, false
+}
+
+// Reset implements Resettable. It is safe for concurrent use.
+func (b *steadyTickerBackoff) Reset() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.start = time.Now()
+}
+
+// Clone implements Cloneable. It is safe for concurrent use.
+func (b *steadyTickerBackoff) Clone() Backoff {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return &steadyTickerBackoff{
+		interval: b.interval,
+		start:    b.start,
+	}
+}
+
+// String implements fmt.Stringer.
+func (b *steadyTickerBackoff) String() string {
+	return "SteadyTicker(interval=" + b.interval.String() + ")"
+}
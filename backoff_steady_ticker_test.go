@@ -0,0 +1,93 @@
+package retry_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestSteadyTickerBackoff(t *testing.T) {
+	t.Parallel()
+
+	t.Run("corrects_drift", func(t *testing.T) {
+		t.Parallel()
+
+		const (
+			interval = 50 * time.Millisecond
+			work     = 20 * time.Millisecond
+			attempts = 4
+		)
+
+		start := time.Now()
+		b := retry.WithMaxRetries(attempts-1, retry.NewSteadyTicker(interval))
+
+		var starts []time.Duration
+		_ = retry.Do(context.Background(), b, func(_ context.Context) error {
+			starts = append(starts, time.Since(start))
+			time.Sleep(work)
+			return retry.RetryableError(context.DeadlineExceeded)
+		})
+
+		if got, want := len(starts), attempts; got != want {
+			t.Fatalf("expected %v to be %v", got, want)
+		}
+
+		// Without drift correction, the last attempt would start at
+		// 3*(interval+work) = 210ms instead of 3*interval = 150ms. Allow half
+		// the accumulated drift as scheduling slack.
+		last := starts[len(starts)-1]
+		if want := (attempts - 1) * interval; last < want || last >= want+(attempts-1)*work/2 {
+			t.Errorf("expected %v to be about %v", last, want)
+		}
+	})
+
+	t.Run("overrun", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.NewSteadyTicker(1 * time.Millisecond)
+		time.Sleep(5 * time.Millisecond)
+
+		val, stop := b.Next()
+		if stop {
+			t.Fatalf("expected not to stop")
+		}
+		if got, want := val, time.Duration(0); got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.NewSteadyTicker(1 * time.Hour)
+		time.Sleep(5 * time.Millisecond)
+
+		b.(retry.Resettable).Reset()
+		if val, _ := b.Next(); val < time.Hour-time.Minute {
+			t.Errorf("expected %v to be about %v", val, time.Hour)
+		}
+	})
+
+	t.Run("string", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.NewSteadyTicker(1 * time.Second)
+		if got, want := fmt.Sprint(b), "SteadyTicker(interval=1s)"; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("panics", func(t *testing.T) {
+		t.Parallel()
+
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("expected panic")
+			}
+		}()
+		retry.NewSteadyTicker(0)
+	})
+}
@@ -1,7 +1,17 @@
 package retry_test
 
 import (
+	"bytes"
 	"context"
+	crand "crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"reflect"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -34,6 +44,63 @@ func ExampleBackoffFunc() {
 	}
 }
 
+func ExampleBackoffFunc_inline() {
+	ctx := context.Background()
+
+	// A one-off backoff defined inline, without a named type.
+	b := retry.BackoffFunc(func() (time.Duration, bool) {
+		return 100 * time.Millisecond, false
+	})
+
+	if err := retry.Do(ctx, retry.WithMaxRetries(3, b), func(_ context.Context) error {
+		// TODO: logic here
+		return nil
+	}); err != nil {
+		// handle error
+	}
+}
+
+func TestDefault(t *testing.T) {
+	t.Parallel()
+
+	t.Run("defaults", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.Default()
+		for i := 0; i < 10; i++ {
+			val, stop := b.Next()
+			if stop {
+				t.Fatalf("should not stop at retry %d", i)
+			}
+
+			if min, max := time.Duration(0), 10*time.Second; val < min || val > max {
+				t.Errorf("expected %v to be between %v and %v", val, min, max)
+			}
+			if i == 0 && val > 100*time.Millisecond {
+				t.Errorf("expected %v to be at most %v", val, 100*time.Millisecond)
+			}
+		}
+
+		if _, stop := b.Next(); !stop {
+			t.Error("expected stop after 10 retries")
+		}
+	})
+
+	t.Run("independent", func(t *testing.T) {
+		t.Parallel()
+
+		b1 := retry.Default()
+		for i := 0; i < 11; i++ {
+			b1.Next()
+		}
+
+		b2 := retry.Default()
+		if _, stop := b2.Next(); stop {
+			t.Error("expected a new instance not to share state")
+		}
+	})
+}
+
 func TestWithJitter(t *testing.T) {
 	t.Parallel()
 
@@ -66,6 +133,150 @@ func ExampleWithJitter() {
 	}
 }
 
+func TestWithJitterRand(t *testing.T) {
+	t.Parallel()
+
+	newBackoff := func() retry.Backoff {
+		return retry.WithJitterRand(250*time.Millisecond, rand.New(rand.NewSource(42)), retry.BackoffFunc(func() (time.Duration, bool) {
+			return 1 * time.Second, false
+		}))
+	}
+
+	b1, b2 := newBackoff(), newBackoff()
+	for i := 0; i < 1_000; i++ {
+		val1, stop1 := b1.Next()
+		val2, stop2 := b2.Next()
+		if stop1 || stop2 {
+			t.Errorf("should not stop")
+		}
+
+		if val1 != val2 {
+			t.Fatalf("attempt %d: expected %v to be %v", i, val1, val2)
+		}
+		if min, max := 750*time.Millisecond, 1250*time.Millisecond; val1 < min || val1 > max {
+			t.Errorf("expected %v to be between %v and %v", val1, min, max)
+		}
+	}
+}
+
+func TestWithJitterReader(t *testing.T) {
+	t.Parallel()
+
+	constant := func() retry.Backoff {
+		return retry.BackoffFunc(func() (time.Duration, bool) {
+			return 1 * time.Second, false
+		})
+	}
+
+	t.Run("bounds", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.WithJitterReader(crand.Reader, 250*time.Millisecond, constant())
+		for i := 0; i < 10_000; i++ {
+			val, stop := b.Next()
+			if stop {
+				t.Fatal("should not stop")
+			}
+			if min, max := 750*time.Millisecond, 1250*time.Millisecond; val < min || val > max {
+				t.Errorf("expected %v to be between %v and %v", val, min, max)
+			}
+		}
+	})
+
+	t.Run("consumption", func(t *testing.T) {
+		t.Parallel()
+
+		r := bytes.NewReader(make([]byte, 3*retry.JitterReaderBytes))
+		b := retry.WithJitterReader(r, 250*time.Millisecond, constant())
+
+		for i := 0; i < 3; i++ {
+			val, stop := b.Next()
+			if stop {
+				t.Fatalf("call %d: should not stop", i)
+			}
+
+			// All-zero bytes map to the bottom of the range.
+			if got, want := val, 750*time.Millisecond; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+			if got, want := r.Len(), (2-i)*retry.JitterReaderBytes; got != want {
+				t.Errorf("expected %v bytes left, got %v", want, got)
+			}
+		}
+
+		// The reader is exhausted, so the backoff stops.
+		if _, stop := b.Next(); !stop {
+			t.Error("expected stop after the reader is exhausted")
+		}
+	})
+
+	t.Run("short_read", func(t *testing.T) {
+		t.Parallel()
+
+		r := bytes.NewReader(make([]byte, retry.JitterReaderBytes-1))
+		b := retry.WithJitterReader(r, 250*time.Millisecond, constant())
+		if _, stop := b.Next(); !stop {
+			t.Error("expected stop on a short read")
+		}
+	})
+
+	t.Run("no_jitter", func(t *testing.T) {
+		t.Parallel()
+
+		r := bytes.NewReader(nil)
+		b := retry.WithJitterReader(r, 0, constant())
+		if val, stop := b.Next(); stop || val != 1*time.Second {
+			t.Errorf("expected (%v, false), got (%v, %t)", 1*time.Second, val, stop)
+		}
+	})
+}
+
+func TestWithJitterAbove(t *testing.T) {
+	t.Parallel()
+
+	delays := []time.Duration{
+		100 * time.Millisecond,
+		1 * time.Second,
+		5 * time.Second,
+		10 * time.Second,
+	}
+
+	var jittered bool
+	for i := 0; i < 1_000; i++ {
+		var n int
+		b := retry.WithJitterAbove(1*time.Second, 500*time.Millisecond, retry.BackoffFunc(func() (time.Duration, bool) {
+			val := delays[n%len(delays)]
+			n++
+			return val, false
+		}))
+
+		for _, want := range delays {
+			val, stop := b.Next()
+			if stop {
+				t.Fatal("should not stop")
+			}
+
+			if want <= 1*time.Second {
+				if val != want {
+					t.Errorf("expected %v to be %v", val, want)
+				}
+				continue
+			}
+
+			if min, max := want-500*time.Millisecond, want+500*time.Millisecond; val < min || val > max {
+				t.Errorf("expected %v to be between %v and %v", val, min, max)
+			}
+			if val != want {
+				jittered = true
+			}
+		}
+	}
+
+	if !jittered {
+		t.Error("expected delays above the threshold to be jittered")
+	}
+}
+
 func TestWithJitterPercent(t *testing.T) {
 	t.Parallel()
 
@@ -84,6 +295,47 @@ func TestWithJitterPercent(t *testing.T) {
 	}
 }
 
+func TestWithJitterPercent_bounds(t *testing.T) {
+	t.Parallel()
+
+	t.Run("zero_percent", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.WithJitterPercent(0, retry.NewConstant(1*time.Second))
+		for i := 0; i < 100; i++ {
+			if val, _ := b.Next(); val != 1*time.Second {
+				t.Errorf("expected %v to be %v", val, 1*time.Second)
+			}
+		}
+	})
+
+	t.Run("clamps_at_zero", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.WithJitterPercent(200, retry.NewConstant(1*time.Second))
+		for i := 0; i < 10_000; i++ {
+			if val, _ := b.Next(); val < 0 || val > 3*time.Second {
+				t.Errorf("expected %v to be between %v and %v", val, 0, 3*time.Second)
+			}
+		}
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.WithJitterPercent(5, retry.BackoffFunc(func() (time.Duration, bool) {
+			return 1 * time.Second, true
+		}))
+		val, stop := b.Next()
+		if !stop {
+			t.Errorf("should stop")
+		}
+		if val != 0 {
+			t.Errorf("expected %v to be %v", val, 0)
+		}
+	})
+}
+
 func ExampleWithJitterPercent() {
 	ctx := context.Background()
 
@@ -98,6 +350,56 @@ func ExampleWithJitterPercent() {
 	}
 }
 
+func TestWithCenteredJitter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("bounds_and_mean", func(t *testing.T) {
+		t.Parallel()
+
+		const samples = 10000
+		d, spread := 1*time.Second, 500*time.Millisecond
+		b := retry.WithCenteredJitter(spread, retry.NewConstant(d))
+
+		var sum time.Duration
+		for i := 0; i < samples; i++ {
+			val, stop := b.Next()
+			if stop {
+				t.Fatalf("should not stop")
+			}
+			if min, max := d-spread, d+spread; val < min || val > max {
+				t.Fatalf("expected %v to be between %v and %v", val, min, max)
+			}
+			sum += val
+		}
+
+		// The standard error of the mean is about 3ms, so 25ms is generous.
+		mean := sum / samples
+		if diff := mean - d; diff < -25*time.Millisecond || diff > 25*time.Millisecond {
+			t.Errorf("expected mean %v to be about %v", mean, d)
+		}
+	})
+
+	t.Run("clamps_at_zero", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.WithCenteredJitter(5*time.Second, retry.NewConstant(1*time.Second))
+		for i := 0; i < 1000; i++ {
+			if val, _ := b.Next(); val < 0 {
+				t.Fatalf("expected %v to be at least 0", val)
+			}
+		}
+	})
+
+	t.Run("zero_spread", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.WithCenteredJitter(0, retry.NewConstant(1*time.Second))
+		if val, _ := b.Next(); val != 1*time.Second {
+			t.Errorf("expected %v to be %v", val, 1*time.Second)
+		}
+	})
+}
+
 func TestWithMaxRetries(t *testing.T) {
 	t.Parallel()
 
@@ -126,6 +428,232 @@ func TestWithMaxRetries(t *testing.T) {
 	}
 }
 
+func TestWithMaxRetries_boundaries(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name  string
+		max   uint64
+		calls int
+	}{
+		{"zero", 0, 1},
+		{"one", 1, 2},
+		{"large", math.MaxUint64, 50},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			b := retry.WithMaxRetries(tc.max, retry.NewConstant(1*time.Nanosecond))
+
+			var calls int
+			_ = retry.Do(ctx, b, func(_ context.Context) error {
+				calls++
+				if calls == 50 {
+					return nil
+				}
+				return retry.RetryableError(fmt.Errorf("oops"))
+			})
+			if got, want := calls, tc.calls; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+		})
+	}
+}
+
+func TestWithMaxAttempts(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name  string
+		n     uint64
+		calls int
+	}{
+		{"zero", 0, 1},
+		{"one", 1, 1},
+		{"two", 2, 2},
+		{"five", 5, 5},
+		{"large", math.MaxUint64, 50},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			b := retry.WithMaxAttempts(tc.n, retry.NewConstant(1*time.Nanosecond))
+
+			var calls int
+			_ = retry.Do(ctx, b, func(_ context.Context) error {
+				calls++
+				if calls == 50 {
+					return nil
+				}
+				return retry.RetryableError(fmt.Errorf("oops"))
+			})
+			if got, want := calls, tc.calls; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+		})
+	}
+}
+
+func TestWithMaxRetriesByError(t *testing.T) {
+	t.Parallel()
+
+	errRateLimited := errors.New("rate limited")
+	errTimeout := errors.New("timeout")
+	errOther := errors.New("other")
+
+	cases := []struct {
+		name  string
+		err   func(call int) error
+		calls int
+	}{
+		{
+			name:  "listed",
+			err:   func(int) error { return errRateLimited },
+			calls: 2,
+		},
+		{
+			name:  "wrapped",
+			err:   func(int) error { return fmt.Errorf("dial: %w", errTimeout) },
+			calls: 6,
+		},
+		{
+			name:  "fallback",
+			err:   func(int) error { return errOther },
+			calls: 4,
+		},
+		{
+			name:  "smallest_limit",
+			err:   func(int) error { return errors.Join(errTimeout, errRateLimited) },
+			calls: 2,
+		},
+		{
+			name: "independent_classes",
+			err: func(call int) error {
+				// Alternate, so each class reaches its limit separately.
+				if call%2 == 1 {
+					return errTimeout
+				}
+				return errRateLimited
+			},
+			// timeout, rate limited, timeout, then rate limited exceeds its limit
+			calls: 4,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			b := retry.WithMaxRetriesByError(map[error]int{
+				errRateLimited: 1,
+				errTimeout:     5,
+			}, 3, retry.NewConstant(1*time.Nanosecond))
+
+			// The error reaches the backoff through middleware.
+			b = retry.WithCappedDuration(1*time.Second, b)
+
+			var calls int
+			err := retry.Do(ctx, b, func(_ context.Context) error {
+				calls++
+				return retry.RetryableError(tc.err(calls))
+			})
+			if !errors.Is(err, retry.ErrBackoffStopped) {
+				t.Errorf("expected %#v to be %#v", err, retry.ErrBackoffStopped)
+			}
+
+			if got, want := calls, tc.calls; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+		})
+	}
+
+	t.Run("reset", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.WithMaxRetriesByError(nil, 2, retry.NewConstant(1*time.Second))
+		if got, want := len(retry.Simulate(b, 10)), 2; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+
+		b.(retry.Resettable).Reset()
+		if got, want := len(retry.Simulate(b, 10)), 2; got != want {
+			t.Errorf("after reset: expected %v to be %v", got, want)
+		}
+	})
+}
+
+func TestWithEscalateOnRepeat(t *testing.T) {
+	t.Parallel()
+
+	t.Run("escalates", func(t *testing.T) {
+		t.Parallel()
+
+		errA := errors.New("a")
+		errB := errors.New("b")
+		errs := []error{
+			errA,
+			errA,                         // repeat: escalate
+			errB,                         // changed: flat
+			fmt.Errorf("wrap: %w", errB), // repeat through wrapping: escalate
+			errors.New("wrap: b"),        // repeat by message: escalate
+			errA,                         // changed: flat
+			errA,                         // repeat: escalate
+		}
+
+		ctx := context.Background()
+		var delays []time.Duration
+		b := retry.WithEscalateOnRepeat(retry.NewExponential(1 * time.Millisecond))
+		b = retry.WithMaxRetries(uint64(len(errs))-1, b)
+
+		var i int
+		_, err := retry.DoWithDelayObserver(ctx, b, func(_ context.Context) (int, error) {
+			err := errs[i]
+			i++
+			return 0, retry.RetryableError(err)
+		}, func(d time.Duration) {
+			delays = append(delays, d)
+		})
+		if !errors.Is(err, retry.ErrBackoffStopped) {
+			t.Errorf("expected %#v to be %#v", err, retry.ErrBackoffStopped)
+		}
+
+		exp := []time.Duration{
+			1 * time.Millisecond,
+			2 * time.Millisecond,
+			2 * time.Millisecond,
+			4 * time.Millisecond,
+			8 * time.Millisecond,
+			8 * time.Millisecond,
+		}
+		if !reflect.DeepEqual(delays, exp) {
+			t.Errorf("expected %v to be %v", delays, exp)
+		}
+	})
+
+	t.Run("without_errors", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.WithEscalateOnRepeat(retry.NewExponential(1 * time.Second))
+		exp := []time.Duration{1 * time.Second, 2 * time.Second, 4 * time.Second}
+		if got := retry.Simulate(b, 3); !reflect.DeepEqual(got, exp) {
+			t.Errorf("expected %v to be %v", got, exp)
+		}
+	})
+}
+
 func ExampleWithMaxRetries() {
 	ctx := context.Background()
 
@@ -156,6 +684,177 @@ func TestWithCappedDuration(t *testing.T) {
 	}
 }
 
+func TestWithDeadlineFraction(t *testing.T) {
+	t.Parallel()
+
+	t.Run("deadline", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 400*time.Millisecond)
+		defer cancel()
+
+		// The fraction sits below other middleware, so the deadline has to be
+		// forwarded to it.
+		b := retry.WithMaxRetries(1, retry.WithDeadlineFraction(0.25, retry.NewConstant(1*time.Hour)))
+
+		var delays []time.Duration
+		_, err := retry.DoWithDelayObserver(ctx, b, func(_ context.Context) (struct{}, error) {
+			return struct{}{}, retry.RetryableError(errors.New("oops"))
+		}, func(d time.Duration) {
+			delays = append(delays, d)
+		})
+		if err == nil {
+			t.Fatal("expected error")
+		}
+
+		if got, want := len(delays), 1; got != want {
+			t.Fatalf("expected %v to be %v", got, want)
+		}
+		if d := delays[0]; d <= 0 || d > 100*time.Millisecond {
+			t.Errorf("expected %v to be at most %v", d, 100*time.Millisecond)
+		}
+	})
+
+	t.Run("no_deadline", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.WithDeadlineFraction(0.25, retry.NewConstant(1*time.Second))
+		if val, stop := b.Next(); stop || val != 1*time.Second {
+			t.Errorf("expected (%v, false), got (%v, %t)", 1*time.Second, val, stop)
+		}
+	})
+
+	t.Run("reused_without_deadline", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.WithDeadlineFraction(0.25, retry.NewConstant(10*time.Millisecond))
+
+		// Each loop fails its first attempt, so it sleeps exactly once.
+		run := func(ctx context.Context) []time.Duration {
+			var delays []time.Duration
+			var attempts int
+			if _, err := retry.DoWithDelayObserver(ctx, b, func(_ context.Context) (struct{}, error) {
+				attempts++
+				if attempts == 1 {
+					return struct{}{}, retry.RetryableError(errors.New("oops"))
+				}
+				return struct{}{}, nil
+			}, func(d time.Duration) {
+				delays = append(delays, d)
+			}); err != nil {
+				t.Fatal(err)
+			}
+			return delays
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		run(ctx)
+		<-ctx.Done()
+
+		// The first loop's deadline has expired, and must not cap a later loop
+		// that has none.
+		delays := run(context.Background())
+		if got, want := len(delays), 1; got != want {
+			t.Fatalf("expected %v to be %v", got, want)
+		}
+		if got, want := delays[0], 10*time.Millisecond; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		for _, fraction := range []float64{0, -0.5, 1.5, math.NaN()} {
+			func() {
+				defer func() {
+					if r := recover(); r == nil {
+						t.Errorf("expected panic for %v", fraction)
+					}
+				}()
+				retry.WithDeadlineFraction(fraction, retry.NewConstant(1*time.Second))
+			}()
+		}
+	})
+}
+
+func TestWithCappedDuration_belowBase(t *testing.T) {
+	t.Parallel()
+
+	// A cap below the base is accepted and silently flattens the backoff.
+	b := retry.WithCappedDuration(1*time.Second, retry.NewExponential(2*time.Second))
+
+	for i := 0; i < 3; i++ {
+		val, stop := b.Next()
+		if stop {
+			t.Errorf("should not stop")
+		}
+		if val != 1*time.Second {
+			t.Errorf("expected %v to be %v", val, 1*time.Second)
+		}
+	}
+}
+
+func TestWithCappedDurationStrict(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		cap  time.Duration
+		min  time.Duration
+		err  bool
+	}{
+		{
+			name: "valid",
+			cap:  3 * time.Second,
+			min:  1 * time.Second,
+		},
+		{
+			name: "equal",
+			cap:  1 * time.Second,
+			min:  1 * time.Second,
+		},
+		{
+			name: "below_base",
+			cap:  1 * time.Second,
+			min:  2 * time.Second,
+			err:  true,
+		},
+		{
+			name: "zero",
+			cap:  0,
+			min:  0,
+			err:  true,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			b, err := retry.WithCappedDurationStrict(tc.cap, tc.min, retry.NewExponential(tc.min+1))
+			if tc.err {
+				if err == nil {
+					t.Errorf("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i := 0; i < 5; i++ {
+				if val, _ := b.Next(); val > tc.cap {
+					t.Errorf("expected %v to be at most %v", val, tc.cap)
+				}
+			}
+		})
+	}
+}
+
 func ExampleWithCappedDuration() {
 	ctx := context.Background()
 
@@ -170,24 +869,229 @@ func ExampleWithCappedDuration() {
 	}
 }
 
-func TestWithMaxDuration(t *testing.T) {
+func TestWithMinDuration(t *testing.T) {
 	t.Parallel()
 
-	b := retry.WithMaxDuration(250*time.Millisecond, retry.BackoffFunc(func() (time.Duration, bool) {
-		return 1 * time.Second, false
-	}))
+	t.Run("raises", func(t *testing.T) {
+		t.Parallel()
 
-	// Take once, within timeout.
-	val, stop := b.Next()
-	if stop {
-		t.Error("should not stop")
-	}
+		b := retry.WithMinDuration(3*time.Second, retry.NewConstant(1*time.Second))
 
-	if val > 250*time.Millisecond {
-		t.Errorf("expected %v to be less than %v", val, 250*time.Millisecond)
-	}
+		val, stop := b.Next()
+		if stop {
+			t.Errorf("should not stop")
+		}
+		if val != 3*time.Second {
+			t.Errorf("expected %v to be %v", val, 3*time.Second)
+		}
+	})
 
-	time.Sleep(200 * time.Millisecond)
+	t.Run("after_jitter", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.WithMinDuration(900*time.Millisecond,
+			retry.WithJitter(500*time.Millisecond, retry.NewConstant(1*time.Second)))
+
+		for i := 0; i < 100_000; i++ {
+			val, stop := b.Next()
+			if stop {
+				t.Errorf("should not stop")
+			}
+			if min, max := 900*time.Millisecond, 1500*time.Millisecond; val < min || val > max {
+				t.Errorf("expected %v to be between %v and %v", val, min, max)
+			}
+		}
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.WithMinDuration(1*time.Second, retry.WithMaxRetries(0, retry.NewConstant(1*time.Second)))
+
+		val, stop := b.Next()
+		if !stop {
+			t.Errorf("should stop")
+		}
+		if val != 0 {
+			t.Errorf("expected %v to be %v", val, 0)
+		}
+	})
+}
+
+func TestWithImmediateFirst(t *testing.T) {
+	t.Parallel()
+
+	t.Run("sequence", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.WithImmediateFirst(retry.NewExponential(1 * time.Second))
+		got := retry.Simulate(b, 4)
+		want := []time.Duration{0, 1 * time.Second, 2 * time.Second, 4 * time.Second}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("max_retries", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.WithMaxRetries(2, retry.WithImmediateFirst(retry.NewConstant(1*time.Second)))
+		if got, want := retry.Simulate(b, 10), []time.Duration{0, 1 * time.Second}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.WithImmediateFirst(retry.NewExponential(1 * time.Second))
+		retry.Simulate(b, 3)
+		b.(retry.Resettable).Reset()
+
+		got := retry.Simulate(b, 2)
+		if want := []time.Duration{0, 1 * time.Second}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+func TestWithMonotonic(t *testing.T) {
+	t.Parallel()
+
+	t.Run("jittered", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.WithMonotonic(retry.WithJitterRand(900*time.Millisecond, rand.New(rand.NewSource(42)),
+			retry.WithMaxRetries(1_000, retry.NewConstant(1*time.Second))))
+
+		delays := retry.Simulate(b, 2_000)
+		if got, want := len(delays), 1_000; got != want {
+			t.Fatalf("expected %v to be %v", got, want)
+		}
+		for i := 1; i < len(delays); i++ {
+			if delays[i] < delays[i-1] {
+				t.Fatalf("attempt %d: expected %v to be at least %v", i, delays[i], delays[i-1])
+			}
+		}
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		t.Parallel()
+
+		var n int
+		values := []time.Duration{3 * time.Second, 1 * time.Second}
+		b := retry.WithMonotonic(retry.BackoffFunc(func() (time.Duration, bool) {
+			val := values[n%len(values)]
+			n++
+			return val, false
+		}))
+
+		if got, want := retry.Simulate(b, 2), []time.Duration{3 * time.Second, 3 * time.Second}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+
+		b.(retry.Resettable).Reset()
+		n = 1
+		if val, _ := b.Next(); val != 1*time.Second {
+			t.Errorf("expected %v to be %v", val, 1*time.Second)
+		}
+	})
+}
+
+func ExampleWithMinDuration() {
+	ctx := context.Background()
+
+	b := retry.NewFibonacci(1 * time.Second)
+	b = retry.WithJitter(500*time.Millisecond, b)
+	b = retry.WithMinDuration(1*time.Second, b)
+
+	if err := retry.Do(ctx, b, func(_ context.Context) error {
+		// TODO: logic here
+		return nil
+	}); err != nil {
+		// handle error
+	}
+}
+
+func TestWithDynamicFactor(t *testing.T) {
+	t.Parallel()
+
+	t.Run("follows_factor", func(t *testing.T) {
+		t.Parallel()
+
+		var pressure uint64
+		atomic.StoreUint64(&pressure, math.Float64bits(1))
+
+		b := retry.WithDynamicFactor(func() float64 {
+			return math.Float64frombits(atomic.LoadUint64(&pressure))
+		}, retry.NewConstant(1*time.Second))
+
+		for _, tc := range []struct {
+			factor float64
+			want   time.Duration
+		}{
+			{1, 1 * time.Second},
+			{2.5, 2500 * time.Millisecond},
+			{0.5, 500 * time.Millisecond},
+			{-1, 0},
+			{math.NaN(), 0},
+			{math.Inf(1), math.MaxInt64},
+		} {
+			atomic.StoreUint64(&pressure, math.Float64bits(tc.factor))
+
+			val, stop := b.Next()
+			if stop {
+				t.Fatalf("should not stop")
+			}
+			if val != tc.want {
+				t.Errorf("factor %v: expected %v to be %v", tc.factor, val, tc.want)
+			}
+		}
+	})
+
+	t.Run("saturates", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.WithDynamicFactor(func() float64 {
+			return 4
+		}, retry.NewConstant(math.MaxInt64/2))
+
+		if val, _ := b.Next(); val != math.MaxInt64 {
+			t.Errorf("expected %v to be %v", val, time.Duration(math.MaxInt64))
+		}
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.WithDynamicFactor(func() float64 {
+			return 2
+		}, retry.WithMaxRetries(0, retry.NewConstant(1*time.Second)))
+
+		if _, stop := b.Next(); !stop {
+			t.Errorf("should stop")
+		}
+	})
+}
+
+func TestWithMaxDuration(t *testing.T) {
+	t.Parallel()
+
+	b := retry.WithMaxDuration(250*time.Millisecond, retry.BackoffFunc(func() (time.Duration, bool) {
+		return 1 * time.Second, false
+	}))
+
+	// Take once, within timeout.
+	val, stop := b.Next()
+	if stop {
+		t.Error("should not stop")
+	}
+
+	if val > 250*time.Millisecond {
+		t.Errorf("expected %v to be less than %v", val, 250*time.Millisecond)
+	}
+
+	time.Sleep(200 * time.Millisecond)
 
 	// Take again, remainder contines
 	val, stop = b.Next()
@@ -224,3 +1128,1027 @@ func ExampleWithMaxDuration() {
 		// handle error
 	}
 }
+
+func TestWithMaxElapsedTime(t *testing.T) {
+	t.Parallel()
+
+	t.Run("stops_after_budget", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.WithMaxElapsedTime(100*time.Millisecond, retry.BackoffFunc(func() (time.Duration, bool) {
+			return 10 * time.Millisecond, false
+		}))
+
+		// The clock starts on the first call.
+		val, stop := b.Next()
+		if stop {
+			t.Error("should not stop")
+		}
+		if val != 10*time.Millisecond {
+			t.Errorf("expected %v to be %v", val, 10*time.Millisecond)
+		}
+
+		time.Sleep(100 * time.Millisecond)
+
+		val, stop = b.Next()
+		if !stop {
+			t.Errorf("should stop")
+		}
+		if val != 0 {
+			t.Errorf("expected %v to be %v", val, 0)
+		}
+	})
+
+	t.Run("stops_instead_of_oversleeping", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.WithMaxElapsedTime(100*time.Millisecond, retry.BackoffFunc(func() (time.Duration, bool) {
+			return 1 * time.Second, false
+		}))
+
+		val, stop := b.Next()
+		if !stop {
+			t.Errorf("should stop")
+		}
+		if val != 0 {
+			t.Errorf("expected %v to be %v", val, 0)
+		}
+	})
+
+	t.Run("composes_with_jitter", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.WithMaxElapsedTime(1*time.Second, retry.WithJitter(5*time.Millisecond, retry.NewConstant(10*time.Millisecond)))
+
+		val, stop := b.Next()
+		if stop {
+			t.Error("should not stop")
+		}
+		if min, max := 5*time.Millisecond, 15*time.Millisecond; val < min || val > max {
+			t.Errorf("expected %v to be between %v and %v", val, min, max)
+		}
+	})
+}
+
+func ExampleWithMaxElapsedTime() {
+	ctx := context.Background()
+
+	b := retry.NewFibonacci(1 * time.Second)
+	b = retry.WithMaxElapsedTime(30*time.Second, b)
+
+	if err := retry.Do(ctx, b, func(_ context.Context) error {
+		// TODO: logic here
+		return nil
+	}); err != nil {
+		// handle error
+	}
+}
+
+func TestResettable(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		b    retry.Backoff
+		exp  []time.Duration
+	}{
+		{
+			name: "exponential",
+			b:    retry.NewExponential(1 * time.Second),
+			exp:  []time.Duration{1 * time.Second, 2 * time.Second, 4 * time.Second},
+		},
+		{
+			name: "fibonacci",
+			b:    retry.NewFibonacci(1 * time.Second),
+			exp:  []time.Duration{1 * time.Second, 2 * time.Second, 3 * time.Second},
+		},
+		{
+			name: "max_retries",
+			b:    retry.WithMaxRetries(2, retry.NewExponential(1*time.Second)),
+			exp:  []time.Duration{1 * time.Second, 2 * time.Second, 0},
+		},
+		{
+			name: "capped_duration",
+			b:    retry.WithCappedDuration(3*time.Second, retry.NewExponential(1*time.Second)),
+			exp:  []time.Duration{1 * time.Second, 2 * time.Second, 3 * time.Second},
+		},
+		{
+			name: "chain",
+			b: retry.WithMaxDuration(1*time.Minute, retry.WithMaxRetries(5,
+				retry.WithCappedDuration(10*time.Second, retry.NewFibonacci(1*time.Second)))),
+			exp: []time.Duration{1 * time.Second, 2 * time.Second, 3 * time.Second},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, ok := tc.b.(retry.Resettable)
+			if !ok {
+				t.Fatalf("expected %T to implement Resettable", tc.b)
+			}
+
+			for i := 0; i < 3; i++ {
+				results := make([]time.Duration, 0, len(tc.exp))
+				for range tc.exp {
+					val, _ := r.Next()
+					results = append(results, val)
+				}
+
+				if !reflect.DeepEqual(results, tc.exp) {
+					t.Errorf("run %d: expected %v to be %v", i, results, tc.exp)
+				}
+				r.Reset()
+			}
+		})
+	}
+}
+
+func ExampleResettable() {
+	ctx := context.Background()
+
+	b := retry.WithMaxRetries(3, retry.NewExponential(1*time.Nanosecond))
+
+	for i := 0; i < 3; i++ {
+		attempts := 0
+		if err := retry.Do(ctx, b, func(_ context.Context) error {
+			attempts++
+			return retry.RetryableError(fmt.Errorf("oops"))
+		}); err != nil {
+			// handle error
+		}
+		fmt.Printf("operation %d: %d attempts\n", i, attempts)
+
+		// Rewind the backoff before reusing it for the next operation.
+		b.(retry.Resettable).Reset()
+	}
+
+	// Output:
+	// operation 0: 4 attempts
+	// operation 1: 4 attempts
+	// operation 2: 4 attempts
+}
+
+func TestWithDeadlineTime(t *testing.T) {
+	t.Parallel()
+
+	t.Run("before", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.WithDeadlineTime(time.Now().Add(1*time.Hour), retry.NewConstant(1*time.Second))
+		if val, stop := b.Next(); stop || val != 1*time.Second {
+			t.Errorf("expected (%v, false), got (%v, %t)", 1*time.Second, val, stop)
+		}
+	})
+
+	t.Run("clamps_near_boundary", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.WithDeadlineTime(time.Now().Add(50*time.Millisecond), retry.NewConstant(1*time.Second))
+		val, stop := b.Next()
+		if stop {
+			t.Fatal("should not stop")
+		}
+		if val <= 0 || val > 50*time.Millisecond {
+			t.Errorf("expected %v to be in (0, %v]", val, 50*time.Millisecond)
+		}
+
+		// Once the deadline passes, the backoff stops.
+		time.Sleep(val)
+		if _, stop := b.Next(); !stop {
+			t.Error("expected stop after the deadline")
+		}
+	})
+
+	t.Run("past", func(t *testing.T) {
+		t.Parallel()
+
+		var calls int
+		b := retry.WithDeadlineTime(time.Now().Add(-1*time.Second), retry.BackoffFunc(func() (time.Duration, bool) {
+			calls++
+			return 1 * time.Second, false
+		}))
+		if _, stop := b.Next(); !stop {
+			t.Error("expected stop")
+		}
+		if got, want := calls, 0; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+func TestWithMaxCumulativeDelay(t *testing.T) {
+	t.Parallel()
+
+	b := retry.WithMaxCumulativeDelay(10*time.Second, retry.NewExponential(1*time.Second))
+
+	exp := []time.Duration{1 * time.Second, 2 * time.Second, 4 * time.Second}
+	for _, want := range exp {
+		val, stop := b.Next()
+		if stop {
+			t.Fatalf("should not stop")
+		}
+		if val != want {
+			t.Errorf("expected %v to be %v", val, want)
+		}
+	}
+
+	// 1s + 2s + 4s + 8s would exceed 10s
+	val, stop := b.Next()
+	if !stop {
+		t.Errorf("should stop")
+	}
+	if val != 0 {
+		t.Errorf("expected %v to be %v", val, 0)
+	}
+}
+
+func ExampleWithMaxCumulativeDelay() {
+	ctx := context.Background()
+
+	b := retry.NewExponential(1 * time.Second)
+	b = retry.WithMaxCumulativeDelay(1*time.Minute, b)
+
+	if err := retry.Do(ctx, b, func(_ context.Context) error {
+		// TODO: logic here
+		return nil
+	}); err != nil {
+		// handle error
+	}
+}
+
+func TestWithGrace(t *testing.T) {
+	t.Parallel()
+
+	t.Run("sequence", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.WithGrace(2, 5*time.Second, retry.WithMaxRetries(2, retry.NewConstant(1*time.Second)))
+		exp := []time.Duration{1 * time.Second, 1 * time.Second, 5 * time.Second, 5 * time.Second}
+		if got := retry.Simulate(b, 10); !reflect.DeepEqual(got, exp) {
+			t.Errorf("expected %v to be %v", got, exp)
+		}
+
+		b.(retry.Resettable).Reset()
+		if got := retry.Simulate(b, 10); !reflect.DeepEqual(got, exp) {
+			t.Errorf("after reset: expected %v to be %v", got, exp)
+		}
+	})
+
+	t.Run("attempts", func(t *testing.T) {
+		t.Parallel()
+
+		for _, extra := range []int{0, 1, 3} {
+			ctx := context.Background()
+			b := retry.WithGrace(extra, 1*time.Nanosecond, retry.WithMaxRetries(2, retry.NewConstant(1*time.Nanosecond)))
+
+			var calls int
+			_ = retry.Do(ctx, b, func(_ context.Context) error {
+				calls++
+				return retry.RetryableError(fmt.Errorf("oops"))
+			})
+
+			if got, want := calls, 3+extra; got != want {
+				t.Errorf("extra %d: expected %v to be %v", extra, got, want)
+			}
+		}
+	})
+}
+
+func TestWithRecoveryWindow(t *testing.T) {
+	t.Parallel()
+
+	t.Run("sequence", func(t *testing.T) {
+		t.Parallel()
+
+		linear, err := retry.NewLinear(1 * time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b := retry.WithRecoveryWindow(3, 1*time.Minute, retry.WithMaxRetries(7, linear))
+
+		exp := []time.Duration{
+			1 * time.Second,
+			2 * time.Second,
+			1 * time.Minute,
+			4 * time.Second,
+			5 * time.Second,
+			1 * time.Minute,
+			7 * time.Second,
+		}
+		if got := retry.Simulate(b, 10); !reflect.DeepEqual(got, exp) {
+			t.Errorf("expected %v to be %v", got, exp)
+		}
+
+		b.(retry.Resettable).Reset()
+		if got := retry.Simulate(b, 10); !reflect.DeepEqual(got, exp) {
+			t.Errorf("after reset: expected %v to be %v", got, exp)
+		}
+	})
+
+	t.Run("every_call", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.WithRecoveryWindow(1, 1*time.Minute, retry.NewConstant(1*time.Second))
+		exp := []time.Duration{1 * time.Minute, 1 * time.Minute, 1 * time.Minute}
+		if got := retry.Simulate(b, 3); !reflect.DeepEqual(got, exp) {
+			t.Errorf("expected %v to be %v", got, exp)
+		}
+	})
+
+	t.Run("panics", func(t *testing.T) {
+		t.Parallel()
+
+		for _, everyN := range []int{0, -1} {
+			func() {
+				defer func() {
+					if r := recover(); r == nil {
+						t.Errorf("everyN %d: expected panic", everyN)
+					}
+				}()
+				retry.WithRecoveryWindow(everyN, 1*time.Minute, retry.NewConstant(1*time.Second))
+			}()
+		}
+	})
+}
+
+func TestWithStopOverride(t *testing.T) {
+	t.Parallel()
+
+	t.Run("force_continue", func(t *testing.T) {
+		t.Parallel()
+
+		var seen []bool
+		b := retry.WithStopOverride(func(next time.Duration, stop bool) (time.Duration, bool) {
+			seen = append(seen, stop)
+			if stop {
+				return 3 * time.Second, false
+			}
+			return next, false
+		}, retry.WithMaxRetries(1, retry.NewConstant(1*time.Second)))
+
+		exp := []time.Duration{1 * time.Second, 3 * time.Second, 3 * time.Second}
+		if got := retry.Simulate(b, 3); !reflect.DeepEqual(got, exp) {
+			t.Errorf("expected %v to be %v", got, exp)
+		}
+		if got, want := seen, []bool{false, true, true}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("force_stop", func(t *testing.T) {
+		t.Parallel()
+
+		var killed atomic.Bool
+		b := retry.WithStopOverride(func(next time.Duration, stop bool) (time.Duration, bool) {
+			if killed.Load() {
+				return 0, true
+			}
+			return next * 2, stop
+		}, retry.NewConstant(1*time.Second))
+
+		if val, stop := b.Next(); stop || val != 2*time.Second {
+			t.Errorf("expected (%v, false), got (%v, %t)", 2*time.Second, val, stop)
+		}
+
+		killed.Store(true)
+		if _, stop := b.Next(); !stop {
+			t.Error("expected stop")
+		}
+	})
+}
+
+func TestJoin(t *testing.T) {
+	t.Parallel()
+
+	b := retry.Join(
+		retry.WithMaxRetries(3, retry.NewConstant(10*time.Millisecond)),
+		retry.WithMaxRetries(4, retry.NewExponential(100*time.Millisecond)),
+	)
+
+	exp := []time.Duration{
+		10 * time.Millisecond,
+		10 * time.Millisecond,
+		10 * time.Millisecond,
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		800 * time.Millisecond,
+	}
+
+	results := make([]time.Duration, 0, len(exp))
+	for range exp {
+		val, stop := b.Next()
+		if stop {
+			t.Fatalf("should not stop")
+		}
+		results = append(results, val)
+	}
+
+	if !reflect.DeepEqual(results, exp) {
+		t.Errorf("expected %v to be %v", results, exp)
+	}
+
+	// Now we stop
+	val, stop := b.Next()
+	if !stop {
+		t.Errorf("should stop")
+	}
+	if val != 0 {
+		t.Errorf("expected %v to be %v", val, 0)
+	}
+}
+
+func ExampleJoin() {
+	ctx := context.Background()
+
+	// Retry quickly three times, then fall back to an exponential backoff.
+	b := retry.Join(
+		retry.WithMaxRetries(3, retry.NewConstant(10*time.Millisecond)),
+		retry.WithMaxRetries(5, retry.NewExponential(100*time.Millisecond)),
+	)
+
+	if err := retry.Do(ctx, b, func(_ context.Context) error {
+		// TODO: logic here
+		return nil
+	}); err != nil {
+		// handle error
+	}
+}
+
+func TestSequence(t *testing.T) {
+	t.Parallel()
+
+	t.Run("phases", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.Sequence(
+			retry.WithMaxRetries(2, retry.NewConstant(10*time.Millisecond)),
+			retry.WithMaxRetries(3, retry.NewExponential(100*time.Millisecond)),
+		)
+
+		got := retry.Simulate(b, 10)
+		exp := []time.Duration{
+			10 * time.Millisecond,
+			10 * time.Millisecond,
+			100 * time.Millisecond,
+			200 * time.Millisecond,
+			400 * time.Millisecond,
+		}
+		if !reflect.DeepEqual(got, exp) {
+			t.Errorf("expected %v to be %v", got, exp)
+		}
+	})
+
+	t.Run("resets_each_phase", func(t *testing.T) {
+		t.Parallel()
+
+		// Both phases were already used, so Join would drain them as-is while
+		// Sequence starts each from the beginning.
+		first := retry.WithMaxRetries(2, retry.NewConstant(10*time.Millisecond))
+		second := retry.WithMaxRetries(2, retry.NewExponential(100*time.Millisecond))
+		retry.Simulate(first, 10)
+		retry.Simulate(second, 1)
+
+		joined := retry.Simulate(retry.Join(first, second), 10)
+		if exp := []time.Duration{200 * time.Millisecond}; !reflect.DeepEqual(joined, exp) {
+			t.Errorf("expected %v to be %v", joined, exp)
+		}
+
+		got := retry.Simulate(retry.Sequence(first, second), 10)
+		exp := []time.Duration{
+			10 * time.Millisecond,
+			10 * time.Millisecond,
+			100 * time.Millisecond,
+			200 * time.Millisecond,
+		}
+		if !reflect.DeepEqual(got, exp) {
+			t.Errorf("expected %v to be %v", got, exp)
+		}
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.Sequence(
+			retry.WithMaxRetries(1, retry.NewConstant(10*time.Millisecond)),
+			retry.WithMaxRetries(1, retry.NewConstant(20*time.Millisecond)),
+		)
+		retry.Simulate(b, 10)
+
+		b.(retry.Resettable).Reset()
+		got := retry.Simulate(b, 10)
+		exp := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond}
+		if !reflect.DeepEqual(got, exp) {
+			t.Errorf("expected %v to be %v", got, exp)
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		t.Parallel()
+
+		if _, stop := retry.Sequence().Next(); !stop {
+			t.Errorf("should stop")
+		}
+	})
+}
+
+func TestWithAutoReset(t *testing.T) {
+	t.Parallel()
+
+	b := retry.WithAutoReset(50*time.Millisecond, retry.NewExponential(1*time.Second))
+
+	// Quick successive calls keep growing.
+	exp := []time.Duration{1 * time.Second, 2 * time.Second, 4 * time.Second}
+	if got := retry.Simulate(b, 3); !reflect.DeepEqual(got, exp) {
+		t.Errorf("expected %v to be %v", got, exp)
+	}
+
+	// After the stable period, the backoff starts over.
+	time.Sleep(100 * time.Millisecond)
+	if got := retry.Simulate(b, 2); !reflect.DeepEqual(got, exp[:2]) {
+		t.Errorf("expected %v to be %v", got, exp[:2])
+	}
+}
+
+func TestWithLogger(t *testing.T) {
+	t.Parallel()
+
+	var lines []string
+	b := retry.WithLogger(func(format string, args ...any) {
+		lines = append(lines, fmt.Sprintf(format, args...))
+	}, retry.WithMaxRetries(1, retry.NewConstant(1*time.Second)))
+
+	retry.Simulate(b, 10)
+
+	exp := []string{
+		"retry: backoff next=1s stop=false",
+		"retry: backoff next=0s stop=true",
+	}
+	if !reflect.DeepEqual(lines, exp) {
+		t.Errorf("expected %q to be %q", lines, exp)
+	}
+}
+
+func ExampleWithLogger() {
+	logf := func(format string, args ...any) {
+		fmt.Println(fmt.Sprintf(format, args...))
+	}
+
+	b := retry.NewExponential(1 * time.Second)
+	b = retry.WithCappedDuration(3*time.Second, b)
+	b = retry.WithMaxRetries(3, b)
+	b = retry.WithLogger(logf, b)
+
+	retry.Simulate(b, 10)
+	// Output:
+	// retry: backoff next=1s stop=false
+	// retry: backoff next=2s stop=false
+	// retry: backoff next=3s stop=false
+	// retry: backoff next=0s stop=true
+}
+
+func TestMaxMin(t *testing.T) {
+	t.Parallel()
+
+	newPair := func() (retry.Backoff, retry.Backoff) {
+		a := retry.WithMaxRetries(2, retry.NewExponential(1*time.Second))
+		b := retry.WithMaxRetries(4, retry.NewConstant(3*time.Second))
+		return a, b
+	}
+
+	cases := []struct {
+		name    string
+		combine func(a, b retry.Backoff) retry.Backoff
+		exp     []time.Duration
+	}{
+		{
+			name:    "max_any",
+			combine: retry.Max,
+			exp:     []time.Duration{3 * time.Second, 3 * time.Second},
+		},
+		{
+			name: "max_all",
+			combine: func(a, b retry.Backoff) retry.Backoff {
+				return retry.MaxMode(retry.StopOnAll, a, b)
+			},
+			exp: []time.Duration{3 * time.Second, 3 * time.Second, 3 * time.Second, 3 * time.Second},
+		},
+		{
+			name:    "min_any",
+			combine: retry.Min,
+			exp:     []time.Duration{1 * time.Second, 2 * time.Second},
+		},
+		{
+			name: "min_all",
+			combine: func(a, b retry.Backoff) retry.Backoff {
+				return retry.MinMode(retry.StopOnAll, a, b)
+			},
+			exp: []time.Duration{1 * time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			b := tc.combine(newPair())
+			if got := retry.Simulate(b, 10); !reflect.DeepEqual(got, tc.exp) {
+				t.Errorf("expected %v to be %v", got, tc.exp)
+			}
+
+			b.(retry.Resettable).Reset()
+			if got := retry.Simulate(b, 10); !reflect.DeepEqual(got, tc.exp) {
+				t.Errorf("after reset: expected %v to be %v", got, tc.exp)
+			}
+		})
+	}
+}
+
+func TestAlternate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("interleaves", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.Alternate(retry.NewExponential(1*time.Second), retry.NewConstant(10*time.Second))
+		exp := []time.Duration{
+			1 * time.Second,
+			10 * time.Second,
+			2 * time.Second,
+			10 * time.Second,
+			4 * time.Second,
+			10 * time.Second,
+		}
+		if got := retry.Simulate(b, len(exp)); !reflect.DeepEqual(got, exp) {
+			t.Errorf("expected %v to be %v", got, exp)
+		}
+	})
+
+	t.Run("stops", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.Alternate(
+			retry.WithMaxRetries(5, retry.NewConstant(1*time.Second)),
+			retry.WithMaxRetries(1, retry.NewConstant(10*time.Second)),
+		)
+		exp := []time.Duration{1 * time.Second, 10 * time.Second, 1 * time.Second}
+		if got := retry.Simulate(b, 10); !reflect.DeepEqual(got, exp) {
+			t.Errorf("expected %v to be %v", got, exp)
+		}
+		if _, stop := b.Next(); !stop {
+			t.Error("expected stop to be sticky")
+		}
+
+		b.(retry.Resettable).Reset()
+		if got := retry.Simulate(b, 10); !reflect.DeepEqual(got, exp) {
+			t.Errorf("after reset: expected %v to be %v", got, exp)
+		}
+	})
+}
+
+func TestWeighted(t *testing.T) {
+	t.Parallel()
+
+	t.Run("distribution", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.WeightedRand(rand.New(rand.NewSource(42)), []retry.WeightedBackoff{
+			{Weight: 3, Backoff: retry.NewConstant(1 * time.Second)},
+			{Weight: 0, Backoff: retry.NewConstant(2 * time.Second)},
+			{Weight: 1, Backoff: retry.NewConstant(3 * time.Second)},
+		})
+
+		counts := make(map[time.Duration]int)
+		for i := 0; i < 10_000; i++ {
+			val, stop := b.Next()
+			if stop {
+				t.Fatal("should not stop")
+			}
+			counts[val]++
+		}
+
+		if got := counts[2*time.Second]; got != 0 {
+			t.Errorf("expected zero-weight backoff to never be selected, got %d", got)
+		}
+		if got, min, max := counts[1*time.Second], 7_000, 8_000; got < min || got > max {
+			t.Errorf("expected %v to be between %v and %v", got, min, max)
+		}
+	})
+
+	t.Run("deterministic", func(t *testing.T) {
+		t.Parallel()
+
+		newBackoff := func() retry.Backoff {
+			return retry.WeightedRand(rand.New(rand.NewSource(7)), []retry.WeightedBackoff{
+				{Weight: 1, Backoff: retry.NewExponential(1 * time.Second)},
+				{Weight: 1, Backoff: retry.NewConstant(1 * time.Minute)},
+			})
+		}
+
+		if got, want := retry.Simulate(newBackoff(), 20), retry.Simulate(newBackoff(), 20); !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("stops", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.Weighted([]retry.WeightedBackoff{
+			{Weight: 1, Backoff: retry.WithMaxRetries(2, retry.NewConstant(1*time.Second))},
+		})
+		if got, want := retry.Simulate(b, 10), []time.Duration{1 * time.Second, 1 * time.Second}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		for _, choices := range [][]retry.WeightedBackoff{
+			nil,
+			{{Weight: 0, Backoff: retry.NewConstant(1 * time.Second)}},
+			{{Weight: -1, Backoff: retry.NewConstant(1 * time.Second)}},
+		} {
+			func() {
+				defer func() {
+					if r := recover(); r == nil {
+						t.Errorf("expected panic for %v", choices)
+					}
+				}()
+				retry.Weighted(choices)
+			}()
+		}
+	})
+}
+
+func TestString(t *testing.T) {
+	t.Parallel()
+
+	mustBackoff := func(b retry.Backoff, err error) retry.Backoff {
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+
+	cases := []struct {
+		name string
+		b    retry.Backoff
+		exp  string
+	}{
+		{
+			name: "chain",
+			b: retry.WithMaxRetries(3,
+				retry.WithCappedDuration(5*time.Second,
+					retry.WithJitter(100*time.Millisecond,
+						retry.NewExponential(50*time.Millisecond)))),
+			exp: "MaxRetries(3, CappedDuration(5s, Jitter(100ms, Exponential(base=50ms))))",
+		},
+		{
+			name: "constant",
+			b:    retry.NewConstant(1 * time.Second),
+			exp:  "Constant(t=1s)",
+		},
+		{
+			name: "multiplier",
+			b:    mustBackoff(retry.NewExponentialBase(1*time.Second, 1.5)),
+			exp:  "Exponential(base=1s, multiplier=1.5)",
+		},
+		{
+			name: "fibonacci",
+			b:    retry.NewFibonacci(1 * time.Second),
+			exp:  "Fibonacci(base=1s)",
+		},
+		{
+			name: "linear",
+			b:    mustBackoff(retry.NewLinear(1 * time.Second)),
+			exp:  "Linear(base=1s)",
+		},
+		{
+			name: "decorrelated_jitter",
+			b:    mustBackoff(retry.NewDecorrelatedJitter(1*time.Second, 1*time.Minute)),
+			exp:  "DecorrelatedJitter(base=1s, cap=1m0s)",
+		},
+		{
+			name: "join",
+			b: retry.Join(
+				retry.NewConstant(1*time.Second),
+				retry.WithMinDuration(1*time.Second, retry.NewFibonacci(1*time.Second)),
+			),
+			exp: "Join(Constant(t=1s), MinDuration(1s, Fibonacci(base=1s)))",
+		},
+		{
+			name: "max_all",
+			b:    retry.MaxMode(retry.StopOnAll, retry.NewConstant(1*time.Second), retry.NewConstant(2*time.Second)),
+			exp:  "Max(StopOnAll, Constant(t=1s), Constant(t=2s))",
+		},
+		{
+			name: "alternate",
+			b:    retry.Alternate(retry.NewConstant(1*time.Second), retry.NewFibonacci(1*time.Second)),
+			exp:  "Alternate(Constant(t=1s), Fibonacci(base=1s))",
+		},
+		{
+			name: "weighted",
+			b: retry.Weighted([]retry.WeightedBackoff{
+				{Weight: 0.75, Backoff: retry.NewConstant(1 * time.Second)},
+				{Weight: 0.25, Backoff: retry.NewFibonacci(1 * time.Second)},
+			}),
+			exp: "Weighted(0.75: Constant(t=1s), 0.25: Fibonacci(base=1s))",
+		},
+		{
+			name: "max_retries_by_error",
+			b: retry.WithMaxRetriesByError(map[error]int{
+				io.ErrUnexpectedEOF: 2,
+				io.EOF:              1,
+			}, 5, retry.NewConstant(1*time.Second)),
+			exp: `MaxRetriesByError("EOF": 1, "unexpected EOF": 2, fallback=5, Constant(t=1s))`,
+		},
+		{
+			name: "backoff_func",
+			b: retry.Sync(retry.BackoffFunc(func() (time.Duration, bool) {
+				return 0, true
+			})),
+			exp: "Sync(retry.BackoffFunc)",
+		},
+		{
+			name: "clone",
+			b:    retry.Clone(retry.WithMaxRetries(1, retry.NewConstant(1*time.Second))),
+			exp:  "MaxRetries(1, Constant(t=1s))",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := fmt.Sprint(tc.b); got != tc.exp {
+				t.Errorf("expected %q to be %q", got, tc.exp)
+			}
+		})
+	}
+}
+
+func TestSimulate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("max_steps", func(t *testing.T) {
+		t.Parallel()
+
+		got := retry.Simulate(retry.NewExponential(1*time.Second), 3)
+		exp := []time.Duration{1 * time.Second, 2 * time.Second, 4 * time.Second}
+		if !reflect.DeepEqual(got, exp) {
+			t.Errorf("expected %v to be %v", got, exp)
+		}
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		t.Parallel()
+
+		got := retry.Simulate(retry.WithMaxRetries(2, retry.NewConstant(1*time.Second)), 10)
+		exp := []time.Duration{1 * time.Second, 1 * time.Second}
+		if !reflect.DeepEqual(got, exp) {
+			t.Errorf("expected %v to be %v", got, exp)
+		}
+	})
+}
+
+func ExampleSimulate() {
+	b := retry.NewFibonacci(1 * time.Second)
+	b = retry.WithCappedDuration(5*time.Second, b)
+	b = retry.WithMaxRetries(6, b)
+
+	fmt.Println(retry.Simulate(b, 10))
+	// Output:
+	// [1s 2s 3s 5s 5s 5s]
+}
+
+func TestSync(t *testing.T) {
+	t.Parallel()
+
+	// This backoff is not safe for concurrent use on its own.
+	var attempt time.Duration
+	b := retry.Sync(retry.BackoffFunc(func() (time.Duration, bool) {
+		attempt++
+		return attempt, false
+	}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				b.Next()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got, want := attempt, time.Duration(10_000); got != want {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+}
+
+func TestClone(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		b    retry.Backoff
+	}{
+		{
+			name: "exponential",
+			b:    retry.NewExponential(1 * time.Second),
+		},
+		{
+			name: "exponential_base",
+			b: func() retry.Backoff {
+				b, _ := retry.NewExponentialBase(1*time.Second, 1.5)
+				return b
+			}(),
+		},
+		{
+			name: "fibonacci",
+			b:    retry.NewFibonacci(1 * time.Second),
+		},
+		{
+			name: "linear",
+			b: func() retry.Backoff {
+				b, _ := retry.NewLinear(1 * time.Second)
+				return b
+			}(),
+		},
+		{
+			name: "chain",
+			b: retry.WithMaxCumulativeDelay(1*time.Hour, retry.WithMaxRetries(5,
+				retry.WithCappedDuration(10*time.Second, retry.WithMinDuration(1*time.Second,
+					retry.NewFibonacci(1*time.Second))))),
+		},
+		{
+			name: "join",
+			b: retry.Join(
+				retry.WithMaxRetries(2, retry.NewConstant(10*time.Millisecond)),
+				retry.WithMaxRetries(3, retry.NewExponential(100*time.Millisecond)),
+			),
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Advance the original so the clone copies its current state.
+			tc.b.Next()
+			tc.b.Next()
+
+			clone := retry.Clone(tc.b)
+			if clone == tc.b {
+				t.Fatalf("expected clone to be a new instance")
+			}
+
+			got := retry.Simulate(clone, 5)
+			exp := retry.Simulate(tc.b, 5)
+			if !reflect.DeepEqual(got, exp) {
+				t.Errorf("expected %v to be %v", got, exp)
+			}
+		})
+	}
+
+	t.Run("independent", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.WithMaxRetries(3, retry.NewExponential(1*time.Second))
+		clone := retry.Clone(b)
+
+		b.Next()
+		b.Next()
+
+		exp := []time.Duration{1 * time.Second, 2 * time.Second, 4 * time.Second}
+		if got := retry.Simulate(clone, 10); !reflect.DeepEqual(got, exp) {
+			t.Errorf("expected %v to be %v", got, exp)
+		}
+	})
+
+	t.Run("backoff_func", func(t *testing.T) {
+		t.Parallel()
+
+		var b retry.Backoff = retry.BackoffFunc(func() (time.Duration, bool) {
+			return 1 * time.Second, false
+		})
+
+		clone := retry.Clone(b)
+		if val, _ := clone.Next(); val != 1*time.Second {
+			t.Errorf("expected %v to be %v", val, 1*time.Second)
+		}
+	})
+}
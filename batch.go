@@ -0,0 +1,185 @@
+package retry
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DoAll runs each function in its own retry loop concurrently and waits for all
+// of them to finish. The returned slice is aligned with fns: the error at index
+// i is the result of retrying fns[i], or nil if it eventually succeeded.
+//
+// Because backoffs are stateful, each function is retried with its own Clone of
+// b so the retry loops do not share state. Canceling the context aborts all
+// in-flight retry loops.
+//
+// Every function runs at once, in its own goroutine. To bound the number that
+// run at a time, for example to spare a downstream a burst of len(fns)
+// requests, use DoAllN.
+func DoAll(ctx context.Context, b Backoff, fns ...RetryFunc) []error {
+	return errorsOf(doAll(ctx, b, len(fns), fns))
+}
+
+// DoAllN is like DoAll, but runs at most n retry loops at a time. The remaining
+// functions wait for a loop to finish before they are first invoked. If the
+// context is canceled, the functions that have not started yet are not invoked,
+// and their errors are the context's error.
+//
+// It panics if n is less than 1.
+func DoAllN(ctx context.Context, b Backoff, n int, fns ...RetryFunc) []error {
+	return errorsOf(DoAllResultsN(ctx, b, n, fns...))
+}
+
+// errorsOf returns the errors of results, in order.
+func errorsOf(results []OpResult) []error {
+	errs := make([]error, len(results))
+	for i, r := range results {
+		errs[i] = r.Err
+	}
+	return errs
+}
+
+// OpResult summarizes the retry loop of a single function run by DoAllResults.
+type OpResult struct {
+	// Index is the position of the function in the arguments to DoAllResults.
+	Index int
+
+	// Err is the result of retrying the function, or nil if it eventually
+	// succeeded.
+	Err error
+
+	// Attempts is the number of times the function was invoked.
+	Attempts int
+
+	// Elapsed is the total time spent in the function's retry loop.
+	Elapsed time.Duration
+}
+
+// DoAllResults is like DoAll, but returns an OpResult for each function with
+// its attempt count and elapsed time in addition to its error. This lets
+// callers tell, for example, a function that failed fast from one that
+// exhausted its retries. The returned slice is aligned with fns, even though
+// the functions run concurrently. Like DoAll, it runs every function at once.
+func DoAllResults(ctx context.Context, b Backoff, fns ...RetryFunc) []OpResult {
+	return doAll(ctx, b, len(fns), fns)
+}
+
+// DoAllResultsN is like DoAllResults, but runs at most n retry loops at a time,
+// like DoAllN.
+//
+// It panics if n is less than 1.
+func DoAllResultsN(ctx context.Context, b Backoff, n int, fns ...RetryFunc) []OpResult {
+	if n < 1 {
+		panic("n must be greater than 0")
+	}
+	return doAll(ctx, b, n, fns)
+}
+
+// doAll retries each of fns with its own Clone of b, using up to workers
+// goroutines.
+func doAll(ctx context.Context, b Backoff, workers int, fns []RetryFunc) []OpResult {
+	results := make([]OpResult, len(fns))
+	if workers > len(fns) {
+		workers = len(fns)
+	}
+
+	indexes := make(chan int, len(fns))
+	for i := range fns {
+		indexes <- i
+	}
+	close(indexes)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range indexes {
+				f := fns[i]
+				_, result, err := DoWithResult(ctx, Clone(b), func(ctx context.Context) (any, error) {
+					return nil, f(ctx)
+				})
+				results[i] = OpResult{
+					Index:    i,
+					Err:      err,
+					Attempts: result.Attempts,
+					Elapsed:  result.TotalElapsed,
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+// DoAsync runs Do in a new goroutine and returns a channel that receives its
+// result, either nil or the final error, exactly once before being closed. This
+// makes it easy to start several retrying operations and select on their
+// results.
+//
+// The channel is buffered, so the goroutine finishes even if the result is
+// never received. It exits once the retry loop returns, which is promptly after
+// ctx is canceled unless f itself ignores ctx. Since b is used by the
+// goroutine, it must not be used elsewhere until the result arrives.
+func DoAsync(ctx context.Context, b Backoff, f RetryFunc) <-chan error {
+	ch := make(chan error, 1)
+	go func() {
+		defer close(ch)
+		ch <- Do(ctx, b, f)
+	}()
+	return ch
+}
+
+// AsyncResult is the result of a retry loop run by DoWithDataAsync.
+type AsyncResult[T any] struct {
+	// Value is the value returned by the function, or the zero value of T if
+	// it never succeeded.
+	Value T
+
+	// Err is the result of retrying the function, or nil if it eventually
+	// succeeded.
+	Err error
+}
+
+// DoWithDataAsync is like DoAsync, but runs DoWithData and sends its value and
+// error as an AsyncResult.
+func DoWithDataAsync[T any](ctx context.Context, b Backoff, f RetryWithDataFunc[T]) <-chan AsyncResult[T] {
+	ch := make(chan AsyncResult[T], 1)
+	go func() {
+		defer close(ch)
+		val, err := DoWithData(ctx, b, f)
+		ch <- AsyncResult[T]{Value: val, Err: err}
+	}()
+	return ch
+}
+
+// DoCancelable is like DoAsync, but also returns a cancel function that stops
+// the retry loop from outside, without a dedicated context. Calling cancel
+// aborts the current sleep, cancels the context passed to f, and ends the loop,
+// after which done receives ErrStopped, unless the loop had already finished.
+// This gives management code a handle on a long-running loop, such as one run
+// with DoForever-style settings. cancel may be called more than once and from
+// any goroutine. If cancel is never called, the goroutine still exits when the
+// loop returns.
+func DoCancelable(ctx context.Context, b Backoff, f RetryFunc) (done <-chan error, cancel func()) {
+	stop := make(chan struct{})
+	var once sync.Once
+
+	ch := make(chan error, 1)
+	go func() {
+		defer close(ch)
+		_, err := DoWithDataStop(ctx, b, stop, func(ctx context.Context) (struct{}, error) {
+			return struct{}{}, f(ctx)
+		})
+		ch <- err
+	}()
+
+	return ch, func() {
+		once.Do(func() {
+			close(stop)
+		})
+	}
+}
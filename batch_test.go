@@ -0,0 +1,388 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestDoAll(t *testing.T) {
+	t.Parallel()
+
+	t.Run("aligned_errors", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.WithMaxRetries(3, retry.NewConstant(1*time.Millisecond))
+
+		var flakyCalls int64
+		errs := retry.DoAll(ctx, b,
+			func(_ context.Context) error {
+				return nil
+			},
+			func(_ context.Context) error {
+				return retry.RetryableError(io.EOF)
+			},
+			func(_ context.Context) error {
+				if atomic.AddInt64(&flakyCalls, 1) < 3 {
+					return retry.RetryableError(fmt.Errorf("flaky"))
+				}
+				return nil
+			},
+			func(_ context.Context) error {
+				return io.ErrUnexpectedEOF
+			},
+		)
+
+		exp := []error{nil, io.EOF, nil, io.ErrUnexpectedEOF}
+		if got, want := len(errs), len(exp); got != want {
+			t.Fatalf("expected %v to be %v", got, want)
+		}
+		for i := range exp {
+			if !errors.Is(errs[i], exp[i]) {
+				t.Errorf("index %d: expected %v to be %v", i, errs[i], exp[i])
+			}
+		}
+	})
+
+	t.Run("independent_backoffs", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.WithMaxRetries(2, retry.NewConstant(1*time.Millisecond))
+
+		var calls int64
+		fn := func(_ context.Context) error {
+			atomic.AddInt64(&calls, 1)
+			return retry.RetryableError(io.EOF)
+		}
+		retry.DoAll(ctx, b, fn, fn, fn)
+
+		// Each function gets its own 1 + 2 attempts
+		if got, want := atomic.LoadInt64(&calls), int64(9); got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("context_canceled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		b := retry.NewConstant(1 * time.Second)
+
+		fn := func(_ context.Context) error {
+			return retry.RetryableError(io.EOF)
+		}
+		for i, err := range retry.DoAll(ctx, b, fn, fn) {
+			if err != context.DeadlineExceeded {
+				t.Errorf("index %d: expected %v to be %v", i, err, context.DeadlineExceeded)
+			}
+		}
+	})
+}
+
+func TestDoAllN(t *testing.T) {
+	t.Parallel()
+
+	t.Run("bounded", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.WithMaxRetries(2, retry.NewConstant(1*time.Millisecond))
+
+		var inFlight, peak, calls int64
+		fn := func(_ context.Context) error {
+			n := atomic.AddInt64(&inFlight, 1)
+			defer atomic.AddInt64(&inFlight, -1)
+			for {
+				p := atomic.LoadInt64(&peak)
+				if n <= p || atomic.CompareAndSwapInt64(&peak, p, n) {
+					break
+				}
+			}
+			atomic.AddInt64(&calls, 1)
+			time.Sleep(5 * time.Millisecond)
+			return retry.RetryableError(io.EOF)
+		}
+
+		errs := retry.DoAllN(ctx, b, 2, fn, fn, fn, fn, fn)
+		if got, want := len(errs), 5; got != want {
+			t.Fatalf("expected %v to be %v", got, want)
+		}
+		for i, err := range errs {
+			if !errors.Is(err, io.EOF) {
+				t.Errorf("index %d: expected %v to be %v", i, err, io.EOF)
+			}
+		}
+
+		if got, want := atomic.LoadInt64(&peak), int64(2); got > want {
+			t.Errorf("expected %v to be at most %v", got, want)
+		}
+		if got, want := atomic.LoadInt64(&calls), int64(15); got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("context_canceled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		b := retry.NewConstant(1 * time.Nanosecond)
+
+		var calls int64
+		fn := func(_ context.Context) error {
+			atomic.AddInt64(&calls, 1)
+			cancel()
+			return retry.RetryableError(io.EOF)
+		}
+		for i, err := range retry.DoAllN(ctx, b, 1, fn, fn, fn) {
+			if err != context.Canceled {
+				t.Errorf("index %d: expected %v to be %v", i, err, context.Canceled)
+			}
+		}
+
+		// Only the first function started before the context was canceled
+		if got, want := atomic.LoadInt64(&calls), int64(1); got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("expected panic")
+			}
+		}()
+		retry.DoAllN(context.Background(), retry.NewConstant(1*time.Second), 0)
+	})
+}
+
+func TestDoAllResults(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	b := retry.WithMaxRetries(3, retry.NewConstant(10*time.Millisecond))
+
+	results := retry.DoAllResults(ctx, b,
+		func(_ context.Context) error {
+			return nil
+		},
+		func(_ context.Context) error {
+			return retry.RetryableError(io.EOF)
+		},
+		func(_ context.Context) error {
+			return io.ErrUnexpectedEOF
+		},
+	)
+
+	exp := []struct {
+		err      error
+		attempts int
+	}{
+		{nil, 1},
+		{io.EOF, 4},
+		{io.ErrUnexpectedEOF, 1},
+	}
+	if got, want := len(results), len(exp); got != want {
+		t.Fatalf("expected %v to be %v", got, want)
+	}
+	for i, r := range results {
+		if got, want := r.Index, i; got != want {
+			t.Errorf("index %d: expected %v to be %v", i, got, want)
+		}
+		if !errors.Is(r.Err, exp[i].err) {
+			t.Errorf("index %d: expected %v to be %v", i, r.Err, exp[i].err)
+		}
+		if got, want := r.Attempts, exp[i].attempts; got != want {
+			t.Errorf("index %d: expected %v to be %v", i, got, want)
+		}
+	}
+
+	// The exhausted function slept between each of its attempts.
+	if got, min := results[1].Elapsed, 30*time.Millisecond; got < min {
+		t.Errorf("expected %v to be at least %v", got, min)
+	}
+}
+
+func TestDoAsync(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		var calls int64
+		ch := retry.DoAsync(context.Background(), retry.NewConstant(1*time.Millisecond), func(_ context.Context) error {
+			if atomic.AddInt64(&calls, 1) < 3 {
+				return retry.RetryableError(io.EOF)
+			}
+			return nil
+		})
+
+		if err := <-ch; err != nil {
+			t.Errorf("expected %v to be nil", err)
+		}
+		if _, ok := <-ch; ok {
+			t.Error("expected channel to be closed")
+		}
+		if got, want := atomic.LoadInt64(&calls), int64(3); got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("select", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		fast := retry.DoAsync(ctx, retry.WithMaxRetries(1, retry.NewConstant(1*time.Millisecond)), func(_ context.Context) error {
+			return retry.RetryableError(io.EOF)
+		})
+		slow := retry.DoAsync(ctx, retry.NewConstant(1*time.Millisecond), func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+		select {
+		case err := <-fast:
+			if !errors.Is(err, io.EOF) {
+				t.Errorf("expected %v to be %v", err, io.EOF)
+			}
+		case <-slow:
+			t.Fatal("expected the slow operation to still be running")
+		}
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		ch := retry.DoAsync(ctx, retry.NewConstant(1*time.Hour), func(_ context.Context) error {
+			return retry.RetryableError(io.EOF)
+		})
+		cancel()
+
+		select {
+		case err := <-ch:
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("expected %v to be %v", err, context.Canceled)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("goroutine did not exit after cancellation")
+		}
+	})
+}
+
+func TestDoWithDataAsync(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		ch := retry.DoWithDataAsync(context.Background(), retry.NewConstant(1*time.Millisecond), func(_ context.Context) (int, error) {
+			return 42, nil
+		})
+
+		res := <-ch
+		if res.Err != nil {
+			t.Errorf("expected %v to be nil", res.Err)
+		}
+		if got, want := res.Value, 42; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if _, ok := <-ch; ok {
+			t.Error("expected channel to be closed")
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.WithMaxRetries(2, retry.NewConstant(1*time.Millisecond))
+		res := <-retry.DoWithDataAsync(context.Background(), b, func(_ context.Context) (int, error) {
+			return 42, retry.RetryableError(io.EOF)
+		})
+		if !errors.Is(res.Err, io.EOF) {
+			t.Errorf("expected %v to be %v", res.Err, io.EOF)
+		}
+		if got, want := res.Value, 0; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+func TestDoCancelable(t *testing.T) {
+	t.Parallel()
+
+	t.Run("cancel_during_sleep", func(t *testing.T) {
+		t.Parallel()
+
+		var calls int64
+		done, cancel := retry.DoCancelable(context.Background(), retry.NewConstant(1*time.Hour), func(_ context.Context) error {
+			atomic.AddInt64(&calls, 1)
+			return retry.RetryableError(io.EOF)
+		})
+
+		// Wait for the first attempt, so the loop is sleeping.
+		for atomic.LoadInt64(&calls) == 0 {
+			time.Sleep(1 * time.Millisecond)
+		}
+		cancel()
+		cancel() // safe to call again
+
+		select {
+		case err := <-done:
+			if !errors.Is(err, retry.ErrStopped) {
+				t.Errorf("expected %v to be %v", err, retry.ErrStopped)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("loop did not stop after cancel")
+		}
+		if _, ok := <-done; ok {
+			t.Error("expected channel to be closed")
+		}
+	})
+
+	t.Run("cancel_during_attempt", func(t *testing.T) {
+		t.Parallel()
+
+		started := make(chan struct{})
+		done, cancel := retry.DoCancelable(context.Background(), retry.NewConstant(1*time.Millisecond), func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+		<-started
+		cancel()
+		if err := <-done; !errors.Is(err, retry.ErrStopped) {
+			t.Errorf("expected %v to be %v", err, retry.ErrStopped)
+		}
+	})
+
+	t.Run("finishes", func(t *testing.T) {
+		t.Parallel()
+
+		done, cancel := retry.DoCancelable(context.Background(), retry.NewConstant(1*time.Millisecond), func(_ context.Context) error {
+			return nil
+		})
+		if err := <-done; err != nil {
+			t.Errorf("expected %v to be nil", err)
+		}
+
+		// Canceling after the loop finished is harmless.
+		cancel()
+	})
+}
@@ -0,0 +1,206 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned by DoWithBreaker, without invoking the function,
+// when the breaker is open.
+var ErrCircuitOpen = errors.New("retry: circuit open")
+
+// BreakerState is the state of a Breaker.
+type BreakerState int
+
+const (
+	// BreakerClosed allows every retry loop to run.
+	BreakerClosed BreakerState = iota
+
+	// BreakerOpen fails every retry loop fast with ErrCircuitOpen.
+	BreakerOpen
+
+	// BreakerHalfOpen allows a single trial retry loop to run. If it succeeds,
+	// the breaker closes; if it gives up, the breaker opens again.
+	BreakerHalfOpen
+)
+
+// String implements fmt.Stringer.
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "Closed"
+	case BreakerOpen:
+		return "Open"
+	case BreakerHalfOpen:
+		return "HalfOpen"
+	default:
+		return "BreakerState(" + strconv.Itoa(int(s)) + ")"
+	}
+}
+
+// Breaker is a circuit breaker shared across many retry loops. During a
+// sustained outage, it stops retry loops from running at all, rather than
+// letting each of them exhaust its backoff. It is safe for concurrent use.
+//
+// The breaker starts closed. After threshold consecutive retry loops give up,
+// it opens, and every retry loop fails fast with ErrCircuitOpen. Once cooldown
+// has elapsed, it becomes half-open and allows a single trial retry loop to
+// run while others continue to fail fast. If the trial succeeds, the breaker
+// closes; if it gives up, the breaker opens for another cooldown.
+type Breaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	l        sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	trial    bool
+}
+
+// NewBreaker creates a new circuit breaker that opens after threshold
+// consecutive give-ups and stays open for cooldown. It panics if threshold is
+// less than 1, or if cooldown is less than zero.
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	if threshold < 1 {
+		panic("threshold must be greater than 0")
+	}
+	if cooldown < 0 {
+		panic("cooldown must be greater than or equal to 0")
+	}
+
+	return &Breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+	}
+}
+
+// State returns the current state of the breaker.
+func (br *Breaker) State() BreakerState {
+	br.l.Lock()
+	defer br.l.Unlock()
+
+	br.advance()
+	return br.state
+}
+
+// advance moves an open breaker to half-open once the cooldown has elapsed.
+// The lock must be held.
+func (br *Breaker) advance() {
+	if br.state == BreakerOpen && time.Since(br.openedAt) >= br.cooldown {
+		br.state = BreakerHalfOpen
+		br.trial = false
+	}
+}
+
+// allow reports whether a retry loop may run, claiming the trial if the
+// breaker is half-open. trial reports whether the loop holds the trial.
+func (br *Breaker) allow() (ok, trial bool) {
+	br.l.Lock()
+	defer br.l.Unlock()
+
+	br.advance()
+	switch br.state {
+	case BreakerClosed:
+		return true, false
+	case BreakerHalfOpen:
+		if br.trial {
+			return false, false
+		}
+		br.trial = true
+		return true, true
+	default:
+		return false, false
+	}
+}
+
+// record records the outcome of a retry loop that allow permitted. Once the
+// breaker has left the closed state, only the outcome of the trial counts; a
+// loop that started while it was closed and ends later is ignored.
+func (br *Breaker) record(success, trial bool) {
+	br.l.Lock()
+	defer br.l.Unlock()
+
+	if br.state != BreakerClosed && !trial {
+		return
+	}
+
+	if success {
+		br.state = BreakerClosed
+		br.failures = 0
+		br.trial = false
+		return
+	}
+
+	if br.state == BreakerHalfOpen {
+		br.open()
+		return
+	}
+
+	br.failures++
+	if br.failures >= br.threshold {
+		br.open()
+	}
+}
+
+// release gives back a claimed trial without recording an outcome.
+func (br *Breaker) release(trial bool) {
+	br.l.Lock()
+	defer br.l.Unlock()
+
+	if trial && br.state == BreakerHalfOpen {
+		br.trial = false
+	}
+}
+
+// open opens the breaker. The lock must be held.
+func (br *Breaker) open() {
+	br.state = BreakerOpen
+	br.openedAt = time.Now()
+	br.failures = 0
+	br.trial = false
+}
+
+// DoWithBreaker is like Do, but first consults br. If br is open, or half-open
+// with a trial already running, it returns ErrCircuitOpen without invoking f.
+// Otherwise, it runs the retry loop and records the outcome in br: success
+// closes the breaker, and giving up after retryable errors, because the
+// backoff stopped or an attempt budget ran out, counts towards opening it.
+//
+// Other outcomes are not recorded. A loop that ends because ctx is done was
+// ended by the caller rather than the service, and a non-retryable,
+// permanent, or validation error usually reflects a bad request rather than an
+// outage, so a burst of client errors cannot open the breaker.
+func DoWithBreaker(ctx context.Context, b Backoff, br *Breaker, f RetryFunc) error {
+	ok, trial := br.allow()
+	if !ok {
+		return ErrCircuitOpen
+	}
+
+	err := Do(ctx, b, f)
+	switch {
+	case err == nil:
+		br.record(true, trial)
+	case ctx.Err() != nil:
+		br.release(trial)
+	case gaveUp(err):
+		br.record(false, trial)
+	default:
+		br.release(trial)
+	}
+	return err
+}
+
+// gaveUp reports whether err, returned by a retry loop, means it gave up after
+// retryable errors rather than on an error it was told not to retry.
+func gaveUp(err error) bool {
+	if IsValidation(err) {
+		return false
+	}
+	return errors.Is(err, ErrBackoffStopped) ||
+		errors.Is(err, ErrBudgetExhausted) ||
+		IsRetryable(err)
+}
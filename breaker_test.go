@@ -0,0 +1,223 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestDoWithBreaker(t *testing.T) {
+	t.Parallel()
+
+	fail := func(_ context.Context) error {
+		return retry.RetryableError(io.EOF)
+	}
+	succeed := func(_ context.Context) error {
+		return nil
+	}
+	newBackoff := func() retry.Backoff {
+		return retry.WithMaxRetries(1, retry.NewConstant(1*time.Nanosecond))
+	}
+
+	t.Run("transitions", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		br := retry.NewBreaker(2, 50*time.Millisecond)
+
+		for i := 0; i < 2; i++ {
+			if got, want := br.State(), retry.BreakerClosed; got != want {
+				t.Fatalf("give-up %d: expected %v to be %v", i, got, want)
+			}
+			if err := retry.DoWithBreaker(ctx, newBackoff(), br, fail); !errors.Is(err, io.EOF) {
+				t.Errorf("expected %#v to be %#v", err, io.EOF)
+			}
+		}
+
+		if got, want := br.State(), retry.BreakerOpen; got != want {
+			t.Fatalf("expected %v to be %v", got, want)
+		}
+
+		var calls int
+		if err := retry.DoWithBreaker(ctx, newBackoff(), br, func(_ context.Context) error {
+			calls++
+			return nil
+		}); !errors.Is(err, retry.ErrCircuitOpen) {
+			t.Errorf("expected %#v to be %#v", err, retry.ErrCircuitOpen)
+		}
+		if calls != 0 {
+			t.Errorf("expected open breaker not to invoke the function")
+		}
+
+		// A failed trial opens the breaker again.
+		time.Sleep(60 * time.Millisecond)
+		if got, want := br.State(), retry.BreakerHalfOpen; got != want {
+			t.Fatalf("expected %v to be %v", got, want)
+		}
+		if err := retry.DoWithBreaker(ctx, newBackoff(), br, fail); !errors.Is(err, io.EOF) {
+			t.Errorf("expected %#v to be %#v", err, io.EOF)
+		}
+		if got, want := br.State(), retry.BreakerOpen; got != want {
+			t.Fatalf("expected %v to be %v", got, want)
+		}
+
+		// A successful trial closes it.
+		time.Sleep(60 * time.Millisecond)
+		if err := retry.DoWithBreaker(ctx, newBackoff(), br, succeed); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := br.State(), retry.BreakerClosed; got != want {
+			t.Fatalf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("success_resets_failures", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		br := retry.NewBreaker(2, 1*time.Minute)
+
+		for i := 0; i < 5; i++ {
+			_ = retry.DoWithBreaker(ctx, newBackoff(), br, fail)
+			if err := retry.DoWithBreaker(ctx, newBackoff(), br, succeed); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if got, want := br.State(), retry.BreakerClosed; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("single_trial", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		br := retry.NewBreaker(1, 0)
+		_ = retry.DoWithBreaker(ctx, newBackoff(), br, fail)
+
+		started := make(chan struct{})
+		finish := make(chan struct{})
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- retry.DoWithBreaker(ctx, newBackoff(), br, func(_ context.Context) error {
+				close(started)
+				<-finish
+				return nil
+			})
+		}()
+		<-started
+
+		if err := retry.DoWithBreaker(ctx, newBackoff(), br, succeed); !errors.Is(err, retry.ErrCircuitOpen) {
+			t.Errorf("expected %#v to be %#v", err, retry.ErrCircuitOpen)
+		}
+
+		close(finish)
+		if err := <-errCh; err != nil {
+			t.Fatal(err)
+		}
+		if got, want := br.State(), retry.BreakerClosed; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("only_trial_counts", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		br := retry.NewBreaker(1, 0)
+
+		// Start a loop while the breaker is closed, and hold it until the
+		// breaker is half-open with a trial running.
+		blocking := func(started, finish chan struct{}, err error) <-chan error {
+			errCh := make(chan error, 1)
+			go func() {
+				errCh <- retry.DoWithBreaker(ctx, newBackoff(), br, func(_ context.Context) error {
+					close(started)
+					<-finish
+					return err
+				})
+			}()
+			<-started
+			return errCh
+		}
+
+		lateFinish := make(chan struct{})
+		lateErr := blocking(make(chan struct{}), lateFinish, nil)
+
+		_ = retry.DoWithBreaker(ctx, newBackoff(), br, fail)
+
+		trialFinish := make(chan struct{})
+		trialErr := blocking(make(chan struct{}), trialFinish, nil)
+
+		// The late loop succeeds, but it is not the trial, so the breaker
+		// stays half-open.
+		close(lateFinish)
+		if err := <-lateErr; err != nil {
+			t.Fatal(err)
+		}
+		if got, want := br.State(), retry.BreakerHalfOpen; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if err := retry.DoWithBreaker(ctx, newBackoff(), br, succeed); !errors.Is(err, retry.ErrCircuitOpen) {
+			t.Errorf("expected %#v to be %#v", err, retry.ErrCircuitOpen)
+		}
+
+		close(trialFinish)
+		if err := <-trialErr; err != nil {
+			t.Fatal(err)
+		}
+		if got, want := br.State(), retry.BreakerClosed; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("context_canceled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		br := retry.NewBreaker(1, 1*time.Minute)
+		if err := retry.DoWithBreaker(ctx, newBackoff(), br, fail); !errors.Is(err, context.Canceled) {
+			t.Errorf("expected %#v to be %#v", err, context.Canceled)
+		}
+		if got, want := br.State(), retry.BreakerClosed; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("client_errors", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		br := retry.NewBreaker(1, 1*time.Minute)
+
+		for _, err := range []error{
+			retry.ValidationError(io.EOF),
+			retry.RetryableError(retry.ValidationError(io.EOF)),
+			retry.PermanentError(io.EOF),
+			io.EOF,
+		} {
+			err := err
+			if got := retry.DoWithBreaker(ctx, newBackoff(), br, func(_ context.Context) error {
+				return err
+			}); !errors.Is(got, io.EOF) {
+				t.Errorf("expected %#v to be %#v", got, io.EOF)
+			}
+			if got, want := br.State(), retry.BreakerClosed; got != want {
+				t.Errorf("%v: expected %v to be %v", err, got, want)
+			}
+		}
+
+		// Giving up after retryable errors still opens it.
+		_ = retry.DoWithBreaker(ctx, newBackoff(), br, fail)
+		if got, want := br.State(), retry.BreakerOpen; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
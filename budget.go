@@ -0,0 +1,131 @@
+package retry
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Budget is a retry budget shared across many retry loops. It limits the ratio
+// of retries to requests over time, which prevents retries from amplifying load
+// on a partially unavailable service. This differs from a Limiter, which caps
+// concurrency rather than volume. Attach it to a context with WithBudget for
+// every retry loop to consult it. It is safe for concurrent use.
+//
+// Each successful request (a retry loop that returns nil) deposits ratio
+// tokens, and minPerSec tokens are deposited every second regardless of
+// traffic so that low-volume callers can still retry. Each retry withdraws one
+// token. If the budget does not have a full token, the retry is skipped. To
+// bound bursts after quiet periods, the balance is capped at 100 requests'
+// worth of ratio tokens plus 10 seconds' worth of minPerSec tokens, but never
+// less than one token.
+type Budget struct {
+	ratio     float64
+	minPerSec float64
+	max       float64
+
+	l       sync.Mutex
+	balance float64
+	last    time.Time
+}
+
+// NewBudget creates a new retry budget that allows roughly ratio retries per
+// request, plus minPerSec retries per second. It panics if ratio or minPerSec
+// is less than zero.
+func NewBudget(ratio float64, minPerSec float64) *Budget {
+	if ratio < 0 {
+		panic("ratio must be greater than or equal to 0")
+	}
+	if minPerSec < 0 {
+		panic("minPerSec must be greater than or equal to 0")
+	}
+
+	max := 100*ratio + 10*minPerSec
+	if max < 1 {
+		max = 1
+	}
+
+	return &Budget{
+		ratio:     ratio,
+		minPerSec: minPerSec,
+		max:       max,
+		balance:   minPerSec,
+		last:      time.Now(),
+	}
+}
+
+// refill deposits the time-based tokens. The lock must be held.
+func (b *Budget) refill() {
+	now := time.Now()
+	b.balance += b.minPerSec * now.Sub(b.last).Seconds()
+	b.last = now
+
+	if b.balance > b.max {
+		b.balance = b.max
+	}
+}
+
+// deposit records a successful request.
+func (b *Budget) deposit() {
+	b.l.Lock()
+	defer b.l.Unlock()
+
+	b.refill()
+	b.balance += b.ratio
+	if b.balance > b.max {
+		b.balance = b.max
+	}
+}
+
+// withdraw attempts to take a token for a retry, reporting whether the retry is
+// allowed.
+func (b *Budget) withdraw() bool {
+	b.l.Lock()
+	defer b.l.Unlock()
+
+	b.refill()
+	if b.balance < 1 {
+		return false
+	}
+	b.balance--
+	return true
+}
+
+// refund returns a token taken by withdraw for a retry that did not happen.
+func (b *Budget) refund() {
+	b.l.Lock()
+	defer b.l.Unlock()
+
+	b.balance++
+	if b.balance > b.max {
+		b.balance = b.max
+	}
+}
+
+type budgetKey struct{}
+
+// WithBudget returns a copy of ctx carrying budget, which every retry loop the
+// context, or a context derived from it, is passed to consults: each retry must
+// withdraw a token from it, and each loop that succeeds deposits tokens into
+// it. If the budget is exhausted, the retry is skipped and the error from the
+// last attempt is returned immediately, as if the backoff had signaled stop.
+// The backoff is not consulted for a skipped retry, so it does not advance.
+//
+// Unlike WithAttemptBudget, which caps the attempts of a single operation, a
+// Budget is meant to outlive requests, so attach the same one to the context of
+// every request to a service.
+func WithBudget(ctx context.Context, budget *Budget) context.Context {
+	return context.WithValue(ctx, budgetKey{}, budget)
+}
+
+// budgetFromContext returns the retry budget carried by ctx, or nil.
+func budgetFromContext(ctx context.Context) *Budget {
+	b, _ := ctx.Value(budgetKey{}).(*Budget)
+	return b
+}
+
+// DoWithBudget is shorthand for Do with a context carrying budget, for call
+// sites that do not otherwise thread one through. See WithBudget.
+func DoWithBudget(ctx context.Context, b Backoff, budget *Budget, f RetryFunc) error {
+	return Do(WithBudget(ctx, budget), b, f)
+}
@@ -0,0 +1,193 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestDoWithBudget(t *testing.T) {
+	t.Parallel()
+
+	t.Run("limits_retry_ratio", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+
+		// Allow one retry for every two successful requests.
+		budget := retry.NewBudget(0.5, 0)
+
+		var calls int
+		for i := 0; i < 4; i++ {
+			if err := retry.DoWithBudget(ctx, retry.NewConstant(1*time.Nanosecond), budget, func(_ context.Context) error {
+				calls++
+				return nil
+			}); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		for i := 0; i < 10; i++ {
+			b := retry.WithMaxRetries(5, retry.NewConstant(1*time.Nanosecond))
+			if err := retry.DoWithBudget(ctx, b, budget, func(_ context.Context) error {
+				calls++
+				return retry.RetryableError(io.EOF)
+			}); !errors.Is(err, io.EOF) {
+				t.Errorf("expected %#v to be %#v", err, io.EOF)
+			}
+		}
+
+		// 4 successes + 10 failed requests + 2 retries
+		if got, want := calls, 16; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("min_per_sec", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		budget := retry.NewBudget(0, 100)
+
+		// Let the time-based tokens accumulate.
+		time.Sleep(50 * time.Millisecond)
+
+		var calls int
+		b := retry.WithMaxRetries(3, retry.NewConstant(1*time.Nanosecond))
+		if err := retry.DoWithBudget(ctx, b, budget, func(_ context.Context) error {
+			calls++
+			return retry.RetryableError(io.EOF)
+		}); !errors.Is(err, io.EOF) {
+			t.Errorf("expected %#v to be %#v", err, io.EOF)
+		}
+
+		if got, want := calls, 4; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("exhausted", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		budget := retry.NewBudget(0, 0)
+
+		var calls int
+		b := retry.WithMaxRetries(3, retry.NewConstant(1*time.Nanosecond))
+		if err := retry.DoWithBudget(ctx, b, budget, func(_ context.Context) error {
+			calls++
+			return retry.RetryableError(io.EOF)
+		}); !errors.Is(err, io.EOF) {
+			t.Errorf("expected %#v to be %#v", err, io.EOF)
+		}
+
+		if got, want := calls, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("failures_do_not_deposit", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		budget := retry.NewBudget(1, 0)
+
+		var calls int
+		for i := 0; i < 3; i++ {
+			b := retry.WithMaxRetries(5, retry.NewConstant(1*time.Nanosecond))
+			_ = retry.DoWithBudget(ctx, b, budget, func(_ context.Context) error {
+				calls++
+				return retry.RetryableError(io.EOF)
+			})
+		}
+
+		if got, want := calls, 3; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("denied_retry_does_not_advance", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		budget := retry.NewBudget(0, 0)
+
+		var nexts int
+		b := retry.BackoffFunc(func() (time.Duration, bool) {
+			nexts++
+			return 1 * time.Nanosecond, false
+		})
+		if err := retry.DoWithBudget(ctx, b, budget, func(_ context.Context) error {
+			return retry.RetryableError(io.EOF)
+		}); !errors.Is(err, io.EOF) {
+			t.Errorf("expected %#v to be %#v", err, io.EOF)
+		}
+
+		if got, want := nexts, 0; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("stopped_backoff_refunds", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		budget := retry.NewBudget(1, 0)
+		if err := retry.DoWithBudget(ctx, retry.NewConstant(1*time.Nanosecond), budget, func(_ context.Context) error {
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		// The backoff stops before the only token is used, so it stays
+		// available for the next call.
+		_ = retry.DoWithBudget(ctx, retry.WithMaxRetries(0, retry.NewConstant(1*time.Nanosecond)), budget, func(_ context.Context) error {
+			return retry.RetryableError(io.EOF)
+		})
+
+		var calls int
+		_ = retry.DoWithBudget(ctx, retry.WithMaxRetries(5, retry.NewConstant(1*time.Nanosecond)), budget, func(_ context.Context) error {
+			calls++
+			return retry.RetryableError(io.EOF)
+		})
+		if got, want := calls, 2; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+func TestWithBudget(t *testing.T) {
+	t.Parallel()
+
+	// Allow one retry for every two successful requests.
+	ctx := retry.WithBudget(context.Background(), retry.NewBudget(0.5, 0))
+
+	var calls int
+	for i := 0; i < 2; i++ {
+		if _, err := retry.DoWithData(ctx, retry.NewConstant(1*time.Nanosecond), func(_ context.Context) (int, error) {
+			calls++
+			return calls, nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		b := retry.WithMaxRetries(5, retry.NewConstant(1*time.Nanosecond))
+		if _, err := retry.DoWithData(ctx, b, func(_ context.Context) (int, error) {
+			calls++
+			return 0, retry.RetryableError(io.EOF)
+		}); !errors.Is(err, io.EOF) {
+			t.Errorf("expected %#v to be %#v", err, io.EOF)
+		}
+	}
+
+	// 2 successes + 3 failed requests + 1 retry
+	if got, want := calls, 6; got != want {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+}
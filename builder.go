@@ -0,0 +1,183 @@
+package retry
+
+import (
+	"fmt"
+	"time"
+)
+
+// Builder assembles a backoff with a fluent API, as an alternative to nesting
+// middleware calls, which read inside-out. Choose one base backoff, then add
+// middleware, which is applied in the order the methods are called:
+//
+//	b, err := NewBuilder().
+//		Exponential(50 * time.Millisecond).
+//		Jitter(100 * time.Millisecond).
+//		CappedDuration(5 * time.Second).
+//		MaxRetries(3).
+//		Build()
+//
+// is equivalent to
+//
+//	WithMaxRetries(3, WithCappedDuration(5*time.Second, WithJitter(100*time.Millisecond, NewExponential(50*time.Millisecond))))
+//
+// Invalid arguments and combinations are reported by Build rather than by the
+// methods, so a chain can be written in one expression. A Builder is not safe
+// for concurrent use.
+type Builder struct {
+	base     Backoff
+	baseName string
+	min      time.Duration
+	cap      time.Duration
+	wrappers []func(Backoff) Backoff
+	seen     map[string]bool
+	err      error
+}
+
+// NewBuilder returns an empty Builder.
+func NewBuilder() *Builder {
+	return &Builder{
+		seen: make(map[string]bool),
+	}
+}
+
+// fail records the first error, so Build reports it.
+func (b *Builder) fail(format string, args ...any) *Builder {
+	if b.err == nil {
+		b.err = fmt.Errorf(format, args...)
+	}
+	return b
+}
+
+// setBase sets the base backoff, rejecting a second one.
+func (b *Builder) setBase(name string, d time.Duration, newBackoff func() Backoff) *Builder {
+	if b.base != nil {
+		return b.fail("cannot use %s: base backoff is already %s", name, b.baseName)
+	}
+	if d <= 0 {
+		return b.fail("%s: base must be greater than 0", name)
+	}
+	b.base, b.baseName, b.min = newBackoff(), name, d
+	return b
+}
+
+// wrap adds middleware, rejecting the same kind twice.
+func (b *Builder) wrap(name string, w func(Backoff) Backoff) *Builder {
+	if b.seen[name] {
+		return b.fail("%s is already set", name)
+	}
+	b.seen[name] = true
+	b.wrappers = append(b.wrappers, w)
+	return b
+}
+
+// Constant uses NewConstant(t) as the base backoff.
+func (b *Builder) Constant(t time.Duration) *Builder {
+	return b.setBase("Constant", t, func() Backoff {
+		return NewConstant(t)
+	})
+}
+
+// Exponential uses NewExponential(base) as the base backoff.
+func (b *Builder) Exponential(base time.Duration) *Builder {
+	return b.setBase("Exponential", base, func() Backoff {
+		return NewExponential(base)
+	})
+}
+
+// Fibonacci uses NewFibonacci(base) as the base backoff.
+func (b *Builder) Fibonacci(base time.Duration) *Builder {
+	return b.setBase("Fibonacci", base, func() Backoff {
+		return NewFibonacci(base)
+	})
+}
+
+// Linear uses NewLinear(base) as the base backoff.
+func (b *Builder) Linear(base time.Duration) *Builder {
+	return b.setBase("Linear", base, func() Backoff {
+		l, _ := NewLinear(base)
+		return l
+	})
+}
+
+// Jitter adds WithJitter(j).
+func (b *Builder) Jitter(j time.Duration) *Builder {
+	if j <= 0 {
+		return b.fail("Jitter: jitter must be greater than 0")
+	}
+	return b.wrap("Jitter", func(next Backoff) Backoff {
+		return WithJitter(j, next)
+	})
+}
+
+// JitterPercent adds WithJitterPercent(j).
+func (b *Builder) JitterPercent(j uint64) *Builder {
+	if j > 100 {
+		return b.fail("JitterPercent: percent must be less than or equal to 100")
+	}
+	return b.wrap("JitterPercent", func(next Backoff) Backoff {
+		return WithJitterPercent(j, next)
+	})
+}
+
+// CappedDuration adds WithCappedDuration(cap). Build returns an error if cap
+// is less than the base.
+func (b *Builder) CappedDuration(cap time.Duration) *Builder {
+	if cap <= 0 {
+		return b.fail("CappedDuration: cap must be greater than 0")
+	}
+	b.cap = cap
+	return b.wrap("CappedDuration", func(next Backoff) Backoff {
+		return WithCappedDuration(cap, next)
+	})
+}
+
+// MinDuration adds WithMinDuration(min).
+func (b *Builder) MinDuration(min time.Duration) *Builder {
+	return b.wrap("MinDuration", func(next Backoff) Backoff {
+		return WithMinDuration(min, next)
+	})
+}
+
+// MaxRetries adds WithMaxRetries(max).
+func (b *Builder) MaxRetries(max uint64) *Builder {
+	return b.wrap("MaxRetries", func(next Backoff) Backoff {
+		return WithMaxRetries(max, next)
+	})
+}
+
+// MaxDuration adds WithMaxDuration(timeout).
+func (b *Builder) MaxDuration(timeout time.Duration) *Builder {
+	if timeout <= 0 {
+		return b.fail("MaxDuration: timeout must be greater than 0")
+	}
+	return b.wrap("MaxDuration", func(next Backoff) Backoff {
+		return WithMaxDuration(timeout, next)
+	})
+}
+
+// Build returns the assembled backoff. It returns the first error recorded by
+// the methods, or an error if no base backoff was chosen, or if a cap is below
+// the base or combined with a constant base, where it would be pointless. Each
+// call returns a new backoff with its own state.
+func (b *Builder) Build() (Backoff, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if b.base == nil {
+		return nil, fmt.Errorf("a base backoff is required")
+	}
+	if b.cap > 0 {
+		if b.baseName == "Constant" {
+			return nil, fmt.Errorf("CappedDuration cannot be combined with a Constant base")
+		}
+		if b.cap < b.min {
+			return nil, fmt.Errorf("cap %s must not be less than the base %s", b.cap, b.min)
+		}
+	}
+
+	out := Clone(b.base)
+	for _, w := range b.wrappers {
+		out = w(out)
+	}
+	return out, nil
+}
@@ -0,0 +1,139 @@
+package retry_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestBuilder(t *testing.T) {
+	t.Parallel()
+
+	mustLinear := func(base time.Duration) retry.Backoff {
+		b, err := retry.NewLinear(base)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+
+	cases := []struct {
+		name    string
+		builder *retry.Builder
+		want    retry.Backoff
+		err     bool
+	}{
+		{
+			name: "call_order",
+			builder: retry.NewBuilder().
+				Exponential(50 * time.Millisecond).
+				Jitter(100 * time.Millisecond).
+				CappedDuration(5 * time.Second).
+				MaxRetries(3),
+			want: retry.WithMaxRetries(3, retry.WithCappedDuration(5*time.Second, retry.WithJitter(100*time.Millisecond, retry.NewExponential(50*time.Millisecond)))),
+		},
+		{
+			name: "limits_first",
+			builder: retry.NewBuilder().
+				Fibonacci(1 * time.Second).
+				MaxDuration(1 * time.Minute).
+				MinDuration(2 * time.Second).
+				JitterPercent(10),
+			want: retry.WithJitterPercent(10, retry.WithMinDuration(2*time.Second, retry.WithMaxDuration(1*time.Minute, retry.NewFibonacci(1*time.Second)))),
+		},
+		{
+			name:    "base_only",
+			builder: retry.NewBuilder().Linear(1 * time.Second),
+			want:    mustLinear(1 * time.Second),
+		},
+		{
+			name:    "constant",
+			builder: retry.NewBuilder().Constant(1 * time.Second).MaxRetries(0),
+			want:    retry.WithMaxRetries(0, retry.NewConstant(1*time.Second)),
+		},
+		{
+			name:    "no_base",
+			builder: retry.NewBuilder().MaxRetries(3),
+			err:     true,
+		},
+		{
+			name:    "two_bases",
+			builder: retry.NewBuilder().Constant(1 * time.Second).Exponential(1 * time.Second),
+			err:     true,
+		},
+		{
+			name:    "zero_base",
+			builder: retry.NewBuilder().Exponential(0),
+			err:     true,
+		},
+		{
+			name:    "duplicate_middleware",
+			builder: retry.NewBuilder().Exponential(1 * time.Second).MaxRetries(3).MaxRetries(5),
+			err:     true,
+		},
+		{
+			name:    "cap_below_base",
+			builder: retry.NewBuilder().Exponential(2 * time.Second).CappedDuration(1 * time.Second),
+			err:     true,
+		},
+		{
+			name:    "cap_on_constant",
+			builder: retry.NewBuilder().Constant(1 * time.Second).CappedDuration(2 * time.Second),
+			err:     true,
+		},
+		{
+			name:    "negative_jitter",
+			builder: retry.NewBuilder().Exponential(1 * time.Second).Jitter(-1),
+			err:     true,
+		},
+		{
+			name:    "zero_jitter",
+			builder: retry.NewBuilder().Exponential(1 * time.Second).Jitter(0),
+			err:     true,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			b, err := tc.builder.Build()
+			if (err != nil) != tc.err {
+				t.Fatalf("expected error to be %t, got %v", tc.err, err)
+			}
+			if err != nil {
+				return
+			}
+
+			if got, want := fmt.Sprint(b), fmt.Sprint(tc.want); got != want {
+				t.Errorf("expected %q to be %q", got, want)
+			}
+		})
+	}
+}
+
+func TestBuilder_independentState(t *testing.T) {
+	t.Parallel()
+
+	builder := retry.NewBuilder().Exponential(1 * time.Second).MaxRetries(3)
+
+	a, err := builder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	retry.Simulate(a, 10)
+
+	b, err := builder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := retry.Simulate(b, 10)
+	if want := []time.Duration{1 * time.Second, 2 * time.Second, 4 * time.Second}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+}
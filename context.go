@@ -0,0 +1,180 @@
+package retry
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+type attemptKey struct{}
+
+// attemptInfo is the per-attempt state stored in the context passed to f.
+type attemptInfo struct {
+	attempt int
+	lastErr error
+}
+
+// attemptCtx carries attemptInfo for a single attempt. It is equivalent to
+// context.WithValue(ctx, attemptKey{}, info), but stores the value inline so
+// that each attempt costs a single allocation.
+type attemptCtx struct {
+	context.Context
+	info attemptInfo
+}
+
+// Value implements context.Context.
+func (c *attemptCtx) Value(key any) any {
+	if key == (attemptKey{}) {
+		return c.info
+	}
+	return c.Context.Value(key)
+}
+
+// withAttempt returns a copy of ctx carrying the 1-based attempt number and the
+// error returned by the previous attempt, if any.
+func withAttempt(ctx context.Context, attempt int, lastErr error) context.Context {
+	return &attemptCtx{
+		Context: ctx,
+		info: attemptInfo{
+			attempt: attempt,
+			lastErr: lastErr,
+		},
+	}
+}
+
+// AttemptFromContext returns the 1-based number of the current attempt from a
+// context passed to a RetryFunc. The first invocation reports 1. It returns 0 if
+// the context did not come from a retry loop.
+func AttemptFromContext(ctx context.Context) int {
+	info, _ := ctx.Value(attemptKey{}).(attemptInfo)
+	return info.attempt
+}
+
+// LastErrorFromContext returns the error returned by the previous attempt from
+// a context passed to a RetryFunc, with the RetryableError marker removed. This
+// lets a function adapt its strategy, for example refreshing credentials after
+// an authorization failure, without keeping state in a closure. It returns nil
+// on the first attempt and if the context did not come from a retry loop.
+func LastErrorFromContext(ctx context.Context) error {
+	info, _ := ctx.Value(attemptKey{}).(attemptInfo)
+	return info.lastErr
+}
+
+type retriesDisabledKey struct{}
+
+// WithRetriesDisabled returns a copy of ctx that disables retries for any retry
+// loop it is passed to: the function is invoked exactly once, and its error is
+// returned regardless of whether it is retryable. This allows a request-scoped
+// opt-out, such as one driven by a header or feature flag, without changing
+// call sites.
+func WithRetriesDisabled(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retriesDisabledKey{}, true)
+}
+
+// DisabledFromContext reports whether retries were disabled for ctx with
+// WithRetriesDisabled.
+func DisabledFromContext(ctx context.Context) bool {
+	disabled, _ := ctx.Value(retriesDisabledKey{}).(bool)
+	return disabled
+}
+
+type requireIdempotentKey struct{}
+
+// RequireIdempotent returns a copy of ctx in which only operations declared
+// idempotent may be retried. A retry loop given the context, or one derived
+// from it, invokes the function once and, instead of retrying a retryable
+// error, returns an error matching ErrNotIdempotent, unless the loop was
+// started with DoIdempotent or DoWithDataIdempotent. This turns an accidental
+// retry of an unsafe operation, such as a payment, into a visible error, and
+// makes every retry in the scope an explicit, reviewable declaration.
+func RequireIdempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, requireIdempotentKey{}, true)
+}
+
+// requiresIdempotent reports whether ctx was marked with RequireIdempotent.
+func requiresIdempotent(ctx context.Context) bool {
+	required, _ := ctx.Value(requireIdempotentKey{}).(bool)
+	return required
+}
+
+type attemptBudgetKey struct{}
+
+// attemptBudget is a pool of attempts shared by every retry loop given a
+// context that carries it.
+type attemptBudget struct {
+	n int64
+}
+
+// take draws one attempt from the budget, reporting whether one was left.
+func (b *attemptBudget) take() bool {
+	for {
+		n := atomic.LoadInt64(&b.n)
+		if n <= 0 {
+			return false
+		}
+		if atomic.CompareAndSwapInt64(&b.n, n, n-1) {
+			return true
+		}
+	}
+}
+
+// remaining returns the number of attempts left in the budget.
+func (b *attemptBudget) remaining() int64 {
+	return atomic.LoadInt64(&b.n)
+}
+
+// WithAttemptBudget returns a copy of ctx carrying a budget of n attempts that
+// is shared by every retry loop the context, or a context derived from it, is
+// passed to. Each invocation of a function by any of those loops draws one
+// attempt, so nested loops, such as an outer retry around a helper that retries
+// internally, draw from the same pool instead of multiplying their attempt
+// counts. Once the budget is spent, the loops stop without sleeping and return
+// an error matching ErrBudgetExhausted. The budget is safe for concurrent use.
+//
+// It panics if n is less than 1.
+func WithAttemptBudget(ctx context.Context, n int) context.Context {
+	if n < 1 {
+		panic("n must be greater than 0")
+	}
+	return context.WithValue(ctx, attemptBudgetKey{}, &attemptBudget{n: int64(n)})
+}
+
+// attemptBudgetFromContext returns the attempt budget carried by ctx, or nil.
+func attemptBudgetFromContext(ctx context.Context) *attemptBudget {
+	b, _ := ctx.Value(attemptBudgetKey{}).(*attemptBudget)
+	return b
+}
+
+// Progress describes a retry loop that is about to sleep before its next
+// attempt, for example to show "retrying... attempt 3 of 5" in a UI.
+type Progress struct {
+	// Attempt is the 1-based number of the attempt that just failed.
+	Attempt int
+
+	// MaxAttempts is the maximum number of attempts the backoff allows,
+	// including the first, if it is known, such as when it is limited with
+	// WithMaxRetries or WithMaxAttempts. It is -1 otherwise.
+	MaxAttempts int
+
+	// NextDelay is how long the loop will sleep before the next attempt.
+	NextDelay time.Duration
+}
+
+type progressKey struct{}
+
+// WithProgress returns a copy of ctx that registers fn to receive the Progress
+// of any retry loop the context, or a context derived from it, is passed to.
+// The loop calls fn right before each sleep, so progress can be reported
+// without threading a callback through every layer. Loops nested inside a
+// retried function report to the same fn. fn must be safe for concurrent use
+// if the context is shared by concurrent loops.
+func WithProgress(ctx context.Context, fn func(Progress)) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// progressFromContext returns the function registered with WithProgress, or
+// nil.
+func progressFromContext(ctx context.Context) func(Progress) {
+	fn, _ := ctx.Value(progressKey{}).(func(Progress))
+	return fn
+}
@@ -0,0 +1,405 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestAttemptFromContext(t *testing.T) {
+	t.Parallel()
+
+	t.Run("outside_retry", func(t *testing.T) {
+		t.Parallel()
+
+		if got, want := retry.AttemptFromContext(context.Background()), 0; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("inside_retry", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.WithMaxRetries(2, retry.NewConstant(1*time.Nanosecond))
+
+		var attempts []int
+		_ = retry.Do(ctx, b, func(ctx context.Context) error {
+			attempts = append(attempts, retry.AttemptFromContext(ctx))
+			return retry.RetryableError(fmt.Errorf("oops"))
+		})
+
+		if got, want := fmt.Sprint(attempts), "[1 2 3]"; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("preserves_parent", func(t *testing.T) {
+		t.Parallel()
+
+		type key struct{}
+		parent, cancel := context.WithCancel(context.WithValue(context.Background(), key{}, "value"))
+		defer cancel()
+
+		_ = retry.Do(parent, retry.NewConstant(1*time.Nanosecond), func(ctx context.Context) error {
+			if got, want := ctx.Value(key{}), "value"; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+
+			// Contexts derived from the attempt are canceled with the parent.
+			child, childCancel := context.WithCancel(ctx)
+			defer childCancel()
+			cancel()
+			<-child.Done()
+			return nil
+		})
+	})
+}
+
+func TestLastErrorFromContext(t *testing.T) {
+	t.Parallel()
+
+	t.Run("outside_retry", func(t *testing.T) {
+		t.Parallel()
+
+		if err := retry.LastErrorFromContext(context.Background()); err != nil {
+			t.Errorf("expected %v to be nil", err)
+		}
+	})
+
+	t.Run("inside_retry", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.WithMaxRetries(2, retry.NewConstant(1*time.Nanosecond))
+
+		errs := []error{errors.New("first"), errors.New("second"), errors.New("third")}
+
+		var got []error
+		_ = retry.Do(ctx, b, func(ctx context.Context) error {
+			got = append(got, retry.LastErrorFromContext(ctx))
+			return retry.RetryableError(errs[len(got)-1])
+		})
+
+		if exp := []error{nil, errs[0], errs[1]}; !reflect.DeepEqual(got, exp) {
+			t.Errorf("expected %v to be %v", got, exp)
+		}
+	})
+}
+
+func TestWithRetriesDisabled(t *testing.T) {
+	t.Parallel()
+
+	t.Run("from_context", func(t *testing.T) {
+		t.Parallel()
+
+		if retry.DisabledFromContext(context.Background()) {
+			t.Errorf("expected retries to be enabled")
+		}
+		if !retry.DisabledFromContext(retry.WithRetriesDisabled(context.Background())) {
+			t.Errorf("expected retries to be disabled")
+		}
+	})
+
+	t.Run("runs_once", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := retry.WithRetriesDisabled(context.Background())
+		b := retry.WithMaxRetries(3, retry.NewConstant(1*time.Nanosecond))
+
+		var calls int
+		err := retry.Do(ctx, b, func(_ context.Context) error {
+			calls++
+			return retry.RetryableError(io.EOF)
+		})
+		if got, want := errors.Unwrap(err), io.EOF; got != want {
+			t.Errorf("expected %#v to be %#v", got, want)
+		}
+		if retry.IsRetryable(err) {
+			t.Errorf("expected %#v not to be retryable", err)
+		}
+		if got, want := calls, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := retry.WithRetriesDisabled(context.Background())
+		if err := retry.Do(ctx, retry.NewConstant(1*time.Nanosecond), func(_ context.Context) error {
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestWithAttemptBudget(t *testing.T) {
+	t.Parallel()
+
+	t.Run("nested", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := retry.WithAttemptBudget(context.Background(), 10)
+		newBackoff := func() retry.Backoff {
+			return retry.WithMaxRetries(9, retry.NewConstant(1*time.Nanosecond))
+		}
+
+		var inner, outer int
+		err := retry.Do(ctx, newBackoff(), func(ctx context.Context) error {
+			outer++
+			return retry.RetryableError(retry.Do(ctx, newBackoff(), func(_ context.Context) error {
+				inner++
+				return retry.RetryableError(io.EOF)
+			}))
+		})
+		if !errors.Is(err, retry.ErrBudgetExhausted) {
+			t.Errorf("expected %v to be %v", err, retry.ErrBudgetExhausted)
+		}
+		if !errors.Is(err, io.EOF) {
+			t.Errorf("expected %v to be %v", err, io.EOF)
+		}
+
+		// Without the budget this would be 100 inner attempts. The first outer
+		// attempt draws 1 and its inner loop the other 9.
+		if got, want := outer+inner, 10; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := outer, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := retry.WithAttemptBudget(context.Background(), 3)
+
+		var calls int
+		if err := retry.Do(ctx, retry.NewConstant(1*time.Nanosecond), func(_ context.Context) error {
+			calls++
+			if calls < 3 {
+				return retry.RetryableError(io.EOF)
+			}
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		// The budget is spent, so another loop does not invoke f at all.
+		err := retry.Do(ctx, retry.NewConstant(1*time.Nanosecond), func(_ context.Context) error {
+			calls++
+			return nil
+		})
+		if !errors.Is(err, retry.ErrBudgetExhausted) {
+			t.Errorf("expected %v to be %v", err, retry.ErrBudgetExhausted)
+		}
+		if got, want := calls, 3; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := retry.WithAttemptBudget(context.Background(), 20)
+
+		var calls int64
+		errs := retry.DoAll(ctx, retry.NewConstant(1*time.Nanosecond),
+			func(_ context.Context) error {
+				atomic.AddInt64(&calls, 1)
+				return retry.RetryableError(io.EOF)
+			},
+			func(_ context.Context) error {
+				atomic.AddInt64(&calls, 1)
+				return retry.RetryableError(io.EOF)
+			},
+			func(_ context.Context) error {
+				atomic.AddInt64(&calls, 1)
+				return retry.RetryableError(io.EOF)
+			},
+		)
+		for _, err := range errs {
+			if !errors.Is(err, retry.ErrBudgetExhausted) {
+				t.Errorf("expected %v to be %v", err, retry.ErrBudgetExhausted)
+			}
+		}
+		if got, want := atomic.LoadInt64(&calls), int64(20); got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("expected panic")
+			}
+		}()
+		retry.WithAttemptBudget(context.Background(), 0)
+	})
+}
+
+func TestRequireIdempotent(t *testing.T) {
+	t.Parallel()
+
+	ctx := retry.RequireIdempotent(context.Background())
+	newBackoff := func() retry.Backoff {
+		return retry.WithMaxRetries(2, retry.NewConstant(1*time.Nanosecond))
+	}
+
+	t.Run("refuses_retry", func(t *testing.T) {
+		t.Parallel()
+
+		var calls int
+		err := retry.Do(ctx, newBackoff(), func(_ context.Context) error {
+			calls++
+			return retry.RetryableError(io.EOF)
+		})
+		if !errors.Is(err, retry.ErrNotIdempotent) {
+			t.Errorf("expected %v to be %v", err, retry.ErrNotIdempotent)
+		}
+		if !errors.Is(err, io.EOF) {
+			t.Errorf("expected %v to be %v", err, io.EOF)
+		}
+		if got, want := calls, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		if err := retry.Do(ctx, newBackoff(), func(_ context.Context) error {
+			return nil
+		}); err != nil {
+			t.Errorf("expected %v to be nil", err)
+		}
+	})
+
+	t.Run("idempotent", func(t *testing.T) {
+		t.Parallel()
+
+		var calls int
+		err := retry.DoIdempotent(ctx, newBackoff(), func(_ context.Context) error {
+			calls++
+			return retry.RetryableError(io.EOF)
+		})
+		if errors.Is(err, retry.ErrNotIdempotent) {
+			t.Errorf("expected %v not to be %v", err, retry.ErrNotIdempotent)
+		}
+		if got, want := calls, 3; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+
+		val, err := retry.DoWithDataIdempotent(ctx, newBackoff(), func(_ context.Context) (int, error) {
+			calls++
+			if calls < 5 {
+				return 0, retry.RetryableError(io.EOF)
+			}
+			return calls, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := val, 5; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("nested", func(t *testing.T) {
+		t.Parallel()
+
+		// The declaration does not extend to loops nested in the function.
+		var calls int
+		err := retry.DoIdempotent(ctx, newBackoff(), func(ctx context.Context) error {
+			return retry.Do(ctx, newBackoff(), func(_ context.Context) error {
+				calls++
+				return retry.RetryableError(io.EOF)
+			})
+		})
+		if !errors.Is(err, retry.ErrNotIdempotent) {
+			t.Errorf("expected %v to be %v", err, retry.ErrNotIdempotent)
+		}
+		if got, want := calls, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+func TestWithProgress(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		b    retry.Backoff
+		max  int
+	}{
+		{
+			name: "max_retries",
+			b:    retry.WithCappedDuration(1*time.Second, retry.WithMaxRetries(2, retry.NewConstant(1*time.Nanosecond))),
+			max:  3,
+		},
+		{
+			name: "max_attempts",
+			b:    retry.WithMaxAttempts(3, retry.NewConstant(1*time.Nanosecond)),
+			max:  3,
+		},
+		{
+			name: "nested_limits",
+			b:    retry.WithMaxRetries(5, retry.WithMaxRetries(2, retry.NewConstant(1*time.Nanosecond))),
+			max:  3,
+		},
+		{
+			name: "grace",
+			b:    retry.WithGrace(1, 1*time.Nanosecond, retry.WithMaxRetries(1, retry.NewConstant(1*time.Nanosecond))),
+			max:  3,
+		},
+		{
+			name: "unknown",
+			b: retry.BackoffFunc(func() (time.Duration, bool) {
+				return 1 * time.Nanosecond, false
+			}),
+			max: -1,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got []retry.Progress
+			ctx := retry.WithProgress(context.Background(), func(p retry.Progress) {
+				got = append(got, p)
+			})
+
+			var calls int
+			_ = retry.Do(ctx, tc.b, func(_ context.Context) error {
+				calls++
+				if calls == 3 {
+					return nil
+				}
+				return retry.RetryableError(io.EOF)
+			})
+
+			want := []retry.Progress{
+				{Attempt: 1, MaxAttempts: tc.max, NextDelay: 1 * time.Nanosecond},
+				{Attempt: 2, MaxAttempts: tc.max, NextDelay: 1 * time.Nanosecond},
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("expected %+v to be %+v", got, want)
+			}
+		})
+	}
+}
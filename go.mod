@@ -1,6 +1,6 @@
 module github.com/sethvargo/go-retry
 
-go 1.16
+go 1.20
 
 // Something weird happened with the v0.2.0 tag where the commit in the module
 // registry doesn't match the commit on GitHub.
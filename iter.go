@@ -0,0 +1,78 @@
+//go:build go1.23
+
+package retry
+
+import (
+	"context"
+	"iter"
+	"time"
+)
+
+// Attempts returns an iterator over the 1-based attempt numbers of a retry
+// loop, for use with range-over-func as an imperative alternative to the
+// callback style:
+//
+//	var err error
+//	for attempt := range retry.Attempts(ctx, b) {
+//		if err = doSomething(); err == nil {
+//			break
+//		}
+//	}
+//
+// The first attempt is yielded immediately. Each time the loop body finishes
+// without breaking, Attempts consults the backoff and sleeps before yielding
+// the next attempt. Iteration ends when the backoff signals stop or the context
+// is canceled. Since the iterator does not see the body's result, breaking out
+// on success is up to the caller. To detect exhaustion after the loop, keep
+// track of the last result: if the loop ended without a success, check
+// ctx.Err() to distinguish cancellation from the backoff stopping. If b is nil,
+// the sequence is empty.
+func Attempts(ctx context.Context, b Backoff) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		if b == nil {
+			return
+		}
+
+		var t *time.Timer
+		defer func() {
+			if t != nil {
+				t.Stop()
+			}
+		}()
+
+		for attempt := 1; ; attempt++ {
+			if ctx.Err() != nil {
+				return
+			}
+
+			if !yield(attempt) {
+				return
+			}
+
+			next, stop := b.Next()
+			if stop {
+				return
+			}
+
+			if ctx.Err() != nil {
+				return
+			}
+
+			if next <= 0 {
+				continue
+			}
+
+			if t == nil {
+				t = time.NewTimer(next)
+			} else {
+				t.Reset(next)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+			}
+		}
+	}
+}
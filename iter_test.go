@@ -0,0 +1,110 @@
+//go:build go1.23
+
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestAttempts(t *testing.T) {
+	t.Parallel()
+
+	t.Run("break_on_success", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.NewConstant(1 * time.Nanosecond)
+
+		var attempts []int
+		for attempt := range retry.Attempts(ctx, b) {
+			attempts = append(attempts, attempt)
+			if attempt == 3 {
+				break
+			}
+		}
+
+		if got, want := attempts, []int{1, 2, 3}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("exhausted", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.WithMaxRetries(2, retry.NewConstant(1*time.Nanosecond))
+
+		var err error
+		var n int
+		for range retry.Attempts(ctx, b) {
+			n++
+			err = errors.New("oops")
+		}
+
+		if err == nil {
+			t.Errorf("expected an error")
+		}
+		if got, want := n, 3; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("context_canceled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		b := retry.NewConstant(1 * time.Hour)
+
+		var n int
+		for range retry.Attempts(ctx, b) {
+			n++
+			cancel()
+		}
+
+		if got, want := n, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := ctx.Err(), context.Canceled; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("nil_backoff", func(t *testing.T) {
+		t.Parallel()
+
+		var n int
+		for range retry.Attempts(context.Background(), nil) {
+			n++
+		}
+
+		if got, want := n, 0; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+func ExampleAttempts() {
+	ctx := context.Background()
+	b := retry.WithMaxRetries(3, retry.NewFibonacci(1*time.Second))
+
+	var err error
+	for range retry.Attempts(ctx, b) {
+		if err = func() error {
+			// TODO: logic here
+			return nil
+		}(); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		// handle error
+	}
+}
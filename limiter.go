@@ -0,0 +1,69 @@
+package retry
+
+import (
+	"context"
+)
+
+// Limiter caps the number of retry attempts that may be in flight at the same
+// time across many retry loops. It is useful for smoothing the recovery of a
+// downstream service after an outage, when many goroutines would otherwise
+// retry simultaneously. It is safe for concurrent use.
+type Limiter struct {
+	slots chan struct{}
+}
+
+// NewLimiter creates a new Limiter that allows at most maxInFlight retry
+// attempts to run concurrently. It panics if maxInFlight is less than or equal
+// to zero.
+func NewLimiter(maxInFlight int) *Limiter {
+	if maxInFlight <= 0 {
+		panic("maxInFlight must be greater than 0")
+	}
+
+	return &Limiter{
+		slots: make(chan struct{}, maxInFlight),
+	}
+}
+
+// acquire blocks until a slot is available or the context is done.
+func (l *Limiter) acquire(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case l.slots <- struct{}{}:
+		return nil
+	}
+}
+
+// release returns a slot acquired with acquire.
+func (l *Limiter) release() {
+	<-l.slots
+}
+
+// DoWithLimiter is like Do, but each retry must acquire a slot from l before f
+// is invoked again, and releases it once f returns. The first attempt is not
+// limited. If the context is canceled while waiting for a slot, the context's
+// error is returned.
+func DoWithLimiter(ctx context.Context, b Backoff, l *Limiter, f RetryFunc) error {
+	var attempt int
+
+	return Do(ctx, b, func(ctx context.Context) error {
+		attempt++
+		if attempt == 1 {
+			return f(ctx)
+		}
+
+		if err := l.acquire(ctx); err != nil {
+			return err
+		}
+		defer l.release()
+
+		return f(ctx)
+	})
+}
@@ -0,0 +1,100 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestDoWithLimiter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("caps_in_flight_retries", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		l := retry.NewLimiter(2)
+
+		var inFlight, maxInFlight int64
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				var attempt int
+				b := retry.WithMaxRetries(3, retry.NewConstant(1*time.Millisecond))
+				if err := retry.DoWithLimiter(ctx, b, l, func(_ context.Context) error {
+					attempt++
+					if attempt == 1 {
+						return retry.RetryableError(fmt.Errorf("oops"))
+					}
+
+					n := atomic.AddInt64(&inFlight, 1)
+					defer atomic.AddInt64(&inFlight, -1)
+					for {
+						max := atomic.LoadInt64(&maxInFlight)
+						if n <= max || atomic.CompareAndSwapInt64(&maxInFlight, max, n) {
+							break
+						}
+					}
+
+					time.Sleep(5 * time.Millisecond)
+					return nil
+				}); err != nil {
+					t.Error(err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		if got, max := atomic.LoadInt64(&maxInFlight), int64(2); got > max {
+			t.Errorf("expected %v to be at most %v", got, max)
+		}
+	})
+
+	t.Run("context_canceled_waiting", func(t *testing.T) {
+		t.Parallel()
+
+		l := retry.NewLimiter(1)
+
+		// Occupy the only slot
+		blockCtx, unblock := context.WithCancel(context.Background())
+		defer unblock()
+		started := make(chan struct{})
+		go func() {
+			var attempt int
+			_ = retry.DoWithLimiter(context.Background(), retry.NewConstant(1*time.Nanosecond), l, func(_ context.Context) error {
+				attempt++
+				if attempt == 1 {
+					return retry.RetryableError(fmt.Errorf("oops"))
+				}
+				close(started)
+				<-blockCtx.Done()
+				return nil
+			})
+		}()
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		var calls int
+		err := retry.DoWithLimiter(ctx, retry.NewConstant(1*time.Nanosecond), l, func(_ context.Context) error {
+			calls++
+			return retry.RetryableError(fmt.Errorf("oops"))
+		})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected %v to be %v", err, context.DeadlineExceeded)
+		}
+		if got, want := calls, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
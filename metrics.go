@@ -0,0 +1,65 @@
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+// Metrics receives counters for the transitions of a retry loop. It is an
+// interface so that callers can wire it to Prometheus, statsd, or a test spy.
+// Implementations must be safe for concurrent use if they are shared across
+// retry loops.
+type Metrics interface {
+	// IncAttempt is called each time the function is invoked.
+	IncAttempt()
+
+	// IncRetry is called each time a retryable error is observed and the
+	// backoff allows another attempt.
+	IncRetry()
+
+	// IncSuccess is called when the retry loop returns without an error.
+	IncSuccess()
+
+	// IncGiveUp is called when the retry loop returns with an error, including
+	// non-retryable errors, a stopped backoff, and a canceled context.
+	IncGiveUp()
+}
+
+// NopMetrics is a Metrics that does nothing.
+var NopMetrics Metrics = nopMetrics{}
+
+type nopMetrics struct{}
+
+func (nopMetrics) IncAttempt() {}
+func (nopMetrics) IncRetry()   {}
+func (nopMetrics) IncSuccess() {}
+func (nopMetrics) IncGiveUp()  {}
+
+// DoWithMetrics is like Do, but reports each transition of the retry loop to m.
+// If m is nil, NopMetrics is used.
+func DoWithMetrics(ctx context.Context, b Backoff, f RetryFunc, m Metrics) error {
+	if b == nil {
+		return ErrNilBackoff
+	}
+	if m == nil {
+		m = NopMetrics
+	}
+
+	err := Do(ctx, withNext(b, func() (time.Duration, bool) {
+		next, stop := b.Next()
+		if !stop {
+			m.IncRetry()
+		}
+		return next, stop
+	}), func(ctx context.Context) error {
+		m.IncAttempt()
+		return f(ctx)
+	})
+	if err != nil {
+		m.IncGiveUp()
+		return err
+	}
+
+	m.IncSuccess()
+	return nil
+}
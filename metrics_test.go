@@ -0,0 +1,97 @@
+package retry_test
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+type testMetrics struct {
+	attempts, retries, successes, giveUps int64
+}
+
+func (m *testMetrics) IncAttempt() { atomic.AddInt64(&m.attempts, 1) }
+func (m *testMetrics) IncRetry()   { atomic.AddInt64(&m.retries, 1) }
+func (m *testMetrics) IncSuccess() { atomic.AddInt64(&m.successes, 1) }
+func (m *testMetrics) IncGiveUp()  { atomic.AddInt64(&m.giveUps, 1) }
+
+func TestDoWithMetrics(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		f    func(attempt int) error
+		want testMetrics
+	}{
+		{
+			name: "success",
+			f: func(attempt int) error {
+				return nil
+			},
+			want: testMetrics{attempts: 1, successes: 1},
+		},
+		{
+			name: "success_after_retries",
+			f: func(attempt int) error {
+				if attempt < 3 {
+					return retry.RetryableError(fmt.Errorf("oops"))
+				}
+				return nil
+			},
+			want: testMetrics{attempts: 3, retries: 2, successes: 1},
+		},
+		{
+			name: "non_retryable",
+			f: func(attempt int) error {
+				return fmt.Errorf("oops")
+			},
+			want: testMetrics{attempts: 1, giveUps: 1},
+		},
+		{
+			name: "exhausted",
+			f: func(attempt int) error {
+				return retry.RetryableError(fmt.Errorf("oops"))
+			},
+			want: testMetrics{attempts: 3, retries: 2, giveUps: 1},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			b := retry.WithMaxRetries(2, retry.NewConstant(1*time.Nanosecond))
+
+			var m testMetrics
+			var attempt int
+			_ = retry.DoWithMetrics(ctx, b, func(_ context.Context) error {
+				attempt++
+				return tc.f(attempt)
+			}, &m)
+
+			if got, want := m, tc.want; got != want {
+				t.Errorf("expected %+v to be %+v", got, want)
+			}
+		})
+	}
+
+	t.Run("nil_metrics", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.NewConstant(1 * time.Nanosecond)
+
+		if err := retry.DoWithMetrics(ctx, b, func(_ context.Context) error {
+			return nil
+		}, nil); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
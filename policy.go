@@ -0,0 +1,166 @@
+package retry
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Policy describes a backoff in a form that can be loaded from configuration,
+// so retry behavior can be tuned without recompiling. Build assembles the
+// backoff it describes.
+//
+// In JSON, durations are strings accepted by time.ParseDuration, such as "1s":
+//
+//	{"type": "exponential", "base": "100ms", "cap": "10s", "maxRetries": 5, "jitterPercent": 10}
+type Policy struct {
+	// Type is the kind of backoff: "constant", "exponential", or "fibonacci".
+	Type string
+
+	// Base is the interval of a constant backoff, or the base of an
+	// exponential or Fibonacci one. It must be greater than 0.
+	Base time.Duration
+
+	// Cap, if greater than 0, caps each delay with WithCappedDuration. It is
+	// only valid for growing backoffs, and must not be less than Base.
+	Cap time.Duration
+
+	// MaxRetries, if not nil, limits the number of retries with
+	// WithMaxRetries. A nil MaxRetries means no limit, while 0 means no
+	// retries.
+	MaxRetries *uint64
+
+	// Jitter, if greater than 0, adds jitter of +/- Jitter with WithJitter.
+	Jitter time.Duration
+
+	// JitterPercent, if greater than 0, adds jitter of +/- JitterPercent
+	// percent with WithJitterPercent. It cannot be combined with Jitter.
+	JitterPercent uint64
+}
+
+// policyJSON is the JSON form of a Policy.
+type policyJSON struct {
+	Type          string  `json:"type"`
+	Base          string  `json:"base"`
+	Cap           string  `json:"cap,omitempty"`
+	MaxRetries    *uint64 `json:"maxRetries,omitempty"`
+	Jitter        string  `json:"jitter,omitempty"`
+	JitterPercent uint64  `json:"jitterPercent,omitempty"`
+}
+
+// Build returns the backoff described by the policy: the base backoff, capped,
+// then jittered, then limited. It returns an error if the policy is invalid,
+// for example if the type is unknown, the base is missing, a cap is set on a
+// constant backoff, or both kinds of jitter are set.
+func (p Policy) Build() (Backoff, error) {
+	if p.Base <= 0 {
+		return nil, fmt.Errorf("base must be greater than 0")
+	}
+	if p.Cap < 0 {
+		return nil, fmt.Errorf("cap must be greater than or equal to 0")
+	}
+	if p.Jitter < 0 {
+		return nil, fmt.Errorf("jitter must be greater than or equal to 0")
+	}
+	if p.Jitter > 0 && p.JitterPercent > 0 {
+		return nil, fmt.Errorf("jitter and jitterPercent cannot both be set")
+	}
+	if p.JitterPercent > 100 {
+		return nil, fmt.Errorf("jitterPercent must be less than or equal to 100")
+	}
+
+	var b Backoff
+	switch p.Type {
+	case "constant":
+		if p.Cap > 0 {
+			return nil, fmt.Errorf("cap is only valid for exponential and fibonacci backoffs")
+		}
+		b = NewConstant(p.Base)
+	case "exponential":
+		b = NewExponential(p.Base)
+	case "fibonacci":
+		b = NewFibonacci(p.Base)
+	default:
+		return nil, fmt.Errorf("unknown backoff type %q", p.Type)
+	}
+
+	if p.Cap > 0 {
+		var err error
+		if b, err = WithCappedDurationStrict(p.Cap, p.Base, b); err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case p.Jitter > 0:
+		b = WithJitter(p.Jitter, b)
+	case p.JitterPercent > 0:
+		b = WithJitterPercent(p.JitterPercent, b)
+	}
+
+	if p.MaxRetries != nil {
+		b = WithMaxRetries(*p.MaxRetries, b)
+	}
+
+	return b, nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (p Policy) MarshalJSON() ([]byte, error) {
+	j := policyJSON{
+		Type:          p.Type,
+		Base:          p.Base.String(),
+		MaxRetries:    p.MaxRetries,
+		JitterPercent: p.JitterPercent,
+	}
+	if p.Cap != 0 {
+		j.Cap = p.Cap.String()
+	}
+	if p.Jitter != 0 {
+		j.Jitter = p.Jitter.String()
+	}
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It only decodes the policy; call
+// Build to validate it.
+func (p *Policy) UnmarshalJSON(data []byte) error {
+	var j policyJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+
+	parse := func(name, s string) (time.Duration, error) {
+		if s == "" {
+			return 0, nil
+		}
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s: %w", name, err)
+		}
+		return d, nil
+	}
+
+	base, err := parse("base", j.Base)
+	if err != nil {
+		return err
+	}
+	cap, err := parse("cap", j.Cap)
+	if err != nil {
+		return err
+	}
+	jitter, err := parse("jitter", j.Jitter)
+	if err != nil {
+		return err
+	}
+
+	*p = Policy{
+		Type:          j.Type,
+		Base:          base,
+		Cap:           cap,
+		MaxRetries:    j.MaxRetries,
+		Jitter:        jitter,
+		JitterPercent: j.JitterPercent,
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package retry_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestPolicy_Build(t *testing.T) {
+	t.Parallel()
+
+	five := uint64(5)
+
+	cases := []struct {
+		name   string
+		policy retry.Policy
+		want   string
+		err    bool
+	}{
+		{
+			name:   "constant",
+			policy: retry.Policy{Type: "constant", Base: 1 * time.Second},
+			want:   "Constant(t=1s)",
+		},
+		{
+			name:   "exponential_chain",
+			policy: retry.Policy{Type: "exponential", Base: 100 * time.Millisecond, Cap: 10 * time.Second, MaxRetries: &five, JitterPercent: 10},
+			want:   "MaxRetries(5, JitterPercent(10, CappedDuration(10s, Exponential(base=100ms))))",
+		},
+		{
+			name:   "fibonacci_jitter",
+			policy: retry.Policy{Type: "fibonacci", Base: 1 * time.Second, Jitter: 50 * time.Millisecond},
+			want:   "Jitter(50ms, Fibonacci(base=1s))",
+		},
+		{
+			name:   "unknown_type",
+			policy: retry.Policy{Type: "linear", Base: 1 * time.Second},
+			err:    true,
+		},
+		{
+			name:   "missing_base",
+			policy: retry.Policy{Type: "constant"},
+			err:    true,
+		},
+		{
+			name:   "cap_without_growth",
+			policy: retry.Policy{Type: "constant", Base: 1 * time.Second, Cap: 2 * time.Second},
+			err:    true,
+		},
+		{
+			name:   "cap_below_base",
+			policy: retry.Policy{Type: "exponential", Base: 2 * time.Second, Cap: 1 * time.Second},
+			err:    true,
+		},
+		{
+			name:   "both_jitters",
+			policy: retry.Policy{Type: "exponential", Base: 1 * time.Second, Jitter: 1 * time.Millisecond, JitterPercent: 5},
+			err:    true,
+		},
+		{
+			name:   "jitter_percent_too_large",
+			policy: retry.Policy{Type: "exponential", Base: 1 * time.Second, JitterPercent: 101},
+			err:    true,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			b, err := tc.policy.Build()
+			if (err != nil) != tc.err {
+				t.Fatalf("expected error to be %t, got %v", tc.err, err)
+			}
+			if tc.err {
+				return
+			}
+			if got, want := fmt.Sprint(b), tc.want; got != want {
+				t.Errorf("expected %q to be %q", got, want)
+			}
+		})
+	}
+}
+
+func TestPolicy_JSON(t *testing.T) {
+	t.Parallel()
+
+	t.Run("round_trip", func(t *testing.T) {
+		t.Parallel()
+
+		config := `{"type": "exponential", "base": "100ms", "cap": "2s", "maxRetries": 4}`
+
+		var p retry.Policy
+		if err := json.Unmarshal([]byte(config), &p); err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := json.Marshal(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var p2 retry.Policy
+		if err := json.Unmarshal(data, &p2); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(p, p2) {
+			t.Errorf("expected %+v to be %+v", p2, p)
+		}
+
+		b, err := p2.Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := retry.Simulate(b, 10)
+		want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("zero_retries", func(t *testing.T) {
+		t.Parallel()
+
+		// An explicit 0 is kept, and means no retries rather than no limit.
+		var p retry.Policy
+		if err := json.Unmarshal([]byte(`{"type": "constant", "base": "1s", "maxRetries": 0}`), &p); err != nil {
+			t.Fatal(err)
+		}
+		if p.MaxRetries == nil || *p.MaxRetries != 0 {
+			t.Fatalf("expected maxRetries to be 0, got %v", p.MaxRetries)
+		}
+	})
+
+	t.Run("invalid_duration", func(t *testing.T) {
+		t.Parallel()
+
+		var p retry.Policy
+		if err := json.Unmarshal([]byte(`{"type": "constant", "base": "soon"}`), &p); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
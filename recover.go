@@ -0,0 +1,60 @@
+package retry
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is the error produced by DoRecover when the function panics. It
+// carries the recovered value and the stack of the goroutine at the time of the
+// panic.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value any
+
+	// Stack is the formatted stack trace captured when the panic was
+	// recovered.
+	Stack []byte
+}
+
+// Error returns the error string, including the recovered value.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("retry: recovered panic: %v", e.Value)
+}
+
+// DoRecover is like Do, but recovers panics inside f, converts them into a
+// retryable *PanicError wrapping the recovered value, and retries them per the
+// backoff. Errors returned by f behave as they do with Do.
+//
+// Recovering panics is strictly opt-in because it is dangerous: a panic can
+// leave shared state corrupted or locks held, and retrying may then misbehave
+// in ways that are harder to debug than the original crash. Only use DoRecover
+// for code known to panic intermittently and safely. To avoid hiding the
+// failure entirely, if the retry loop gives up for any reason, such as the
+// backoff stopping or ctx being canceled, while the last attempt had panicked,
+// DoRecover panics again with the *PanicError, whose Stack holds the original
+// stack trace.
+func DoRecover(ctx context.Context, b Backoff, f RetryFunc) error {
+	var last *PanicError
+
+	err := Do(ctx, b, func(ctx context.Context) (err error) {
+		last = nil
+		defer func() {
+			if r := recover(); r != nil {
+				last = &PanicError{
+					Value: r,
+					Stack: debug.Stack(),
+				}
+				err = RetryableError(last)
+			}
+		}()
+
+		return f(ctx)
+	})
+
+	if err != nil && last != nil {
+		panic(last)
+	}
+	return err
+}
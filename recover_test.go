@@ -0,0 +1,130 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestDoRecover(t *testing.T) {
+	t.Parallel()
+
+	t.Run("retries_panics", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.WithMaxRetries(3, retry.NewConstant(1*time.Nanosecond))
+
+		var i int
+		var lastErr error
+		if err := retry.DoRecover(ctx, b, func(ctx context.Context) error {
+			i++
+			lastErr = retry.LastErrorFromContext(ctx)
+			if i < 3 {
+				panic("boom")
+			}
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		if got, want := i, 3; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+
+		var perr *retry.PanicError
+		if !errors.As(lastErr, &perr) {
+			t.Fatalf("expected %#v to be a PanicError", lastErr)
+		}
+		if got, want := perr.Value, "boom"; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := perr.Error(), "retry: recovered panic: boom"; got != want {
+			t.Errorf("expected %q to be %q", got, want)
+		}
+		if len(perr.Stack) == 0 {
+			t.Errorf("expected a stack trace")
+		}
+	})
+
+	t.Run("errors_unchanged", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.NewConstant(1 * time.Nanosecond)
+
+		err := retry.DoRecover(ctx, b, func(_ context.Context) error {
+			return errors.New("oops")
+		})
+		if got, want := err.Error(), "oops"; got != want {
+			t.Errorf("expected %q to be %q", got, want)
+		}
+	})
+
+	t.Run("repanics_on_give_up", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.WithMaxRetries(1, retry.NewConstant(1*time.Nanosecond))
+
+		defer func() {
+			r := recover()
+			perr, ok := r.(*retry.PanicError)
+			if !ok {
+				t.Fatalf("expected %#v to be a PanicError", r)
+			}
+			if !strings.Contains(string(perr.Stack), "recover_test.go") {
+				t.Errorf("expected stack to include the panicking function")
+			}
+		}()
+
+		_ = retry.DoRecover(ctx, b, func(_ context.Context) error {
+			panic("boom")
+		})
+		t.Errorf("expected to panic")
+	})
+
+	t.Run("repanics_on_cancel", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		b := retry.NewConstant(1 * time.Nanosecond)
+
+		defer func() {
+			if _, ok := recover().(*retry.PanicError); !ok {
+				t.Errorf("expected to panic with a PanicError")
+			}
+		}()
+
+		_ = retry.DoRecover(ctx, b, func(_ context.Context) error {
+			cancel()
+			panic("boom")
+		})
+		t.Errorf("expected to panic")
+	})
+
+	t.Run("error_after_panic", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.NewConstant(1 * time.Nanosecond)
+
+		// Only a panic in the last attempt is raised again.
+		var calls int
+		err := retry.DoRecover(ctx, b, func(_ context.Context) error {
+			calls++
+			if calls == 1 {
+				panic("boom")
+			}
+			return errors.New("oops")
+		})
+		if got, want := err.Error(), "oops"; got != want {
+			t.Errorf("expected %q to be %q", got, want)
+		}
+	})
+}
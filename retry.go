@@ -15,12 +15,20 @@ package retry
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
 	"time"
 )
 
 // RetryFunc is a function passed to retry.
 type RetryFunc func(ctx context.Context) error
 
+// RetryWithDataFunc is a function passed to retry that returns a value in
+// addition to an error.
+type RetryWithDataFunc[T any] func(ctx context.Context) (T, error)
+
 type retryableError struct {
 	err error
 }
@@ -33,6 +41,14 @@ func RetryableError(err error) error {
 	return &retryableError{err}
 }
 
+// RetryableErrorf formats an error according to format, like fmt.Errorf, and
+// marks it as retryable. Unlike RetryableError, it never returns nil, so it can
+// signal a retry with no underlying cause, such as a resource that is not ready
+// yet. The %w verb is supported and wraps its operand as usual.
+func RetryableErrorf(format string, args ...any) error {
+	return &retryableError{fmt.Errorf(format, args...)}
+}
+
 // Unwrap implements error wrapping.
 func (e *retryableError) Unwrap() error {
 	return e.err
@@ -46,47 +62,1518 @@ func (e *retryableError) Error() string {
 	return "retryable: " + e.err.Error()
 }
 
+// IsRetryable reports whether err, or any error it wraps, is marked as
+// retryable with RetryableError. It performs the same check the retry loop uses,
+// so the classification can be reused outside a loop, for example to decide
+// whether to enqueue work for later or fail now.
+func IsRetryable(err error) bool {
+	var rerr *retryableError
+	return errors.As(err, &rerr)
+}
+
+// UnwrapRetryable returns the error wrapped by the first RetryableError marker
+// in the chain of err, and true. If err is not retryable, it returns err and
+// false.
+func UnwrapRetryable(err error) (error, bool) {
+	var rerr *retryableError
+	if errors.As(err, &rerr) {
+		return rerr.Unwrap(), true
+	}
+	return err, false
+}
+
+type permanentError struct {
+	err error
+}
+
+// PermanentError marks an error as permanent. A permanent error stops the retry
+// loop immediately, even if it (or an error it wraps) is also marked as
+// retryable. That is, permanent takes precedence over retryable. The retry loop
+// returns the underlying error.
+func PermanentError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err}
+}
+
+// Unwrap implements error wrapping.
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Error returns the error string.
+func (e *permanentError) Error() string {
+	return "permanent: " + e.err.Error()
+}
+
+type validationError struct {
+	err error
+}
+
+// ValidationError marks an error as a validation failure, such as a malformed
+// request, that must never be retried. It is the explicit counterpart to
+// RetryableError, and takes precedence over every other marker: the retry loop
+// stops immediately if err, or any error that wraps it, is a validation error,
+// even if an outer layer also marked it with RetryableError. The precedence is
+// therefore ValidationError, then PermanentError, then RetryableError.
+//
+// Unlike PermanentError, the marker is kept on the error the retry loop
+// returns, so callers can still detect the failure with IsValidation, for
+// example to respond with 400 Bad Request.
+func ValidationError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &validationError{err}
+}
+
+// Unwrap implements error wrapping.
+func (e *validationError) Unwrap() error {
+	return e.err
+}
+
+// Error returns the error string.
+func (e *validationError) Error() string {
+	return "validation: " + e.err.Error()
+}
+
+// IsValidation reports whether err, or any error it wraps, is marked as a
+// validation failure with ValidationError.
+func IsValidation(err error) bool {
+	var verr *validationError
+	return errors.As(err, &verr)
+}
+
+// unwrapPermanent removes the permanent marker, and any retryable marker
+// directly beneath it, from err if it is the outermost error.
+func unwrapPermanent(err error) error {
+	if e, ok := err.(*permanentError); ok {
+		return unwrapRetryable(e.Unwrap())
+	}
+	return err
+}
+
+// unwrapRetryable removes the retryable marker from err if it is the outermost
+// error. If the caller wrapped a retryable error with additional annotations,
+// err is returned as-is so that the annotations are preserved; the underlying
+// error is still reachable with errors.Is and errors.As.
+func unwrapRetryable(err error) error {
+	switch e := err.(type) {
+	case *retryableError:
+		return e.Unwrap()
+	case *retryAfterError:
+		return unwrapRetryable(e.Unwrap())
+	case *retryMaxError:
+		return unwrapRetryable(e.Unwrap())
+	}
+	return err
+}
+
+// ErrBackoffStopped indicates that the retry loop gave up because the backoff
+// signaled stop, as opposed to the function returning a non-retryable error.
+// Errors returned on this path match ErrBackoffStopped with errors.Is, while
+// the last error returned by the function remains reachable with errors.Is,
+// errors.As, and errors.Unwrap.
+var ErrBackoffStopped = errors.New("retry: backoff stopped")
+
+type backoffStoppedError struct {
+	err      error
+	attempts int
+}
+
+// Unwrap implements error wrapping.
+func (e *backoffStoppedError) Unwrap() error {
+	return e.err
+}
+
+// Error returns the error string of the underlying error.
+func (e *backoffStoppedError) Error() string {
+	return e.err.Error()
+}
+
+// Is reports whether target is ErrBackoffStopped.
+func (e *backoffStoppedError) Is(target error) bool {
+	return target == ErrBackoffStopped
+}
+
+// Attempts implements AttemptsError.
+func (e *backoffStoppedError) Attempts() int {
+	return e.attempts
+}
+
+// ErrBudgetExhausted is matched by the error the retry loop returns when the
+// attempt budget attached to its context with WithAttemptBudget runs out. The
+// last error returned by the function, if any, remains reachable with
+// errors.Is, errors.As, and errors.Unwrap.
+var ErrBudgetExhausted = errors.New("retry: attempt budget exhausted")
+
+type budgetExhaustedError struct {
+	err      error
+	attempts int
+}
+
+// Unwrap implements error wrapping.
+func (e *budgetExhaustedError) Unwrap() error {
+	return e.err
+}
+
+// Error returns the error string.
+func (e *budgetExhaustedError) Error() string {
+	if e.err == nil {
+		return ErrBudgetExhausted.Error()
+	}
+	return ErrBudgetExhausted.Error() + ": " + e.err.Error()
+}
+
+// Is reports whether target is ErrBudgetExhausted.
+func (e *budgetExhaustedError) Is(target error) bool {
+	return target == ErrBudgetExhausted
+}
+
+// Attempts implements AttemptsError.
+func (e *budgetExhaustedError) Attempts() int {
+	return e.attempts
+}
+
+// ErrNotIdempotent is matched by the error the retry loop returns when it
+// refuses to retry because its context was marked with RequireIdempotent and
+// the loop was not started with DoIdempotent or DoWithDataIdempotent. The error
+// returned by the function remains reachable with errors.Is, errors.As, and
+// errors.Unwrap.
+var ErrNotIdempotent = errors.New("retry: refusing to retry an operation not declared idempotent")
+
+type notIdempotentError struct {
+	err      error
+	attempts int
+}
+
+// Unwrap implements error wrapping.
+func (e *notIdempotentError) Unwrap() error {
+	return e.err
+}
+
+// Error returns the error string.
+func (e *notIdempotentError) Error() string {
+	return ErrNotIdempotent.Error() + ": " + e.err.Error()
+}
+
+// Is reports whether target is ErrNotIdempotent.
+func (e *notIdempotentError) Is(target error) bool {
+	return target == ErrNotIdempotent
+}
+
+// Attempts implements AttemptsError.
+func (e *notIdempotentError) Attempts() int {
+	return e.attempts
+}
+
+// AttemptsError is implemented by errors returned by the retry loop when it
+// gives up, either because the backoff stopped or because the function returned
+// a non-retryable error. Attempts reports how many times the function was
+// invoked, so callers can log, for example, "failed after 5 attempts". The
+// error message and the underlying error are unchanged, and remain reachable
+// with errors.Is, errors.As, and errors.Unwrap. Errors returned because the
+// context was canceled do not implement AttemptsError.
+type AttemptsError interface {
+	error
+	Attempts() int
+}
+
+type attemptsError struct {
+	err      error
+	attempts int
+}
+
+// Unwrap implements error wrapping.
+func (e *attemptsError) Unwrap() error {
+	return e.err
+}
+
+// Error returns the error string of the underlying error.
+func (e *attemptsError) Error() string {
+	return e.err.Error()
+}
+
+// Attempts implements AttemptsError.
+func (e *attemptsError) Attempts() int {
+	return e.attempts
+}
+
+// RetryAfterError is an error that carries a hint for how long to wait before
+// the next attempt, such as an HTTP Retry-After header. If a retryable error
+// implements RetryAfterError, the retry loop waits for the larger of the hint
+// and the duration returned by the backoff.
+type RetryAfterError interface {
+	error
+
+	// RetryAfter returns the minimum time to wait before the next attempt.
+	RetryAfter() time.Duration
+}
+
+type retryAfterError struct {
+	err   error
+	after time.Duration
+}
+
+// RetryableErrorAfter marks an error as retryable and requests that the next
+// attempt happen no sooner than d from now.
+func RetryableErrorAfter(err error, d time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	return &retryAfterError{
+		err:   RetryableError(err),
+		after: d,
+	}
+}
+
+// Unwrap implements error wrapping.
+func (e *retryAfterError) Unwrap() error {
+	return e.err
+}
+
+// Error returns the error string.
+func (e *retryAfterError) Error() string {
+	return e.err.Error()
+}
+
+// RetryAfter implements RetryAfterError.
+func (e *retryAfterError) RetryAfter() time.Duration {
+	return e.after
+}
+
+type retryMaxError struct {
+	err       error
+	remaining int
+}
+
+// RetryableErrorMax marks an error as retryable, but hints that it is only
+// worth retrying at most remaining more times. The retry loop stops once the
+// hint is exhausted, even if the backoff would continue. If subsequent errors
+// carry a hint, the smallest remaining budget wins.
+func RetryableErrorMax(err error, remaining int) error {
+	if err == nil {
+		return nil
+	}
+	return &retryMaxError{
+		err:       RetryableError(err),
+		remaining: remaining,
+	}
+}
+
+// Unwrap implements error wrapping.
+func (e *retryMaxError) Unwrap() error {
+	return e.err
+}
+
+// Error returns the error string.
+func (e *retryMaxError) Error() string {
+	return e.err.Error()
+}
+
+// ErrNilBackoff is returned when a nil Backoff is given to the retry loop.
+var ErrNilBackoff = errors.New("retry: backoff is nil")
+
+// minDeadlineReserve is the minimum amount of time reserved for a final attempt
+// when shortening a sleep to fit before the context's deadline. It accounts for
+// timer imprecision when the function itself returns very quickly.
+const minDeadlineReserve = 10 * time.Millisecond
+
 // Do wraps a function with a backoff to retry. The provided context is the same
 // context passed to the RetryFunc.
 func Do(ctx context.Context, b Backoff, f RetryFunc) error {
-	for {
+	// The adaptor does not escape, so it costs no allocation.
+	_, err := doWithData(ctx, b, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, f(ctx)
+	}, loopOptions{})
+	return err
+}
+
+// DoSimple is like Do, but for functions that do not take a context, using
+// context.Background() internally. It is meant for simple scripts and tests;
+// for production code that should be cancellable, prefer Do.
+func DoSimple(b Backoff, f func() error) error {
+	return Do(context.Background(), b, func(_ context.Context) error {
+		return f()
+	})
+}
+
+// DoSimpleWithData is like DoWithData, but for functions that do not take a
+// context, using context.Background() internally. It is meant for simple
+// scripts and tests; for production code that should be cancellable, prefer
+// DoWithData.
+func DoSimpleWithData[T any](b Backoff, f func() (T, error)) (T, error) {
+	return DoWithData(context.Background(), b, func(_ context.Context) (T, error) {
+		return f()
+	})
+}
+
+// DoWithData wraps a function that returns a value with a backoff to retry.
+// The provided context is the same context passed to the RetryWithDataFunc. On
+// success, the value returned by the function is returned. On failure, the
+// zero value of T is returned.
+//
+// If the context has a deadline that would expire before the next attempt, the
+// sleep is shortened so that one final attempt can run before the deadline.
+//
+// If b is nil, ErrNilBackoff is returned without invoking f.
+func DoWithData[T any](ctx context.Context, b Backoff, f RetryWithDataFunc[T]) (T, error) {
+	return doWithData(ctx, b, f, loopOptions{})
+}
+
+// DoWithMinInterval is like DoWithData, but never sleeps for less than
+// minInterval between attempts, whatever the backoff returns. It is a safety
+// floor against a misconfigured or custom backoff that returns 0 indefinitely,
+// which would otherwise spin through attempts at full CPU. Unlike
+// WithMinDuration, which is part of a backoff, the floor belongs to the loop,
+// so it also applies after the sleep has been shortened to fit the context's
+// deadline. A minInterval of 0 or less has no effect.
+func DoWithMinInterval[T any](ctx context.Context, b Backoff, minInterval time.Duration, f RetryWithDataFunc[T]) (T, error) {
+	return doWithData(ctx, b, f, loopOptions{
+		minInterval: minInterval,
+	})
+}
+
+// DoIdempotent is like Do, but declares that f is idempotent, meaning it is
+// safe to repeat, such as a read or an upsert keyed by a request ID. Only use
+// it for such operations. The declaration is what allows retries in a context
+// marked with RequireIdempotent; otherwise, DoIdempotent behaves exactly like
+// Do. It applies to this retry loop only, not to loops nested inside f.
+func DoIdempotent(ctx context.Context, b Backoff, f RetryFunc) error {
+	_, err := doWithData(ctx, b, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, f(ctx)
+	}, loopOptions{idempotent: true})
+	return err
+}
+
+// DoWithDataIdempotent is like DoIdempotent, but for a function that returns a
+// value, as in DoWithData.
+func DoWithDataIdempotent[T any](ctx context.Context, b Backoff, f RetryWithDataFunc[T]) (T, error) {
+	return doWithData(ctx, b, f, loopOptions{idempotent: true})
+}
+
+// Wrap returns a function that calls f with retries baked in, so the retrying
+// version can be passed wherever f was expected, for example when injecting a
+// dependency. Each call of the returned function retries with a fresh Clone of
+// b, so calls do not share backoff state; b should therefore be a fresh
+// backoff, and must implement Cloneable to be safely called concurrently.
+func Wrap[T any](b Backoff, f RetryWithDataFunc[T]) RetryWithDataFunc[T] {
+	return func(ctx context.Context) (T, error) {
+		return DoWithData(ctx, Clone(b), f)
+	}
+}
+
+// loopOptions are optional hooks into the retry loop used by the variants of
+// DoWithData that cannot be built by wrapping the function or the backoff.
+type loopOptions struct {
+	// observeDelay, if set, is called with each sleep duration right before the
+	// timer starts.
+	observeDelay DelayObserver
+
+	// sleeper, if set, replaces the timer used to wait between attempts.
+	sleeper Sleeper
+
+	// atLeastOnce skips the cancellation check before the first attempt, so f
+	// is always invoked at least once.
+	atLeastOnce bool
+
+	// idempotent declares that f is safe to repeat, which allows retries under
+	// RequireIdempotent.
+	idempotent bool
+
+	// minInterval, if positive, is the shortest sleep between attempts.
+	minInterval time.Duration
+}
+
+// doWithData is the retry loop behind DoWithData.
+func doWithData[T any](ctx context.Context, b Backoff, f RetryWithDataFunc[T], opts loopOptions) (T, error) {
+	var zero T
+
+	if b == nil {
+		return zero, ErrNilBackoff
+	}
+
+	// remaining is the retry budget hinted by RetryableErrorMax, or -1 if no
+	// hint has been seen.
+	remaining := -1
+
+	// The timer is allocated on the first retry and reused afterwards.
+	var t *time.Timer
+	defer func() {
+		if t != nil {
+			t.Stop()
+		}
+	}()
+
+	deadline, hasDeadline := ctx.Deadline()
+	disabled := DisabledFromContext(ctx)
+	budget := attemptBudgetFromContext(ctx)
+	retryBudget := budgetFromContext(ctx)
+	requireIdempotent := requiresIdempotent(ctx)
+
+	progress := progressFromContext(ctx)
+	maxAttempts := -1
+	if progress != nil {
+		maxAttempts = maxAttemptsOf(b)
+	}
+
+	var lastErr error
+
+	for attempt := 1; ; attempt++ {
 		// Return immediately if ctx is canceled
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		default:
+		if attempt > 1 || !opts.atLeastOnce {
+			select {
+			case <-ctx.Done():
+				return zero, ctx.Err()
+			default:
+			}
+
+			// Draw from the attempt budget shared with enclosing loops
+			if budget != nil && !budget.take() {
+				return zero, &budgetExhaustedError{lastErr, attempt - 1}
+			}
 		}
 
-		err := f(ctx)
+		var start time.Time
+		if hasDeadline {
+			start = time.Now()
+		}
+
+		val, err := f(withAttempt(ctx, attempt, lastErr))
 		if err == nil {
-			return nil
+			if retryBudget != nil {
+				retryBudget.deposit()
+			}
+			return val, nil
+		}
+		lastErr = unwrapRetryable(err)
+
+		// Retries were disabled for this request
+		if disabled {
+			return zero, &attemptsError{unwrapPermanent(lastErr), attempt}
+		}
+
+		// Validation failures are never retried, whatever else wraps them
+		if IsValidation(err) {
+			return zero, &attemptsError{unwrapRetryable(err), attempt}
+		}
+
+		// Permanent, even if also retryable
+		var perr *permanentError
+		if errors.As(err, &perr) {
+			return zero, &attemptsError{unwrapPermanent(err), attempt}
 		}
 
 		// Not retryable
-		var rerr *retryableError
-		if !errors.As(err, &rerr) {
-			return err
+		if !IsRetryable(err) {
+			return zero, &attemptsError{err, attempt}
+		}
+
+		// Stop early if the error hinted that it's no longer worth retrying
+		var rmerr *retryMaxError
+		if errors.As(err, &rmerr) && (remaining < 0 || rmerr.remaining < remaining) {
+			remaining = rmerr.remaining
+		}
+		if remaining == 0 {
+			return zero, &backoffStoppedError{unwrapRetryable(err), attempt}
+		}
+		if remaining > 0 {
+			remaining--
 		}
 
+		// Refuse to repeat an operation that was not declared safe to repeat
+		if requireIdempotent && !opts.idempotent {
+			return zero, &notIdempotentError{lastErr, attempt}
+		}
+
+		// Don't sleep if the attempt budget is already spent
+		if budget != nil && budget.remaining() <= 0 {
+			return zero, &budgetExhaustedError{lastErr, attempt}
+		}
+
+		// Skip the retry if the shared retry budget has no token for it
+		if retryBudget != nil && !retryBudget.withdraw() {
+			return zero, &backoffStoppedError{unwrapRetryable(err), attempt}
+		}
+
+		observeError(b, lastErr)
+		observeDeadline(b, deadline)
 		next, stop := b.Next()
 		if stop {
-			return rerr.Unwrap()
+			if retryBudget != nil {
+				retryBudget.refund()
+			}
+			return zero, &backoffStoppedError{unwrapRetryable(err), attempt}
+		}
+
+		// If the context would expire before the next attempt, shorten the sleep
+		// to leave time for one final attempt, assuming it takes about as long as
+		// the last one.
+		if hasDeadline {
+			reserve := time.Since(start)
+			if reserve < minDeadlineReserve {
+				reserve = minDeadlineReserve
+			}
+			if left := time.Until(deadline); next >= left && left > reserve {
+				next = left - reserve
+			}
+		}
+
+		// Honor the server's hint if it's longer than the backoff
+		var raerr RetryAfterError
+		if errors.As(err, &raerr) {
+			if after := raerr.RetryAfter(); after > next {
+				next = after
+			}
+		}
+
+		// Never spin faster than the safety floor
+		if next < opts.minInterval {
+			next = opts.minInterval
 		}
 
 		// ctx.Done() has priority, so we test it alone first
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
+			return zero, ctx.Err()
 		default:
 		}
 
-		t := time.NewTimer(next)
+		if opts.observeDelay != nil {
+			opts.observeDelay(next)
+		}
+		if progress != nil {
+			progress(Progress{
+				Attempt:     attempt,
+				MaxAttempts: maxAttempts,
+				NextDelay:   next,
+			})
+		}
+
+		if opts.sleeper != nil {
+			if err := opts.sleeper.Sleep(ctx, next); err != nil {
+				return zero, err
+			}
+			continue
+		}
+
+		// There's nothing to wait for, so skip the timer entirely. The context
+		// was checked above.
+		if next <= 0 {
+			continue
+		}
+
+		// The timer is always drained before it is reset, since the only way to
+		// get here again is by receiving from t.C.
+		if t == nil {
+			t = time.NewTimer(next)
+		} else {
+			t.Reset(next)
+		}
+
+		// If ctx is canceled as the timer fires, select may pick either case.
+		// The check at the top of the loop still returns before f is invoked
+		// again, so a canceled ctx never leads to another attempt.
 		select {
 		case <-ctx.Done():
-			t.Stop()
-			return ctx.Err()
+			return zero, ctx.Err()
 		case <-t.C:
 			continue
 		}
 	}
 }
+
+// DoAtLeastOnce is like DoWithData, but always invokes f at least once, even if
+// ctx is already done when it is called. This suits best-effort cleanup that
+// should run even while shutting down. Beware that f receives the done context,
+// so any operation in f that honors it, such as an HTTP request built from it,
+// fails immediately; f must use a context of its own for work that should
+// outlive ctx. If the first attempt fails and ctx is done, the context's error
+// is returned without retrying.
+func DoAtLeastOnce[T any](ctx context.Context, b Backoff, f RetryWithDataFunc[T]) (T, error) {
+	return doWithData(ctx, b, f, loopOptions{
+		atLeastOnce: true,
+	})
+}
+
+// DelayObserver is a function called with the duration the retry loop is about
+// to sleep.
+type DelayObserver func(d time.Duration)
+
+// DoWithDelayObserver is like DoWithData, but calls observe with each sleep
+// duration right before the timer starts. Unlike NotifyFunc, observe receives
+// the true sleep duration, after the delay has been shortened to fit the
+// context's deadline and lengthened to honor a RetryAfterError. This is useful
+// for recording the delays of real runs to tune a backoff.
+func DoWithDelayObserver[T any](ctx context.Context, b Backoff, f RetryWithDataFunc[T], observe DelayObserver) (T, error) {
+	return doWithData(ctx, b, f, loopOptions{
+		observeDelay: observe,
+	})
+}
+
+// Result contains information about a completed retry loop.
+type Result struct {
+	// Attempts is the number of times the function was invoked.
+	Attempts int
+
+	// TotalElapsed is the total time spent in the retry loop, including the
+	// execution time of the function and the time spent sleeping.
+	TotalElapsed time.Duration
+
+	// LastBackoff is the last duration returned by the backoff, or 0 if the
+	// backoff was never consulted.
+	LastBackoff time.Duration
+}
+
+// DoWithResult is like DoWithData, but also returns a Result describing the
+// execution of the retry loop. The Result is populated on both the success and
+// error paths.
+func DoWithResult[T any](ctx context.Context, b Backoff, f RetryWithDataFunc[T]) (T, Result, error) {
+	var result Result
+	if b == nil {
+		var zero T
+		return zero, result, ErrNilBackoff
+	}
+
+	start := time.Now()
+
+	val, err := DoWithData(ctx, withNext(b, func() (time.Duration, bool) {
+		next, stop := b.Next()
+		if !stop {
+			result.LastBackoff = next
+		}
+		return next, stop
+	}), func(ctx context.Context) (T, error) {
+		result.Attempts++
+		return f(ctx)
+	})
+
+	result.TotalElapsed = time.Since(start)
+	return val, result, err
+}
+
+// Outcome describes why a retry loop returned, as reported by DoWithOutcome.
+type Outcome int
+
+const (
+	// OutcomeSuccess means the function succeeded.
+	OutcomeSuccess Outcome = iota
+
+	// OutcomeNonRetryable means the function returned an error that was not
+	// retryable, or was marked with PermanentError.
+	OutcomeNonRetryable
+
+	// OutcomeBackoffExhausted means the backoff signaled stop, and the returned
+	// error matches ErrBackoffStopped.
+	OutcomeBackoffExhausted
+
+	// OutcomeContextCanceled means the context was canceled or its deadline
+	// passed, and the returned error is the context's error.
+	OutcomeContextCanceled
+)
+
+// String implements fmt.Stringer.
+func (o Outcome) String() string {
+	switch o {
+	case OutcomeSuccess:
+		return "Success"
+	case OutcomeNonRetryable:
+		return "NonRetryable"
+	case OutcomeBackoffExhausted:
+		return "BackoffExhausted"
+	case OutcomeContextCanceled:
+		return "ContextCanceled"
+	default:
+		return "Outcome(" + strconv.Itoa(int(o)) + ")"
+	}
+}
+
+// DoWithOutcome is like Do, but also returns an Outcome describing why the
+// retry loop returned, so callers can switch on it for metrics or alerting
+// instead of inspecting the error.
+func DoWithOutcome(ctx context.Context, b Backoff, f RetryFunc) (Outcome, error) {
+	err := Do(ctx, b, f)
+	switch {
+	case err == nil:
+		return OutcomeSuccess, nil
+	case errors.Is(err, ErrBackoffStopped):
+		return OutcomeBackoffExhausted, err
+	case ctx.Err() != nil && errors.Is(err, ctx.Err()):
+		return OutcomeContextCanceled, err
+	default:
+		return OutcomeNonRetryable, err
+	}
+}
+
+// Trace is a record of a retry loop, as returned by DoWithTrace.
+type Trace struct {
+	// Delays contains the duration slept before each retry, after the delay
+	// has been shortened to fit the context's deadline and lengthened to honor
+	// a RetryAfterError.
+	Delays []time.Duration
+
+	// Errors contains the error returned by each attempt, in order, with the
+	// RetryableError and PermanentError markers removed. The entry for a
+	// successful attempt is nil.
+	Errors []error
+}
+
+// DoWithTrace is like Do, but also returns a Trace of the delays that were
+// actually slept and the errors returned by each attempt. Unlike
+// DoWithDelayObserver, the complete record is returned once the retry loop
+// finishes, which is useful for the post-mortem analysis of a single failed
+// operation. The Trace is populated on both the success and error paths.
+func DoWithTrace(ctx context.Context, b Backoff, f RetryFunc) (Trace, error) {
+	var trace Trace
+
+	_, err := doWithData(ctx, b, func(ctx context.Context) (any, error) {
+		err := f(ctx)
+		if err != nil {
+			trace.Errors = append(trace.Errors, unwrapPermanent(unwrapRetryable(err)))
+		} else {
+			trace.Errors = append(trace.Errors, nil)
+		}
+		return nil, err
+	}, loopOptions{
+		observeDelay: func(d time.Duration) {
+			trace.Delays = append(trace.Delays, d)
+		},
+	})
+	return trace, err
+}
+
+// TimelineEntry is an error returned by an attempt, and when it was returned
+// relative to the start of the retry loop.
+type TimelineEntry struct {
+	At  time.Duration
+	Err error
+}
+
+// TimelineError is returned by DoWithTimeline when the retry loop gives up. Its
+// message lists the error of every attempt with its time relative to the start
+// of the loop, such as "[t+0ms] err1; [t+110ms] err2", and it unwraps to the
+// error the loop would otherwise have returned, so errors.Is and errors.As
+// still match the last error, ErrBackoffStopped, or the context's error.
+type TimelineError struct {
+	err      error
+	timeline []TimelineEntry
+}
+
+// Unwrap implements error wrapping.
+func (e *TimelineError) Unwrap() error {
+	return e.err
+}
+
+// Error returns the error string.
+func (e *TimelineError) Error() string {
+	if len(e.timeline) == 0 {
+		return e.err.Error()
+	}
+
+	var b strings.Builder
+	for i, entry := range e.timeline {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		b.WriteString("[t+")
+		b.WriteString(strconv.FormatInt(entry.At.Milliseconds(), 10))
+		b.WriteString("ms] ")
+		b.WriteString(entry.Err.Error())
+	}
+	return b.String()
+}
+
+// Timeline returns the error of every attempt, in order, with the
+// RetryableError and PermanentError markers removed.
+func (e *TimelineError) Timeline() []TimelineEntry {
+	return e.timeline
+}
+
+// DoWithTimeline is like DoWithData, but when the retry loop gives up, the
+// returned error is a *TimelineError recording when each attempt failed. This
+// is useful for forensic debugging of a single failed operation.
+func DoWithTimeline[T any](ctx context.Context, b Backoff, f RetryWithDataFunc[T]) (T, error) {
+	var timeline []TimelineEntry
+	start := time.Now()
+
+	val, err := DoWithData(ctx, b, func(ctx context.Context) (T, error) {
+		val, err := f(ctx)
+		if err != nil {
+			timeline = append(timeline, TimelineEntry{
+				At:  time.Since(start),
+				Err: unwrapPermanent(unwrapRetryable(err)),
+			})
+		}
+		return val, err
+	})
+	if err != nil {
+		return val, &TimelineError{err: err, timeline: timeline}
+	}
+	return val, nil
+}
+
+// NotifyFunc is a function called before each retry with the 1-based number of
+// the attempt that failed, the underlying error, and the duration until the
+// next attempt.
+type NotifyFunc func(attempt int, err error, next time.Duration)
+
+// DoWithNotify is like Do, but calls notify after a retryable error is observed
+// and the next backoff duration is computed, but before sleeping. notify is not
+// called when the backoff signals stop or when the error is not retryable.
+func DoWithNotify(ctx context.Context, b Backoff, f RetryFunc, notify NotifyFunc) error {
+	if b == nil {
+		return ErrNilBackoff
+	}
+
+	var attempt int
+	var lastErr error
+
+	return Do(ctx, withNext(b, func() (time.Duration, bool) {
+		next, stop := b.Next()
+		if !stop {
+			notify(attempt, lastErr, next)
+		}
+		return next, stop
+	}), func(ctx context.Context) error {
+		attempt++
+
+		err := f(ctx)
+		lastErr = err
+
+		if inner, ok := UnwrapRetryable(err); ok {
+			lastErr = inner
+		}
+		return err
+	})
+}
+
+// DoWithFirstRetry is like Do, but calls onFirstRetry with the underlying error
+// the first time the function fails with a retryable error and a retry will
+// follow, and never again during the same call. This is a cheap, quiet signal
+// for the transition from working to degraded, such as for alerting. Like
+// DoWithNotify, it is not called if the backoff stops before any retry.
+func DoWithFirstRetry(ctx context.Context, b Backoff, f RetryFunc, onFirstRetry func(err error)) error {
+	var fired bool
+
+	return DoWithNotify(ctx, b, f, func(_ int, err error, _ time.Duration) {
+		if !fired {
+			fired = true
+			onFirstRetry(err)
+		}
+	})
+}
+
+// CoalescedNotifyFunc is a function called before each retry with the
+// underlying error, the number of consecutive failed attempts that returned
+// the same error, and the duration until the next attempt.
+type CoalescedNotifyFunc func(err error, repeatCount int, next time.Duration)
+
+// DoWithCoalescedNotify is like DoWithNotify, but tracks runs of consecutive
+// identical errors so that notify can log "error X (repeated 5 times)" rather
+// than flooding logs during a sustained single-cause failure. repeatCount is 1
+// for the first occurrence of an error and increases while later attempts fail
+// with an error equal to it, restarting at 1 when the error changes.
+//
+// Errors are compared with equal, or by their messages if equal is nil.
+func DoWithCoalescedNotify(ctx context.Context, b Backoff, f RetryFunc, notify CoalescedNotifyFunc, equal func(a, b error) bool) error {
+	if equal == nil {
+		equal = func(a, b error) bool {
+			return a.Error() == b.Error()
+		}
+	}
+
+	var prev error
+	var repeat int
+
+	return DoWithNotify(ctx, b, f, func(_ int, err error, next time.Duration) {
+		if prev != nil && equal(err, prev) {
+			repeat++
+		} else {
+			repeat = 1
+		}
+		prev = err
+
+		notify(err, repeat, next)
+	})
+}
+
+// DoWithCleanup is like Do, but calls cleanup after each failed attempt that
+// will be retried, before sleeping, with the error the attempt returned with the
+// RetryableError marker removed. This formalizes the "roll back, then retry"
+// pattern for transactions. cleanup is not called after a successful attempt,
+// nor when the retry loop gives up, since no retry follows; run any final
+// cleanup on the returned error instead.
+func DoWithCleanup(ctx context.Context, b Backoff, f RetryFunc, cleanup func(ctx context.Context, err error)) error {
+	if b == nil {
+		return ErrNilBackoff
+	}
+
+	var lastErr error
+
+	return Do(ctx, withNext(b, func() (time.Duration, bool) {
+		next, stop := b.Next()
+		if !stop {
+			cleanup(ctx, lastErr)
+		}
+		return next, stop
+	}), func(ctx context.Context) error {
+		err := f(ctx)
+		lastErr = unwrapRetryable(err)
+		return err
+	})
+}
+
+// ClassifierFunc reports whether an error should be retried.
+type ClassifierFunc func(err error) bool
+
+// DoWithClassifier is like Do, but uses classify to decide whether an error is
+// retryable instead of requiring errors to be wrapped with RetryableError. A
+// nil error returns immediately, an error for which classify returns true is
+// retried, and an error for which classify returns false is returned
+// immediately.
+//
+// Errors explicitly wrapped with RetryableError are always retried, even if
+// classify returns false for them. That is, an explicit wrap takes precedence
+// over the classifier.
+func DoWithClassifier(ctx context.Context, b Backoff, f RetryFunc, classify ClassifierFunc) error {
+	return Do(ctx, b, func(ctx context.Context) error {
+		err := f(ctx)
+		if err == nil {
+			return nil
+		}
+
+		if IsRetryable(err) {
+			return err
+		}
+
+		if classify(err) {
+			return RetryableError(err)
+		}
+		return err
+	})
+}
+
+// TransformFunc normalizes an error returned by a function before the retry
+// loop decides whether it is retryable.
+type TransformFunc func(err error) error
+
+// DoWithTransform is like DoWithData, but applies transform to every non-nil
+// error returned by f before the retryable check. This centralizes error
+// classification for a whole service, for example wrapping a third-party
+// "unavailable" status with RetryableError, instead of wrapping at every call
+// site. If transform returns nil, the attempt is treated as a success and the
+// value returned by f is returned.
+func DoWithTransform[T any](ctx context.Context, b Backoff, f RetryWithDataFunc[T], transform TransformFunc) (T, error) {
+	return DoWithData(ctx, b, func(ctx context.Context) (T, error) {
+		val, err := f(ctx)
+		if err != nil {
+			err = transform(err)
+		}
+		return val, err
+	})
+}
+
+// ContextFunc is a function that derives the context passed to an attempt from
+// the context the retry loop would otherwise pass, given the 1-based attempt
+// number.
+type ContextFunc func(ctx context.Context, attempt int) context.Context
+
+// DoWithAttemptContext is like DoWithData, but calls withCtx before each
+// attempt and passes the context it returns to f. This lets each attempt carry
+// its own values, such as a request ID or a tracing span. The context given to
+// withCtx already carries the attempt number and previous error, so
+// AttemptFromContext and LastErrorFromContext keep working as long as withCtx
+// derives its result from it. A nil withCtx passes the context through
+// unchanged.
+func DoWithAttemptContext[T any](ctx context.Context, b Backoff, f RetryWithDataFunc[T], withCtx ContextFunc) (T, error) {
+	if withCtx == nil {
+		return DoWithData(ctx, b, f)
+	}
+
+	return DoWithData(ctx, b, func(ctx context.Context) (T, error) {
+		return f(withCtx(ctx, AttemptFromContext(ctx)))
+	})
+}
+
+// DoWithNonRetryable is like DoWithData, but returns immediately if an error
+// returned by f matches any of nonRetryable with errors.Is, such as
+// sql.ErrNoRows. This overrides even an explicit RetryableError wrap, and is a
+// lightweight alternative to DoWithClassifier when only a few specific errors
+// are known to be fatal. The RetryableError marker, if any, is removed from the
+// returned error.
+func DoWithNonRetryable[T any](ctx context.Context, b Backoff, f RetryWithDataFunc[T], nonRetryable ...error) (T, error) {
+	return DoWithData(ctx, b, func(ctx context.Context) (T, error) {
+		val, err := f(ctx)
+		if err == nil {
+			return val, nil
+		}
+
+		for _, target := range nonRetryable {
+			if errors.Is(err, target) {
+				return val, PermanentError(err)
+			}
+		}
+		return val, err
+	})
+}
+
+// ErrMaxCalls is matched by the error DoWithMaxCalls returns when f has been
+// invoked the maximum number of times without succeeding.
+var ErrMaxCalls = errors.New("retry: maximum calls reached")
+
+type maxCallsError struct {
+	err error
+}
+
+// Unwrap implements error wrapping.
+func (e *maxCallsError) Unwrap() error {
+	return e.err
+}
+
+// Error returns the error string.
+func (e *maxCallsError) Error() string {
+	return ErrMaxCalls.Error() + ": " + e.err.Error()
+}
+
+// Is reports whether target is ErrMaxCalls.
+func (e *maxCallsError) Is(target error) bool {
+	return target == ErrMaxCalls
+}
+
+// DoWithMaxCalls is like DoWithData, but invokes f at most maxCalls times,
+// regardless of what the backoff allows. If the last permitted call returns a
+// retryable error, the loop stops without sleeping and the returned error
+// matches ErrMaxCalls, while the last error returned by f remains reachable
+// with errors.Is and errors.As. This is a safety valve against runaway loops,
+// for example when a ready predicate or classifier never lets the loop end. A
+// maxCalls of 0 or less means no limit.
+func DoWithMaxCalls[T any](ctx context.Context, b Backoff, maxCalls int, f RetryWithDataFunc[T]) (T, error) {
+	var calls int
+
+	return DoWithData(ctx, b, func(ctx context.Context) (T, error) {
+		calls++
+
+		val, err := f(ctx)
+		if err != nil && maxCalls > 0 && calls >= maxCalls && IsRetryable(err) {
+			return val, PermanentError(&maxCallsError{unwrapRetryable(err)})
+		}
+		return val, err
+	})
+}
+
+// DoWithFinalAttempt is like DoWithData, but when the backoff signals stop, it
+// invokes f one final time, without waiting, and returns its result directly
+// instead of the previous error. Any RetryableError or PermanentError marker is
+// removed from the final error. This suits cleanup-style operations that should
+// try their best before giving up, at the cost of one guaranteed extra call and
+// a slightly longer worst case: with WithMaxRetries(n, b), f is invoked up to
+// n+2 times. No final attempt is made if f returns a non-retryable error or if
+// ctx is done.
+func DoWithFinalAttempt[T any](ctx context.Context, b Backoff, f RetryWithDataFunc[T]) (T, error) {
+	var attempts int
+	var lastErr error
+
+	val, err := DoWithData(ctx, b, func(ctx context.Context) (T, error) {
+		attempts++
+		val, err := f(ctx)
+		lastErr = err
+		return val, err
+	})
+	if err == nil || !errors.Is(err, ErrBackoffStopped) || ctx.Err() != nil {
+		return val, err
+	}
+
+	val, err = f(withAttempt(ctx, attempts+1, unwrapRetryable(lastErr)))
+	if err != nil {
+		return val, unwrapPermanent(unwrapRetryable(err))
+	}
+	return val, nil
+}
+
+// DoWithLastData is like DoWithData, but if the context is canceled, it
+// returns the value from the most recent invocation of f alongside the
+// context's error, instead of the zero value. If f was never invoked, the zero
+// value is returned. This is useful for fetch-then-validate workflows where a
+// partial result is better than none.
+func DoWithLastData[T any](ctx context.Context, b Backoff, f RetryWithDataFunc[T]) (T, error) {
+	var last T
+
+	val, err := DoWithData(ctx, b, func(ctx context.Context) (T, error) {
+		val, err := f(ctx)
+		last = val
+		return val, err
+	})
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil && errors.Is(err, ctxErr) {
+			return last, err
+		}
+	}
+	return val, err
+}
+
+// DoWithAttemptTimeout is like Do, but each invocation of f receives a context
+// that is canceled after attemptTimeout, so a single stuck attempt cannot hang
+// the retry loop. Cancellation of the parent context still stops the entire
+// loop.
+//
+// If an attempt times out and f returns context.DeadlineExceeded, the error is
+// treated as retryable as long as the parent context has not also expired.
+func DoWithAttemptTimeout(ctx context.Context, b Backoff, attemptTimeout time.Duration, f RetryFunc) error {
+	return Do(ctx, b, func(ctx context.Context) error {
+		attemptCtx, cancel := context.WithTimeout(ctx, attemptTimeout)
+		defer cancel()
+
+		err := f(attemptCtx)
+		if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+			if !IsRetryable(err) {
+				return RetryableError(err)
+			}
+		}
+		return err
+	})
+}
+
+// ErrStopped is returned by DoWithDataStop when the stop channel is closed.
+var ErrStopped = errors.New("retry: stopped")
+
+// DoWithDataStop is like DoWithData, but also aborts when stop is closed,
+// returning ErrStopped. This bridges retrying into code that signals shutdown
+// with a channel rather than a context. Cancellation of the context still
+// returns the context's error. The context passed to f is canceled when stop is
+// closed.
+func DoWithDataStop[T any](ctx context.Context, b Backoff, stop <-chan struct{}, f RetryWithDataFunc[T]) (T, error) {
+	var zero T
+
+	select {
+	case <-stop:
+		return zero, ErrStopped
+	default:
+	}
+
+	stopCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go func() {
+		select {
+		case <-stop:
+			cancel()
+		case <-stopCtx.Done():
+		}
+	}()
+
+	val, err := DoWithData(stopCtx, b, f)
+	if err != nil && ctx.Err() == nil && errors.Is(err, context.Canceled) {
+		select {
+		case <-stop:
+			return zero, ErrStopped
+		default:
+		}
+	}
+	return val, err
+}
+
+// DoUntilSignal is like Do, but also stops when done is closed, treating it as
+// a success and returning nil. Unlike cancellation, which returns the context's
+// error, closing done means "stop trying, we're good", for example when another
+// goroutine observed that the condition a cleanup function was retrying for has
+// been met. If the context is also canceled, cancellation takes precedence and
+// the context's error is returned. The context passed to f is canceled when done
+// is closed.
+func DoUntilSignal(ctx context.Context, b Backoff, f RetryFunc, done <-chan struct{}) error {
+	_, err := DoWithDataStop(ctx, b, done, func(ctx context.Context) (any, error) {
+		return nil, f(ctx)
+	})
+	if err == ErrStopped {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return nil
+	}
+	return err
+}
+
+// ErrDeadlineExceeded is matched by the error DoWithDeadlineAsError returns when
+// the context's deadline passes.
+var ErrDeadlineExceeded = errors.New("retry: deadline exceeded")
+
+type deadlineExceededError struct {
+	err error
+}
+
+// Unwrap implements error wrapping.
+func (e *deadlineExceededError) Unwrap() error {
+	return e.err
+}
+
+// Error returns the error string.
+func (e *deadlineExceededError) Error() string {
+	return ErrDeadlineExceeded.Error() + ": " + e.err.Error()
+}
+
+// Is reports whether target is ErrDeadlineExceeded.
+func (e *deadlineExceededError) Is(target error) bool {
+	return target == ErrDeadlineExceeded
+}
+
+// DoWithDeadlineAsError is like DoWithData, but distinguishes the two causes of
+// context cancellation. If the context's deadline passes, the returned error
+// matches ErrDeadlineExceeded (and still context.DeadlineExceeded) and is
+// marked with RetryableError, so an enclosing retry loop treats running out of
+// time as a retryable condition. If the context is canceled, context.Canceled
+// is returned unchanged, so cancellation always aborts.
+func DoWithDeadlineAsError[T any](ctx context.Context, b Backoff, f RetryWithDataFunc[T]) (T, error) {
+	val, err := DoWithData(ctx, b, f)
+	if err != nil && ctx.Err() == context.DeadlineExceeded && errors.Is(err, context.DeadlineExceeded) {
+		return val, RetryableError(&deadlineExceededError{err})
+	}
+	return val, err
+}
+
+// DoWithDataTimeout is like DoWithData, but retries for at most timeout. If the
+// timeout elapses, context.DeadlineExceeded is returned.
+func DoWithDataTimeout[T any](parent context.Context, timeout time.Duration, b Backoff, f RetryWithDataFunc[T]) (T, error) {
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+
+	return DoWithData(ctx, b, f)
+}
+
+// ErrNotReady is the underlying error returned by DoUntil when the backoff
+// stops before the function produced a ready value.
+var ErrNotReady = errors.New("retry: value not ready")
+
+// DoUntil is like DoWithData, but also retries while f returns a nil error and
+// a value for which ready returns false. It returns the first value for which
+// ready returns true. Errors returned by f follow the usual retry semantics,
+// and ready is not called for them.
+//
+// If the backoff stops while the value is still not ready, the returned error
+// matches both ErrBackoffStopped and ErrNotReady.
+func DoUntil[T any](ctx context.Context, b Backoff, f RetryWithDataFunc[T], ready func(T) bool) (T, error) {
+	return DoWithData(ctx, b, func(ctx context.Context) (T, error) {
+		val, err := f(ctx)
+		if err != nil {
+			return val, err
+		}
+		if !ready(val) {
+			return val, RetryableError(ErrNotReady)
+		}
+		return val, nil
+	})
+}
+
+// DoForever repeatedly invokes f for as long as the context is alive. It is
+// meant for supervising long-lived work, such as a persistent connection: when
+// f returns nil, the backoff is reset (if it implements Resettable) and f is
+// invoked again immediately, so the next outage starts from the base delay.
+//
+// Every error returned by f is retried, whether or not it is wrapped with
+// RetryableError, except for errors wrapped with PermanentError or
+// ValidationError. DoForever returns only when the context is canceled, f
+// returns a permanent or validation error, or the backoff stops.
+func DoForever(ctx context.Context, b Backoff, f RetryFunc) error {
+	for {
+		if err := Do(ctx, b, func(ctx context.Context) error {
+			err := f(ctx)
+			if err == nil {
+				return nil
+			}
+
+			var perr *permanentError
+			if errors.As(err, &perr) {
+				return err
+			}
+
+			if !IsRetryable(err) {
+				return RetryableError(err)
+			}
+			return err
+		}); err != nil {
+			return err
+		}
+
+		resetBackoff(b)
+	}
+}
+
+// DoCollectErrors is like DoWithData, but when the retry loop gives up, the
+// returned error joins the errors from every attempt with errors.Join, so
+// errors.Is matches any of them and the message lists them all. The final
+// error takes the place of the last attempt's error, so it still matches, for
+// example, ErrBackoffStopped or the context's error. If only one error was
+// observed, it is returned as-is.
+func DoCollectErrors[T any](ctx context.Context, b Backoff, f RetryWithDataFunc[T]) (T, error) {
+	var errs []error
+
+	val, err := DoWithData(ctx, b, func(ctx context.Context) (T, error) {
+		val, err := f(ctx)
+		if err != nil {
+			errs = append(errs, unwrapPermanent(unwrapRetryable(err)))
+		}
+		return val, err
+	})
+	if err == nil {
+		return val, nil
+	}
+
+	if n := len(errs); n > 0 && errors.Is(err, errs[n-1]) {
+		errs[n-1] = err
+	} else {
+		errs = append(errs, err)
+	}
+
+	if len(errs) == 1 {
+		return val, errs[0]
+	}
+	return val, errors.Join(errs...)
+}
+
+// DoSuccessLog is like DoWithData, but also returns the errors of the attempts
+// that failed, in order, with the RetryableError marker removed. On success,
+// these are the errors that preceded it, which answers whether an operation
+// succeeded cleanly or only after flapping, for example for audit logging. The
+// slice is nil if the first attempt succeeded, and is only allocated once an
+// attempt fails. If the retry loop gives up, the slice includes the error of
+// the last attempt, and the returned error is unchanged.
+func DoSuccessLog[T any](ctx context.Context, b Backoff, f RetryWithDataFunc[T]) (T, []error, error) {
+	var errs []error
+
+	val, err := DoWithData(ctx, b, func(ctx context.Context) (T, error) {
+		val, err := f(ctx)
+		if err != nil {
+			errs = append(errs, unwrapRetryable(err))
+		}
+		return val, err
+	})
+	return val, errs, err
+}
+
+// StreamFunc is a function passed to DoStream. It receives the checkpoint
+// returned by the previous attempt, or the zero value on the first attempt, and
+// returns the checkpoint it reached, even when it fails.
+type StreamFunc[T any] func(ctx context.Context, checkpoint T) (T, error)
+
+// DoStream is like DoWithData, but resumes each retry from the checkpoint the
+// failed attempt returned rather than starting over. The checkpoint can be any
+// progress token, such as a byte offset into a download or the cursor of a
+// paginated listing. On success, DoStream returns the final checkpoint. If the
+// retry loop gives up, it returns the last checkpoint along with the error, so
+// the caller can resume later.
+func DoStream[T any](ctx context.Context, b Backoff, f StreamFunc[T]) (T, error) {
+	var checkpoint T
+
+	_, err := DoWithData(ctx, b, func(ctx context.Context) (struct{}, error) {
+		next, err := f(ctx, checkpoint)
+		checkpoint = next
+		return struct{}{}, err
+	})
+	return checkpoint, err
+}
+
+// ErrStreakNotReached is the underlying error returned by DoWithStreak when the
+// backoff stops after a success but before the streak was reached.
+var ErrStreakNotReached = errors.New("retry: success streak not reached")
+
+// DoWithStreak is like Do, but only returns nil once f has succeeded streak
+// times in a row. This is useful for confirming that a service is genuinely
+// healthy rather than momentarily up. The backoff is applied between all
+// attempts, successful or not, and a retryable error resets the streak. A
+// non-retryable error is returned immediately. A streak of 1 or less behaves
+// like Do.
+func DoWithStreak(ctx context.Context, b Backoff, f RetryFunc, streak int) error {
+	var successes int
+
+	return Do(ctx, b, func(ctx context.Context) error {
+		if err := f(ctx); err != nil {
+			successes = 0
+			return err
+		}
+
+		successes++
+		if successes < streak {
+			return RetryableError(ErrStreakNotReached)
+		}
+		return nil
+	})
+}
+
+// ErrNotDone is the underlying error returned by DoBool when the backoff stops
+// before f reports that it is done.
+var ErrNotDone = errors.New("retry: not done")
+
+// DoBool is like Do, but f also reports whether it is done, which suits poll
+// loops where "not done yet" is not really an error. The combinations of done
+// and err are handled as follows:
+//
+//   - done and a nil error returns nil.
+//   - not done and a nil error retries with the backoff, as if f had returned a
+//     retryable ErrNotDone.
+//   - a retryable error retries with the backoff, unless f is also done.
+//   - a non-retryable error is returned immediately, as with Do.
+//
+// If f reports done along with an error, the error is returned without
+// retrying, even if it is retryable.
+func DoBool(ctx context.Context, b Backoff, f func(ctx context.Context) (done bool, err error)) error {
+	return Do(ctx, b, func(ctx context.Context) error {
+		done, err := f(ctx)
+		switch {
+		case done:
+			return PermanentError(err)
+		case err == nil:
+			return RetryableError(ErrNotDone)
+		default:
+			return err
+		}
+	})
+}
+
+// DoAfter is like Do, but waits for d before the first invocation of f, which is
+// useful for deliberately throttled or scheduled startups. A backoff cannot
+// delay the first attempt, since it is only consulted after a failure. If the
+// context is canceled or its deadline passes during the initial delay, the
+// context's error is returned without invoking f. If b is nil, ErrNilBackoff is
+// returned without waiting.
+func DoAfter(ctx context.Context, d time.Duration, b Backoff, f RetryFunc) error {
+	if b == nil {
+		return ErrNilBackoff
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+	}
+
+	return Do(ctx, b, f)
+}
+
+// startupRand is the source of the delays chosen by DoWithStartupJitter.
+var startupRand = newLockedRandom(time.Now().UnixNano())
+
+// DoWithStartupJitter is like DoAfter, but waits for a uniformly random delay
+// in [0, window] before the first invocation of f. When a whole fleet restarts
+// and starts retrying at once, this spreads the first attempts across the
+// window instead of hammering a downstream in lockstep. A window of 0 or less
+// does not wait. If the context is canceled or its deadline passes during the
+// initial delay, the context's error is returned without invoking f. If b is
+// nil, ErrNilBackoff is returned without waiting.
+func DoWithStartupJitter(ctx context.Context, window time.Duration, b Backoff, f RetryFunc) error {
+	if b == nil {
+		return ErrNilBackoff
+	}
+
+	var d time.Duration
+	if window > 0 {
+		// Saturate rather than overflow for the largest possible window.
+		n := int64(window)
+		if n < math.MaxInt64 {
+			n++
+		}
+		d = time.Duration(startupRand.Int63n(n))
+	}
+	return DoAfter(ctx, d, b, f)
+}
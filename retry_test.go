@@ -5,8 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math/rand"
 	"net/http"
+	"reflect"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -22,6 +25,170 @@ func TestRetryableError(t *testing.T) {
 	}
 }
 
+func TestRetryableErrorf(t *testing.T) {
+	t.Parallel()
+
+	t.Run("formats", func(t *testing.T) {
+		t.Parallel()
+
+		err := retry.RetryableErrorf("not ready: %d/%d", 1, 3)
+		if got, want := err.Error(), "retryable: not ready: 1/3"; got != want {
+			t.Errorf("expected %q to be %q", got, want)
+		}
+		if !retry.IsRetryable(err) {
+			t.Errorf("expected %#v to be retryable", err)
+		}
+	})
+
+	t.Run("wraps", func(t *testing.T) {
+		t.Parallel()
+
+		err := retry.RetryableErrorf("read: %w", io.EOF)
+		if !errors.Is(err, io.EOF) {
+			t.Errorf("expected %#v to be %#v", err, io.EOF)
+		}
+	})
+
+	t.Run("retries", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.WithMaxRetries(2, retry.NewConstant(1*time.Nanosecond))
+
+		var calls int
+		err := retry.Do(ctx, b, func(_ context.Context) error {
+			calls++
+			return retry.RetryableErrorf("not ready")
+		})
+		if !errors.Is(err, retry.ErrBackoffStopped) {
+			t.Errorf("expected %#v to be %#v", err, retry.ErrBackoffStopped)
+		}
+		if got, want := calls, 3; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+func TestRetryableErrorAfter(t *testing.T) {
+	t.Parallel()
+
+	err := retry.RetryableErrorAfter(io.EOF, 5*time.Second)
+	if got, want := err.Error(), "retryable: EOF"; got != want {
+		t.Errorf("expected %q to be %q", got, want)
+	}
+
+	var raerr retry.RetryAfterError
+	if !errors.As(err, &raerr) {
+		t.Fatalf("expected %#v to be a RetryAfterError", err)
+	}
+	if got, want := raerr.RetryAfter(), 5*time.Second; got != want {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+
+	if !errors.Is(err, io.EOF) {
+		t.Errorf("expected %#v to be %#v", err, io.EOF)
+	}
+
+	if retry.RetryableErrorAfter(nil, 5*time.Second) != nil {
+		t.Errorf("expected nil")
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name      string
+		err       error
+		retryable bool
+		inner     error
+	}{
+		{
+			name: "nil",
+			err:  nil,
+		},
+		{
+			name:  "plain",
+			err:   io.EOF,
+			inner: io.EOF,
+		},
+		{
+			name:      "retryable",
+			err:       retry.RetryableError(io.EOF),
+			retryable: true,
+			inner:     io.EOF,
+		},
+		{
+			name:      "wrapped",
+			err:       fmt.Errorf("outer: %w", retry.RetryableError(io.EOF)),
+			retryable: true,
+			inner:     io.EOF,
+		},
+		{
+			name:      "retry_after",
+			err:       retry.RetryableErrorAfter(io.EOF, 1*time.Second),
+			retryable: true,
+			inner:     io.EOF,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got, want := retry.IsRetryable(tc.err), tc.retryable; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+
+			inner, ok := retry.UnwrapRetryable(tc.err)
+			if got, want := ok, tc.retryable; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+			if got, want := inner, tc.inner; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+		})
+	}
+}
+
+func TestPermanentError(t *testing.T) {
+	t.Parallel()
+
+	err := retry.PermanentError(io.EOF)
+	if got, want := err.Error(), "permanent: EOF"; got != want {
+		t.Errorf("expected %q to be %q", got, want)
+	}
+	if !errors.Is(err, io.EOF) {
+		t.Errorf("expected %#v to be %#v", err, io.EOF)
+	}
+	if retry.PermanentError(nil) != nil {
+		t.Errorf("expected nil")
+	}
+}
+
+func TestValidationError(t *testing.T) {
+	t.Parallel()
+
+	err := retry.ValidationError(io.EOF)
+	if got, want := err.Error(), "validation: EOF"; got != want {
+		t.Errorf("expected %q to be %q", got, want)
+	}
+	if !errors.Is(err, io.EOF) {
+		t.Errorf("expected %#v to be %#v", err, io.EOF)
+	}
+	if !retry.IsValidation(fmt.Errorf("wrap: %w", err)) {
+		t.Errorf("expected %v to be a validation error", err)
+	}
+	if retry.IsValidation(io.EOF) {
+		t.Errorf("expected %v not to be a validation error", io.EOF)
+	}
+	if retry.ValidationError(nil) != nil {
+		t.Errorf("expected nil")
+	}
+}
+
 func TestDo(t *testing.T) {
 	t.Parallel()
 
@@ -83,7 +250,7 @@ func TestDo(t *testing.T) {
 			t.Fatal("expected err")
 		}
 
-		if got, want := err, io.EOF; got != want {
+		if got, want := err, io.EOF; !errors.Is(got, want) {
 			t.Errorf("expected %#v to be %#v", got, want)
 		}
 	})
@@ -127,81 +294,3434 @@ func TestDo(t *testing.T) {
 	})
 }
 
-func ExampleDo_simple() {
-	ctx := context.Background()
+func TestDo_retryAfter(t *testing.T) {
+	t.Parallel()
 
-	b := retry.NewFibonacci(1 * time.Nanosecond)
+	t.Run("hint_longer_than_backoff", func(t *testing.T) {
+		t.Parallel()
 
-	i := 0
-	if err := retry.Do(ctx, retry.WithMaxRetries(3, b), func(ctx context.Context) error {
-		fmt.Printf("%d\n", i)
-		i++
-		return retry.RetryableError(fmt.Errorf("oops"))
-	}); err != nil {
-		// handle error
+		ctx := context.Background()
+		b := retry.WithMaxRetries(1, retry.NewConstant(1*time.Nanosecond))
+
+		start := time.Now()
+		if err := retry.Do(ctx, b, func(_ context.Context) error {
+			return retry.RetryableErrorAfter(io.EOF, 50*time.Millisecond)
+		}); !errors.Is(err, io.EOF) {
+			t.Errorf("expected %#v to be %#v", err, io.EOF)
+		}
+
+		if got, min := time.Since(start), 50*time.Millisecond; got < min {
+			t.Errorf("expected %v to be at least %v", got, min)
+		}
+	})
+
+	t.Run("backoff_longer_than_hint", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.WithMaxRetries(1, retry.NewConstant(50*time.Millisecond))
+
+		start := time.Now()
+		if err := retry.Do(ctx, b, func(_ context.Context) error {
+			return retry.RetryableErrorAfter(io.EOF, 1*time.Nanosecond)
+		}); !errors.Is(err, io.EOF) {
+			t.Errorf("expected %#v to be %#v", err, io.EOF)
+		}
+
+		if got, min := time.Since(start), 50*time.Millisecond; got < min {
+			t.Errorf("expected %v to be at least %v", got, min)
+		}
+	})
+}
+
+func TestDo_maxRetries(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		retries uint64
+		calls   int
+	}{
+		{retries: 0, calls: 1},
+		{retries: 1, calls: 2},
+		{retries: 3, calls: 4},
 	}
 
-	// Output:
-	// 0
-	// 1
-	// 2
-	// 3
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(fmt.Sprint(tc.retries), func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			b := retry.WithMaxRetries(tc.retries, retry.NewConstant(1*time.Nanosecond))
+
+			var i int
+			if err := retry.Do(ctx, b, func(_ context.Context) error {
+				i++
+				return retry.RetryableError(fmt.Errorf("oops"))
+			}); err == nil {
+				t.Fatal("expected err")
+			}
+
+			if got, want := i, tc.calls; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+		})
+	}
 }
 
-func ExampleDo_customRetry() {
-	ctx := context.Background()
+func TestDo_backoffStopped(t *testing.T) {
+	t.Parallel()
 
-	b := retry.NewFibonacci(1 * time.Nanosecond)
+	t.Run("backoff_stop", func(t *testing.T) {
+		t.Parallel()
 
-	// This example demonstrates selectively retrying specific errors. Only errors
-	// wrapped with RetryableError are eligible to be retried.
-	if err := retry.Do(ctx, retry.WithMaxRetries(3, b), func(ctx context.Context) error {
-		resp, err := http.Get("https://google.com/")
-		if err != nil {
-			return err
+		ctx := context.Background()
+		b := retry.WithMaxRetries(1, retry.NewConstant(1*time.Nanosecond))
+
+		err := retry.Do(ctx, b, func(_ context.Context) error {
+			return retry.RetryableError(&testError{"oops"})
+		})
+		if !errors.Is(err, retry.ErrBackoffStopped) {
+			t.Errorf("expected %#v to be %#v", err, retry.ErrBackoffStopped)
 		}
-		defer resp.Body.Close()
 
-		switch resp.StatusCode / 100 {
-		case 4:
-			return fmt.Errorf("bad response: %v", resp.StatusCode)
-		case 5:
-			return retry.RetryableError(fmt.Errorf("bad response: %v", resp.StatusCode))
-		default:
-			return nil
+		var terr *testError
+		if !errors.As(err, &terr) {
+			t.Errorf("expected %#v to be a *testError", err)
 		}
-	}); err != nil {
-		// handle error
-	}
+		if got, want := errors.Unwrap(err), error(terr); got != want {
+			t.Errorf("expected %#v to be %#v", got, want)
+		}
+		if got, want := err.Error(), "oops"; got != want {
+			t.Errorf("expected %q to be %q", got, want)
+		}
+	})
+
+	t.Run("non_retryable", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.WithMaxRetries(1, retry.NewConstant(1*time.Nanosecond))
+
+		err := retry.Do(ctx, b, func(_ context.Context) error {
+			return io.EOF
+		})
+		if errors.Is(err, retry.ErrBackoffStopped) {
+			t.Errorf("expected %#v not to be %#v", err, retry.ErrBackoffStopped)
+		}
+	})
 }
 
-func TestCancel(t *testing.T) {
-	for i := 0; i < 100000; i++ {
+type testError struct {
+	msg string
+}
+
+func (e *testError) Error() string {
+	return e.msg
+}
+
+func TestDo_errorChain(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		err  error
+		msg  string
+	}{
+		{
+			name: "non_retryable",
+			err:  fmt.Errorf("annotated: %w", &testError{"oops"}),
+			msg:  "annotated: oops",
+		},
+		{
+			name: "backoff_stop",
+			err:  retry.RetryableError(fmt.Errorf("annotated: %w", &testError{"oops"})),
+			msg:  "annotated: oops",
+		},
+		{
+			name: "backoff_stop_annotated_outside",
+			err:  fmt.Errorf("outer: %w", retry.RetryableError(&testError{"oops"})),
+			msg:  "outer: retryable: oops",
+		},
+		{
+			name: "backoff_stop_retry_after",
+			err:  retry.RetryableErrorAfter(fmt.Errorf("annotated: %w", &testError{"oops"}), 1*time.Nanosecond),
+			msg:  "annotated: oops",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			b := retry.WithMaxRetries(1, retry.NewConstant(1*time.Nanosecond))
+
+			err := retry.Do(ctx, b, func(_ context.Context) error {
+				return tc.err
+			})
+			if err == nil {
+				t.Fatal("expected err")
+			}
+
+			var terr *testError
+			if !errors.As(err, &terr) {
+				t.Fatalf("expected %#v to be a *testError", err)
+			}
+			if got, want := terr.msg, "oops"; got != want {
+				t.Errorf("expected %q to be %q", got, want)
+			}
+
+			if !errors.Is(err, terr) {
+				t.Errorf("expected %#v to be %#v", err, terr)
+			}
+
+			if got, want := err.Error(), tc.msg; got != want {
+				t.Errorf("expected %q to be %q", got, want)
+			}
+		})
+	}
+
+	t.Run("context_canceled", func(t *testing.T) {
+		t.Parallel()
+
 		ctx, cancel := context.WithCancel(context.Background())
+		b := retry.NewConstant(1 * time.Second)
 
-		calls := 0
-		rf := func(ctx context.Context) error {
-			calls++
-			// Never succeed.
-			// Always return a RetryableError
-			return retry.RetryableError(errors.New("nope"))
+		err := retry.Do(ctx, b, func(_ context.Context) error {
+			cancel()
+			return retry.RetryableError(io.EOF)
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected %#v to be %#v", err, context.Canceled)
 		}
+	})
+}
 
-		const delay time.Duration = time.Millisecond
-		b := retry.NewConstant(delay)
+func TestDo_retryMax(t *testing.T) {
+	t.Parallel()
 
-		const maxRetries = 5
-		b = retry.WithMaxRetries(maxRetries, b)
+	cases := []struct {
+		name  string
+		hints []int
+		calls int
+	}{
+		{
+			name:  "zero",
+			hints: []int{0},
+			calls: 1,
+		},
+		{
+			name:  "constant_hint",
+			hints: []int{2, 2, 2, 2, 2},
+			calls: 3,
+		},
+		{
+			name:  "smallest_hint_wins",
+			hints: []int{5, 1, 5, 5, 5},
+			calls: 3,
+		},
+		{
+			name:  "backoff_stops_first",
+			hints: []int{10, 10, 10, 10, 10},
+			calls: 4,
+		},
+	}
 
-		const jitter time.Duration = 5 * time.Millisecond
-		b = retry.WithJitter(jitter, b)
+	for _, tc := range cases {
+		tc := tc
 
-		// Here we cancel the Context *before* the call to Do
-		cancel()
-		retry.Do(ctx, b, rf)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
 
-		if calls > 1 {
-			t.Errorf("rf was called %d times instead of 0 or 1", calls)
+			ctx := context.Background()
+			b := retry.WithMaxRetries(3, retry.NewConstant(1*time.Nanosecond))
+
+			var i int
+			err := retry.Do(ctx, b, func(_ context.Context) error {
+				hint := tc.hints[i]
+				i++
+				return retry.RetryableErrorMax(io.EOF, hint)
+			})
+			if !errors.Is(err, io.EOF) {
+				t.Errorf("expected %#v to be %#v", err, io.EOF)
+			}
+
+			if got, want := i, tc.calls; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+		})
+	}
+}
+
+func TestDo_deadline(t *testing.T) {
+	t.Parallel()
+
+	t.Run("final_attempt_before_deadline", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.NewConstant(5 * time.Second)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		var i int
+		start := time.Now()
+		err := retry.Do(ctx, b, func(_ context.Context) error {
+			i++
+			if i == 2 {
+				return nil
+			}
+			return retry.RetryableError(fmt.Errorf("oops"))
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got, want := i, 2; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, max := time.Since(start), 100*time.Millisecond; got > max {
+			t.Errorf("expected %v to be less than %v", got, max)
+		}
+	})
+
+	t.Run("no_time_for_attempt", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.NewConstant(5 * time.Second)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		var i int
+		err := retry.Do(ctx, b, func(_ context.Context) error {
+			i++
+			time.Sleep(30 * time.Millisecond)
+			return retry.RetryableError(fmt.Errorf("oops"))
+		})
+		if err != context.DeadlineExceeded {
+			t.Errorf("expected %v to be %v", err, context.DeadlineExceeded)
 		}
+
+		if got, want := i, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+func TestDo_permanent(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		err  error
+	}{
+		{
+			name: "permanent",
+			err:  retry.PermanentError(io.EOF),
+		},
+		{
+			name: "permanent_retryable",
+			err:  retry.PermanentError(retry.RetryableError(io.EOF)),
+		},
+		{
+			name: "retryable_permanent",
+			err:  retry.RetryableError(retry.PermanentError(io.EOF)),
+		},
+		{
+			name: "annotated",
+			err:  fmt.Errorf("outer: %w", retry.PermanentError(retry.RetryableError(io.EOF))),
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			b := retry.WithMaxRetries(3, retry.NewConstant(1*time.Nanosecond))
+
+			var i int
+			err := retry.Do(ctx, b, func(_ context.Context) error {
+				i++
+				return tc.err
+			})
+			if !errors.Is(err, io.EOF) {
+				t.Errorf("expected %#v to be %#v", err, io.EOF)
+			}
+			if errors.Is(err, retry.ErrBackoffStopped) {
+				t.Errorf("expected %#v not to be %#v", err, retry.ErrBackoffStopped)
+			}
+
+			if got, want := i, 1; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+		})
 	}
+
+	t.Run("unwraps", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.NewConstant(1 * time.Nanosecond)
+
+		err := retry.Do(ctx, b, func(_ context.Context) error {
+			return retry.PermanentError(retry.RetryableError(io.EOF))
+		})
+		if got, want := errors.Unwrap(err), io.EOF; got != want {
+			t.Errorf("expected %#v to be %#v", got, want)
+		}
+	})
+}
+
+func TestDo_validation(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		err  error
+	}{
+		{
+			name: "validation",
+			err:  retry.ValidationError(io.EOF),
+		},
+		{
+			name: "retryable_validation",
+			err:  retry.RetryableError(retry.ValidationError(io.EOF)),
+		},
+		{
+			name: "validation_retryable",
+			err:  retry.ValidationError(retry.RetryableError(io.EOF)),
+		},
+		{
+			name: "annotated",
+			err:  retry.RetryableError(fmt.Errorf("outer: %w", retry.ValidationError(io.EOF))),
+		},
+		{
+			name: "permanent_validation",
+			err:  retry.PermanentError(retry.ValidationError(io.EOF)),
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			b := retry.WithMaxRetries(3, retry.NewConstant(1*time.Nanosecond))
+
+			var i int
+			err := retry.Do(ctx, b, func(_ context.Context) error {
+				i++
+				return tc.err
+			})
+			if !errors.Is(err, io.EOF) {
+				t.Errorf("expected %#v to be %#v", err, io.EOF)
+			}
+			if !retry.IsValidation(err) {
+				t.Errorf("expected %v to be a validation error", err)
+			}
+
+			if got, want := i, 1; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+		})
+	}
+
+	t.Run("keeps_marker", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.NewConstant(1 * time.Nanosecond)
+
+		// The outer retryable marker is removed, and the validation one kept.
+		err := retry.Do(ctx, b, func(_ context.Context) error {
+			return retry.RetryableError(retry.ValidationError(io.EOF))
+		})
+		if got, want := errors.Unwrap(err).Error(), "validation: EOF"; got != want {
+			t.Errorf("expected %q to be %q", got, want)
+		}
+		if retry.IsRetryable(err) {
+			t.Errorf("expected %v not to be retryable", err)
+		}
+	})
+
+	t.Run("forever", func(t *testing.T) {
+		t.Parallel()
+
+		var i int
+		err := retry.DoForever(context.Background(), retry.NewConstant(1*time.Nanosecond), func(_ context.Context) error {
+			i++
+			return retry.ValidationError(io.EOF)
+		})
+		if !retry.IsValidation(err) {
+			t.Errorf("expected %v to be a validation error", err)
+		}
+		if got, want := i, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+func TestDo_zeroDelay(t *testing.T) {
+	t.Parallel()
+
+	t.Run("retries", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.WithMaxRetries(3, retry.BackoffFunc(func() (time.Duration, bool) {
+			return 0, false
+		}))
+
+		var i int
+		err := retry.Do(ctx, b, func(_ context.Context) error {
+			i++
+			return retry.RetryableError(io.EOF)
+		})
+		if !errors.Is(err, retry.ErrBackoffStopped) {
+			t.Errorf("expected %#v to be %#v", err, retry.ErrBackoffStopped)
+		}
+		if got, want := i, 4; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("context_canceled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		b := retry.BackoffFunc(func() (time.Duration, bool) {
+			return 0, false
+		})
+
+		var i int
+		err := retry.Do(ctx, b, func(_ context.Context) error {
+			i++
+			cancel()
+			return retry.RetryableError(io.EOF)
+		})
+		if got, want := err, context.Canceled; got != want {
+			t.Errorf("expected %#v to be %#v", got, want)
+		}
+		if got, want := i, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+func TestDo_backoffHooks(t *testing.T) {
+	t.Parallel()
+
+	// Variants that intercept calls to Next must still forward the error, the
+	// deadline, and the attempt limit to the backoff they wrap.
+	cases := []struct {
+		name string
+		do   func(ctx context.Context, b retry.Backoff, f retry.RetryFunc) error
+	}{
+		{
+			name: "Do",
+			do:   retry.Do,
+		},
+		{
+			name: "DoWithResult",
+			do: func(ctx context.Context, b retry.Backoff, f retry.RetryFunc) error {
+				_, _, err := retry.DoWithResult(ctx, b, func(ctx context.Context) (struct{}, error) {
+					return struct{}{}, f(ctx)
+				})
+				return err
+			},
+		},
+		{
+			name: "DoWithNotify",
+			do: func(ctx context.Context, b retry.Backoff, f retry.RetryFunc) error {
+				return retry.DoWithNotify(ctx, b, f, func(int, error, time.Duration) {})
+			},
+		},
+		{
+			name: "DoWithFirstRetry",
+			do: func(ctx context.Context, b retry.Backoff, f retry.RetryFunc) error {
+				return retry.DoWithFirstRetry(ctx, b, f, func(error) {})
+			},
+		},
+		{
+			name: "DoWithCoalescedNotify",
+			do: func(ctx context.Context, b retry.Backoff, f retry.RetryFunc) error {
+				return retry.DoWithCoalescedNotify(ctx, b, f, func(error, int, time.Duration) {}, nil)
+			},
+		},
+		{
+			name: "DoWithCleanup",
+			do: func(ctx context.Context, b retry.Backoff, f retry.RetryFunc) error {
+				return retry.DoWithCleanup(ctx, b, f, func(context.Context, error) {})
+			},
+		},
+		{
+			name: "DoWithMetrics",
+			do: func(ctx context.Context, b retry.Backoff, f retry.RetryFunc) error {
+				return retry.DoWithMetrics(ctx, b, f, nil)
+			},
+		},
+		{
+			name: "DoWithBudget",
+			do: func(ctx context.Context, b retry.Backoff, f retry.RetryFunc) error {
+				return retry.DoWithBudget(ctx, b, retry.NewBudget(10, 10), f)
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			t.Run("error", func(t *testing.T) {
+				t.Parallel()
+
+				b := retry.WithMaxRetriesByError(map[error]int{io.EOF: 1}, 5, retry.NewConstant(1*time.Nanosecond))
+
+				var calls int
+				_ = tc.do(context.Background(), b, func(_ context.Context) error {
+					calls++
+					return retry.RetryableError(io.EOF)
+				})
+				if got, want := calls, 2; got != want {
+					t.Errorf("expected %v to be %v", got, want)
+				}
+			})
+
+			t.Run("deadline_and_limit", func(t *testing.T) {
+				t.Parallel()
+
+				ctx, cancel := context.WithTimeout(context.Background(), 1*time.Minute)
+				defer cancel()
+
+				var got []retry.Progress
+				ctx = retry.WithProgress(ctx, func(p retry.Progress) {
+					got = append(got, p)
+				})
+
+				// A millionth of the remaining minute is at most 60µs.
+				b := retry.WithMaxRetries(1, retry.WithDeadlineFraction(1e-6, retry.NewConstant(1*time.Hour)))
+				_ = tc.do(ctx, b, func(_ context.Context) error {
+					return retry.RetryableError(io.EOF)
+				})
+
+				if len(got) != 1 {
+					t.Fatalf("expected %v to have 1 entry", got)
+				}
+				if got, want := got[0].MaxAttempts, 2; got != want {
+					t.Errorf("expected %v to be %v", got, want)
+				}
+				if d := got[0].NextDelay; d > 60*time.Microsecond {
+					t.Errorf("expected %v to be at most %v", d, 60*time.Microsecond)
+				}
+			})
+		})
+	}
+}
+
+func TestDo_nilBackoff(t *testing.T) {
+	t.Parallel()
+
+	// Variants that wrap the backoff must check for nil themselves, since the
+	// wrapper they pass to the retry loop never is.
+	cases := []struct {
+		name string
+		do   func(ctx context.Context, f retry.RetryFunc) error
+	}{
+		{
+			name: "Do",
+			do: func(ctx context.Context, f retry.RetryFunc) error {
+				return retry.Do(ctx, nil, f)
+			},
+		},
+		{
+			name: "DoWithResult",
+			do: func(ctx context.Context, f retry.RetryFunc) error {
+				_, _, err := retry.DoWithResult(ctx, nil, func(ctx context.Context) (struct{}, error) {
+					return struct{}{}, f(ctx)
+				})
+				return err
+			},
+		},
+		{
+			name: "DoWithNotify",
+			do: func(ctx context.Context, f retry.RetryFunc) error {
+				return retry.DoWithNotify(ctx, nil, f, func(int, error, time.Duration) {})
+			},
+		},
+		{
+			name: "DoWithFirstRetry",
+			do: func(ctx context.Context, f retry.RetryFunc) error {
+				return retry.DoWithFirstRetry(ctx, nil, f, func(error) {})
+			},
+		},
+		{
+			name: "DoWithCoalescedNotify",
+			do: func(ctx context.Context, f retry.RetryFunc) error {
+				return retry.DoWithCoalescedNotify(ctx, nil, f, func(error, int, time.Duration) {}, nil)
+			},
+		},
+		{
+			name: "DoWithCleanup",
+			do: func(ctx context.Context, f retry.RetryFunc) error {
+				return retry.DoWithCleanup(ctx, nil, f, func(context.Context, error) {})
+			},
+		},
+		{
+			name: "DoWithMetrics",
+			do: func(ctx context.Context, f retry.RetryFunc) error {
+				return retry.DoWithMetrics(ctx, nil, f, nil)
+			},
+		},
+		{
+			name: "DoWithBudget",
+			do: func(ctx context.Context, f retry.RetryFunc) error {
+				return retry.DoWithBudget(ctx, nil, retry.NewBudget(1, 1), f)
+			},
+		},
+		{
+			name: "DoAfter",
+			do: func(ctx context.Context, f retry.RetryFunc) error {
+				return retry.DoAfter(ctx, 1*time.Hour, nil, f)
+			},
+		},
+		{
+			name: "DoWithStartupJitter",
+			do: func(ctx context.Context, f retry.RetryFunc) error {
+				return retry.DoWithStartupJitter(ctx, 1*time.Hour, nil, f)
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var calls int
+			err := tc.do(context.Background(), func(_ context.Context) error {
+				calls++
+				return retry.RetryableError(io.EOF)
+			})
+			if got, want := err, retry.ErrNilBackoff; got != want {
+				t.Errorf("expected %#v to be %#v", got, want)
+			}
+			if got, want := calls, 0; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+		})
+	}
+}
+
+func TestDo_attemptsError(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name     string
+		err      func(attempt int) error
+		attempts int
+	}{
+		{
+			name: "non_retryable",
+			err: func(attempt int) error {
+				if attempt < 2 {
+					return retry.RetryableError(io.EOF)
+				}
+				return io.EOF
+			},
+			attempts: 2,
+		},
+		{
+			name: "permanent",
+			err: func(attempt int) error {
+				return retry.PermanentError(io.EOF)
+			},
+			attempts: 1,
+		},
+		{
+			name: "backoff_stopped",
+			err: func(attempt int) error {
+				return retry.RetryableError(io.EOF)
+			},
+			attempts: 4,
+		},
+		{
+			name: "retry_max",
+			err: func(attempt int) error {
+				return retry.RetryableErrorMax(io.EOF, 1)
+			},
+			attempts: 2,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			b := retry.WithMaxRetries(3, retry.NewConstant(1*time.Nanosecond))
+
+			var attempt int
+			err := retry.Do(ctx, b, func(_ context.Context) error {
+				attempt++
+				return tc.err(attempt)
+			})
+
+			var aerr retry.AttemptsError
+			if !errors.As(err, &aerr) {
+				t.Fatalf("expected %#v to be an AttemptsError", err)
+			}
+			if got, want := aerr.Attempts(), tc.attempts; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+			if !errors.Is(err, io.EOF) {
+				t.Errorf("expected %#v to be %#v", err, io.EOF)
+			}
+			if got, want := err.Error(), "EOF"; got != want {
+				t.Errorf("expected %q to be %q", got, want)
+			}
+		})
+	}
+
+	t.Run("context_canceled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := retry.Do(ctx, retry.NewConstant(1*time.Nanosecond), func(_ context.Context) error {
+			return nil
+		})
+
+		var aerr retry.AttemptsError
+		if errors.As(err, &aerr) {
+			t.Errorf("expected %#v not to be an AttemptsError", err)
+		}
+	})
+}
+
+func TestDoSimple(t *testing.T) {
+	t.Parallel()
+
+	b := retry.NewConstant(1 * time.Nanosecond)
+
+	var i int
+	if err := retry.DoSimple(b, func() error {
+		i++
+		if i < 3 {
+			return retry.RetryableError(fmt.Errorf("oops"))
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := i, 3; got != want {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+}
+
+func TestDoSimpleWithData(t *testing.T) {
+	t.Parallel()
+
+	b := retry.NewConstant(1 * time.Nanosecond)
+
+	var i int
+	val, err := retry.DoSimpleWithData(b, func() (string, error) {
+		i++
+		if i < 2 {
+			return "", retry.RetryableError(fmt.Errorf("oops"))
+		}
+		return "ok", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := val, "ok"; got != want {
+		t.Errorf("expected %q to be %q", got, want)
+	}
+}
+
+func TestDoWithData(t *testing.T) {
+	t.Parallel()
+
+	t.Run("returns_value", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.WithMaxRetries(3, retry.BackoffFunc(func() (time.Duration, bool) {
+			return 1 * time.Nanosecond, false
+		}))
+
+		var i int
+		val, err := retry.DoWithData(ctx, b, func(_ context.Context) (int, error) {
+			i++
+			if i < 3 {
+				return 0, retry.RetryableError(fmt.Errorf("oops"))
+			}
+			return 42, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got, want := val, 42; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("zero_value_on_error", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.WithMaxRetries(1, retry.BackoffFunc(func() (time.Duration, bool) {
+			return 1 * time.Nanosecond, false
+		}))
+
+		val, err := retry.DoWithData(ctx, b, func(_ context.Context) (string, error) {
+			return "partial", retry.RetryableError(io.EOF)
+		})
+		if got, want := err, io.EOF; !errors.Is(got, want) {
+			t.Errorf("expected %#v to be %#v", got, want)
+		}
+		if got, want := val, ""; got != want {
+			t.Errorf("expected %q to be %q", got, want)
+		}
+	})
+}
+
+func TestDoWithOutcome(t *testing.T) {
+	t.Parallel()
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	cases := []struct {
+		name string
+		ctx  context.Context
+		err  error
+		exp  retry.Outcome
+	}{
+		{
+			name: "success",
+			ctx:  context.Background(),
+			exp:  retry.OutcomeSuccess,
+		},
+		{
+			name: "non_retryable",
+			ctx:  context.Background(),
+			err:  io.EOF,
+			exp:  retry.OutcomeNonRetryable,
+		},
+		{
+			name: "permanent",
+			ctx:  context.Background(),
+			err:  retry.PermanentError(retry.RetryableError(io.EOF)),
+			exp:  retry.OutcomeNonRetryable,
+		},
+		{
+			name: "backoff_exhausted",
+			ctx:  context.Background(),
+			err:  retry.RetryableError(io.EOF),
+			exp:  retry.OutcomeBackoffExhausted,
+		},
+		{
+			name: "context_canceled",
+			ctx:  canceled,
+			err:  retry.RetryableError(io.EOF),
+			exp:  retry.OutcomeContextCanceled,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			b := retry.WithMaxRetries(1, retry.NewConstant(1*time.Nanosecond))
+			outcome, err := retry.DoWithOutcome(tc.ctx, b, func(_ context.Context) error {
+				return tc.err
+			})
+			if got, want := outcome, tc.exp; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+
+			if (err == nil) != (tc.exp == retry.OutcomeSuccess) {
+				t.Errorf("expected outcome %v to be consistent with %v", outcome, err)
+			}
+		})
+	}
+}
+
+func TestDoWithTrace(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.NewConstant(1 * time.Millisecond)
+
+		var i int
+		trace, err := retry.DoWithTrace(ctx, b, func(_ context.Context) error {
+			i++
+			if i < 3 {
+				return retry.RetryableError(io.EOF)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got, want := trace.Delays, []time.Duration{1 * time.Millisecond, 1 * time.Millisecond}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := trace.Errors, []error{io.EOF, io.EOF, nil}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("backoff_stopped", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		var n time.Duration
+		b := retry.WithMaxRetries(2, retry.BackoffFunc(func() (time.Duration, bool) {
+			n++
+			return n * time.Millisecond, false
+		}))
+
+		trace, err := retry.DoWithTrace(ctx, b, func(_ context.Context) error {
+			return retry.RetryableError(io.EOF)
+		})
+		if !errors.Is(err, retry.ErrBackoffStopped) {
+			t.Errorf("expected %#v to be %#v", err, retry.ErrBackoffStopped)
+		}
+
+		if got, want := trace.Delays, []time.Duration{1 * time.Millisecond, 2 * time.Millisecond}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := trace.Errors, []error{io.EOF, io.EOF, io.EOF}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+func TestDoWithTimeline(t *testing.T) {
+	t.Parallel()
+
+	t.Run("gives_up", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.WithMaxRetries(2, retry.NewConstant(20*time.Millisecond))
+
+		var i int
+		_, err := retry.DoWithTimeline(ctx, b, func(_ context.Context) (int, error) {
+			i++
+			return 0, retry.RetryableError(fmt.Errorf("err%d", i))
+		})
+
+		var terr *retry.TimelineError
+		if !errors.As(err, &terr) {
+			t.Fatalf("expected %#v to be a TimelineError", err)
+		}
+		if !errors.Is(err, retry.ErrBackoffStopped) {
+			t.Errorf("expected %#v to be %#v", err, retry.ErrBackoffStopped)
+		}
+		if got, want := errors.Unwrap(errors.Unwrap(err)).Error(), "err3"; got != want {
+			t.Errorf("expected %q to be %q", got, want)
+		}
+
+		timeline := terr.Timeline()
+		if got, want := len(timeline), 3; got != want {
+			t.Fatalf("expected %v to be %v", got, want)
+		}
+		for j, entry := range timeline {
+			if got, want := entry.Err.Error(), fmt.Sprintf("err%d", j+1); got != want {
+				t.Errorf("expected %q to be %q", got, want)
+			}
+			if min := time.Duration(j) * 20 * time.Millisecond; entry.At < min {
+				t.Errorf("expected %v to be at least %v", entry.At, min)
+			}
+		}
+
+		msg := err.Error()
+		for _, want := range []string{"[t+0ms] err1; [t+", "ms] err2; [t+", "ms] err3"} {
+			if !strings.Contains(msg, want) {
+				t.Errorf("expected %q to contain %q", msg, want)
+			}
+		}
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.NewConstant(1 * time.Nanosecond)
+
+		if _, err := retry.DoWithTimeline(ctx, b, func(_ context.Context) (int, error) {
+			return 1, nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestDoAtLeastOnce(t *testing.T) {
+	t.Parallel()
+
+	t.Run("canceled_success", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		var calls int
+		val, err := retry.DoAtLeastOnce(ctx, retry.NewConstant(1*time.Nanosecond), func(_ context.Context) (int, error) {
+			calls++
+			return 1, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := val, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := calls, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("canceled_failure", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		var calls int
+		_, err := retry.DoAtLeastOnce(ctx, retry.NewConstant(1*time.Nanosecond), func(_ context.Context) (int, error) {
+			calls++
+			return 0, retry.RetryableError(io.EOF)
+		})
+		if got, want := err, context.Canceled; got != want {
+			t.Errorf("expected %#v to be %#v", got, want)
+		}
+		if got, want := calls, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("retries", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.WithMaxRetries(2, retry.NewConstant(1*time.Nanosecond))
+
+		var calls int
+		_, err := retry.DoAtLeastOnce(ctx, b, func(_ context.Context) (int, error) {
+			calls++
+			return 0, retry.RetryableError(io.EOF)
+		})
+		if !errors.Is(err, retry.ErrBackoffStopped) {
+			t.Errorf("expected %#v to be %#v", err, retry.ErrBackoffStopped)
+		}
+		if got, want := calls, 3; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+func TestDoWithDelayObserver(t *testing.T) {
+	t.Parallel()
+
+	t.Run("observes_each_sleep", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.WithMaxRetries(3, retry.BackoffFunc(func() (time.Duration, bool) {
+			return 1 * time.Millisecond, false
+		}))
+
+		var delays []time.Duration
+		_, err := retry.DoWithDelayObserver(ctx, b, func(_ context.Context) (int, error) {
+			return 0, retry.RetryableError(fmt.Errorf("oops"))
+		}, func(d time.Duration) {
+			delays = append(delays, d)
+		})
+		if !errors.Is(err, retry.ErrBackoffStopped) {
+			t.Errorf("expected %#v to be %#v", err, retry.ErrBackoffStopped)
+		}
+
+		if got, want := delays, []time.Duration{1 * time.Millisecond, 1 * time.Millisecond, 1 * time.Millisecond}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("retry_after", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.NewConstant(1 * time.Nanosecond)
+
+		var i int
+		var delays []time.Duration
+		if _, err := retry.DoWithDelayObserver(ctx, b, func(_ context.Context) (int, error) {
+			i++
+			if i == 1 {
+				return 0, retry.RetryableErrorAfter(fmt.Errorf("oops"), 5*time.Millisecond)
+			}
+			return i, nil
+		}, func(d time.Duration) {
+			delays = append(delays, d)
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		if got, want := delays, []time.Duration{5 * time.Millisecond}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("deadline_clamp", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		b := retry.NewConstant(1 * time.Second)
+
+		var i int
+		var delays []time.Duration
+		if _, err := retry.DoWithDelayObserver(ctx, b, func(_ context.Context) (int, error) {
+			i++
+			if i == 1 {
+				return 0, retry.RetryableError(fmt.Errorf("oops"))
+			}
+			return i, nil
+		}, func(d time.Duration) {
+			delays = append(delays, d)
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		if got, want := len(delays), 1; got != want {
+			t.Fatalf("expected %v to be %v", got, want)
+		}
+		if got, max := delays[0], 100*time.Millisecond; got >= max {
+			t.Errorf("expected %v to be less than %v", got, max)
+		}
+	})
+}
+
+func TestDoWithResult(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.WithMaxRetries(3, retry.BackoffFunc(func() (time.Duration, bool) {
+			return 1 * time.Millisecond, false
+		}))
+
+		var i int
+		val, result, err := retry.DoWithResult(ctx, b, func(_ context.Context) (int, error) {
+			i++
+			if i < 2 {
+				return 0, retry.RetryableError(fmt.Errorf("oops"))
+			}
+			return i, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got, want := val, 2; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := result.Attempts, 2; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := result.LastBackoff, 1*time.Millisecond; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if min := 1 * time.Millisecond; result.TotalElapsed < min {
+			t.Errorf("expected %v to be at least %v", result.TotalElapsed, min)
+		}
+	})
+
+	t.Run("backoff_stop", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.WithMaxRetries(3, retry.BackoffFunc(func() (time.Duration, bool) {
+			return 1 * time.Nanosecond, false
+		}))
+
+		_, result, err := retry.DoWithResult(ctx, b, func(_ context.Context) (int, error) {
+			return 0, retry.RetryableError(fmt.Errorf("oops"))
+		})
+		if err == nil {
+			t.Fatal("expected err")
+		}
+
+		if got, want := result.Attempts, 4; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := result.LastBackoff, 1*time.Nanosecond; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("non_retryable", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.WithMaxRetries(3, retry.BackoffFunc(func() (time.Duration, bool) {
+			return 1 * time.Nanosecond, false
+		}))
+
+		_, result, err := retry.DoWithResult(ctx, b, func(_ context.Context) (int, error) {
+			return 0, fmt.Errorf("oops")
+		})
+		if err == nil {
+			t.Fatal("expected err")
+		}
+
+		if got, want := result.Attempts, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := result.LastBackoff, time.Duration(0); got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("context_canceled", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.BackoffFunc(func() (time.Duration, bool) {
+			return 5 * time.Second, false
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, result, err := retry.DoWithResult(ctx, b, func(_ context.Context) (int, error) {
+			return 0, retry.RetryableError(fmt.Errorf("oops"))
+		})
+		if err != context.DeadlineExceeded {
+			t.Errorf("expected %v to be %v", err, context.DeadlineExceeded)
+		}
+
+		// The sleep is shortened to fit a final attempt before the deadline
+		if got, min := result.Attempts, 2; got < min {
+			t.Errorf("expected %v to be at least %v", got, min)
+		}
+		if got, want := result.LastBackoff, 5*time.Second; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if min := 50 * time.Millisecond; result.TotalElapsed < min {
+			t.Errorf("expected %v to be at least %v", result.TotalElapsed, min)
+		}
+	})
+}
+
+func TestDoWithFirstRetry(t *testing.T) {
+	t.Parallel()
+
+	t.Run("fires_once", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.WithMaxRetries(10, retry.NewConstant(1*time.Nanosecond))
+
+		var attempts int
+		var got []error
+		err := retry.DoWithFirstRetry(context.Background(), b, func(_ context.Context) error {
+			attempts++
+			if attempts == 1 {
+				return retry.RetryableError(io.ErrUnexpectedEOF)
+			}
+			return retry.RetryableError(io.EOF)
+		}, func(err error) {
+			got = append(got, err)
+		})
+		if !errors.Is(err, io.EOF) {
+			t.Errorf("expected %v to be %v", err, io.EOF)
+		}
+
+		if got, want := attempts, 11; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if want := []error{io.ErrUnexpectedEOF}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("no_retry", func(t *testing.T) {
+		t.Parallel()
+
+		var calls int
+		for _, f := range []retry.RetryFunc{
+			func(_ context.Context) error { return nil },
+			func(_ context.Context) error { return io.EOF },
+		} {
+			_ = retry.DoWithFirstRetry(context.Background(), retry.NewConstant(1*time.Nanosecond), f, func(_ error) {
+				calls++
+			})
+		}
+		if got, want := calls, 0; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+func TestDoWithCoalescedNotify(t *testing.T) {
+	t.Parallel()
+
+	t.Run("repeat_count", func(t *testing.T) {
+		t.Parallel()
+
+		errs := []error{
+			io.EOF,
+			io.EOF,
+			errors.New("EOF"), // same message, so the same by default
+			io.ErrUnexpectedEOF,
+			io.ErrUnexpectedEOF,
+			io.EOF,
+		}
+
+		var i int
+		var got []string
+		if err := retry.DoWithCoalescedNotify(context.Background(), retry.NewConstant(1*time.Nanosecond), func(_ context.Context) error {
+			if i == len(errs) {
+				return nil
+			}
+			err := errs[i]
+			i++
+			return retry.RetryableError(err)
+		}, func(err error, repeatCount int, _ time.Duration) {
+			got = append(got, fmt.Sprintf("%v x%d", err, repeatCount))
+		}, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		want := []string{
+			"EOF x1",
+			"EOF x2",
+			"EOF x3",
+			"unexpected EOF x1",
+			"unexpected EOF x2",
+			"EOF x1",
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %q to be %q", got, want)
+		}
+	})
+
+	t.Run("custom_equal", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.WithMaxRetries(3, retry.NewConstant(1*time.Nanosecond))
+
+		var i int
+		var counts []int
+		_ = retry.DoWithCoalescedNotify(context.Background(), b, func(_ context.Context) error {
+			i++
+			return retry.RetryableError(fmt.Errorf("timeout after %dms", i))
+		}, func(_ error, repeatCount int, _ time.Duration) {
+			counts = append(counts, repeatCount)
+		}, func(a, b error) bool {
+			return strings.HasPrefix(a.Error(), "timeout") && strings.HasPrefix(b.Error(), "timeout")
+		})
+
+		if got, want := counts, []int{1, 2, 3}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+func TestDoWithNotify(t *testing.T) {
+	t.Parallel()
+
+	t.Run("notifies_between_attempts", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.WithMaxRetries(2, retry.BackoffFunc(func() (time.Duration, bool) {
+			return 1 * time.Nanosecond, false
+		}))
+
+		var attempts []int
+		if err := retry.DoWithNotify(ctx, b, func(_ context.Context) error {
+			return retry.RetryableError(io.EOF)
+		}, func(attempt int, err error, next time.Duration) {
+			if got, want := err, io.EOF; got != want {
+				t.Errorf("expected %#v to be %#v", got, want)
+			}
+			if got, want := next, 1*time.Nanosecond; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+			attempts = append(attempts, attempt)
+		}); err == nil {
+			t.Fatal("expected err")
+		}
+
+		// 3 attempts, but the final failure is not notified
+		if got, want := fmt.Sprint(attempts), "[1 2]"; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("not_called_on_non_retryable", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.WithMaxRetries(2, retry.BackoffFunc(func() (time.Duration, bool) {
+			return 1 * time.Nanosecond, false
+		}))
+
+		var calls int
+		if err := retry.DoWithNotify(ctx, b, func(_ context.Context) error {
+			return fmt.Errorf("oops")
+		}, func(_ int, _ error, _ time.Duration) {
+			calls++
+		}); err == nil {
+			t.Fatal("expected err")
+		}
+
+		if got, want := calls, 0; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+func TestDoWithCleanup(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		succeed int
+		err     error
+		calls   int
+		cleanup int
+	}{
+		{
+			name:    "success_first",
+			succeed: 1,
+			calls:   1,
+			cleanup: 0,
+		},
+		{
+			name:    "success_after_retries",
+			succeed: 3,
+			calls:   3,
+			cleanup: 2,
+		},
+		{
+			name:    "gives_up",
+			calls:   4,
+			cleanup: 3,
+		},
+		{
+			name:    "non_retryable",
+			err:     io.EOF,
+			calls:   1,
+			cleanup: 0,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			b := retry.WithMaxRetries(3, retry.NewConstant(1*time.Nanosecond))
+
+			var calls int
+			var cleaned []error
+			_ = retry.DoWithCleanup(ctx, b, func(_ context.Context) error {
+				calls++
+				if calls == tc.succeed {
+					return nil
+				}
+				if tc.err != nil {
+					return tc.err
+				}
+				return retry.RetryableError(fmt.Errorf("attempt %d", calls))
+			}, func(_ context.Context, err error) {
+				cleaned = append(cleaned, err)
+			})
+
+			if got, want := calls, tc.calls; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+			if got, want := len(cleaned), tc.cleanup; got != want {
+				t.Fatalf("expected %v to be %v", got, want)
+			}
+			for i, err := range cleaned {
+				if got, want := err.Error(), fmt.Sprintf("attempt %d", i+1); got != want {
+					t.Errorf("expected %q to be %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDoWithClassifier(t *testing.T) {
+	t.Parallel()
+
+	isEOF := func(err error) bool {
+		return errors.Is(err, io.EOF)
+	}
+
+	cases := []struct {
+		name  string
+		err   error
+		calls int
+	}{
+		{
+			name:  "classified_retryable",
+			err:   io.EOF,
+			calls: 4,
+		},
+		{
+			name:  "classified_not_retryable",
+			err:   io.ErrUnexpectedEOF,
+			calls: 1,
+		},
+		{
+			name:  "explicit_wrap_wins",
+			err:   retry.RetryableError(io.ErrUnexpectedEOF),
+			calls: 4,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			b := retry.WithMaxRetries(3, retry.BackoffFunc(func() (time.Duration, bool) {
+				return 1 * time.Nanosecond, false
+			}))
+
+			var i int
+			if err := retry.DoWithClassifier(ctx, b, func(_ context.Context) error {
+				i++
+				return tc.err
+			}, isEOF); err == nil {
+				t.Fatal("expected err")
+			}
+
+			if got, want := i, tc.calls; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+		})
+	}
+
+	t.Run("nil_error", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.WithMaxRetries(3, retry.NewConstant(1*time.Nanosecond))
+
+		if err := retry.DoWithClassifier(ctx, b, func(_ context.Context) error {
+			return nil
+		}, isEOF); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestDoWithTransform(t *testing.T) {
+	t.Parallel()
+
+	errUnavailable := errors.New("unavailable")
+	errIgnorable := errors.New("ignorable")
+
+	transform := func(err error) error {
+		switch {
+		case errors.Is(err, errUnavailable):
+			return retry.RetryableError(err)
+		case errors.Is(err, errIgnorable):
+			return nil
+		default:
+			return err
+		}
+	}
+
+	t.Run("retryable", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.NewConstant(1 * time.Nanosecond)
+
+		var i int
+		val, err := retry.DoWithTransform(ctx, b, func(_ context.Context) (int, error) {
+			i++
+			if i < 3 {
+				return 0, errUnavailable
+			}
+			return i, nil
+		}, transform)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := val, 3; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.NewConstant(1 * time.Nanosecond)
+
+		val, err := retry.DoWithTransform(ctx, b, func(_ context.Context) (int, error) {
+			return 7, errIgnorable
+		}, transform)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := val, 7; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("non_retryable", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.NewConstant(1 * time.Nanosecond)
+
+		var i int
+		_, err := retry.DoWithTransform(ctx, b, func(_ context.Context) (int, error) {
+			i++
+			return 0, io.EOF
+		}, transform)
+		if !errors.Is(err, io.EOF) {
+			t.Errorf("expected %#v to be %#v", err, io.EOF)
+		}
+		if got, want := i, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("not_called_on_success", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.NewConstant(1 * time.Nanosecond)
+
+		if _, err := retry.DoWithTransform(ctx, b, func(_ context.Context) (int, error) {
+			return 1, nil
+		}, func(err error) error {
+			t.Errorf("expected transform not to be called")
+			return err
+		}); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestDoWithAttemptContext(t *testing.T) {
+	t.Parallel()
+
+	type requestIDKey struct{}
+
+	t.Run("modifies", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.WithMaxRetries(2, retry.NewConstant(1*time.Nanosecond))
+
+		var seen []int
+		var ids []string
+		_, err := retry.DoWithAttemptContext(ctx, b, func(ctx context.Context) (int, error) {
+			ids = append(ids, ctx.Value(requestIDKey{}).(string))
+			return 0, retry.RetryableError(io.EOF)
+		}, func(ctx context.Context, attempt int) context.Context {
+			seen = append(seen, attempt)
+			return context.WithValue(ctx, requestIDKey{}, fmt.Sprintf("req-%d", attempt))
+		})
+		if !errors.Is(err, retry.ErrBackoffStopped) {
+			t.Errorf("expected %#v to be %#v", err, retry.ErrBackoffStopped)
+		}
+
+		if got, want := seen, []int{1, 2, 3}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := ids, []string{"req-1", "req-2", "req-3"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.NewConstant(1 * time.Nanosecond)
+
+		val, err := retry.DoWithAttemptContext(ctx, b, func(ctx context.Context) (int, error) {
+			return retry.AttemptFromContext(ctx), nil
+		}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := val, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+func TestDoWithNonRetryable(t *testing.T) {
+	t.Parallel()
+
+	errFatal := errors.New("fatal")
+
+	cases := []struct {
+		name  string
+		err   error
+		calls int
+	}{
+		{
+			name:  "sentinel",
+			err:   errFatal,
+			calls: 1,
+		},
+		{
+			name:  "overrides_retryable",
+			err:   retry.RetryableError(errFatal),
+			calls: 1,
+		},
+		{
+			name:  "wrap_chain",
+			err:   retry.RetryableError(fmt.Errorf("query: %w", errFatal)),
+			calls: 1,
+		},
+		{
+			name:  "other_sentinel",
+			err:   retry.RetryableError(io.ErrUnexpectedEOF),
+			calls: 1,
+		},
+		{
+			name:  "unmatched_retryable",
+			err:   retry.RetryableError(io.EOF),
+			calls: 3,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			b := retry.WithMaxRetries(2, retry.NewConstant(1*time.Nanosecond))
+
+			var calls int
+			_, err := retry.DoWithNonRetryable(ctx, b, func(_ context.Context) (int, error) {
+				calls++
+				return 0, tc.err
+			}, errFatal, io.ErrUnexpectedEOF)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+
+			if got, want := calls, tc.calls; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+			if tc.calls == 1 && retry.IsRetryable(err) {
+				t.Errorf("expected %v not to be retryable", err)
+			}
+		})
+	}
+}
+
+func TestDoWithMaxCalls(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name     string
+		maxCalls int
+		succeed  int
+		calls    int
+		err      error
+	}{
+		{
+			name:     "limited",
+			maxCalls: 3,
+			calls:    3,
+			err:      retry.ErrMaxCalls,
+		},
+		{
+			name:     "single",
+			maxCalls: 1,
+			calls:    1,
+			err:      retry.ErrMaxCalls,
+		},
+		{
+			name:     "succeeds_at_limit",
+			maxCalls: 3,
+			succeed:  3,
+			calls:    3,
+		},
+		{
+			name:     "backoff_first",
+			maxCalls: 10,
+			calls:    6,
+			err:      retry.ErrBackoffStopped,
+		},
+		{
+			name:     "unlimited",
+			maxCalls: 0,
+			calls:    6,
+			err:      retry.ErrBackoffStopped,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			b := retry.WithMaxRetries(5, retry.NewConstant(1*time.Nanosecond))
+
+			var calls int
+			_, err := retry.DoWithMaxCalls(ctx, b, tc.maxCalls, func(_ context.Context) (int, error) {
+				calls++
+				if calls == tc.succeed {
+					return calls, nil
+				}
+				return 0, retry.RetryableError(io.EOF)
+			})
+
+			if tc.err == nil {
+				if err != nil {
+					t.Fatal(err)
+				}
+			} else {
+				if !errors.Is(err, tc.err) {
+					t.Errorf("expected %#v to be %#v", err, tc.err)
+				}
+				if !errors.Is(err, io.EOF) {
+					t.Errorf("expected %#v to be %#v", err, io.EOF)
+				}
+			}
+
+			if got, want := calls, tc.calls; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+		})
+	}
+}
+
+func TestDoWithFinalAttempt(t *testing.T) {
+	t.Parallel()
+
+	const maxRetries = 3
+
+	t.Run("final_error", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.WithMaxRetries(maxRetries, retry.NewConstant(1*time.Nanosecond))
+
+		var calls int
+		var attempts []int
+		_, err := retry.DoWithFinalAttempt(ctx, b, func(ctx context.Context) (int, error) {
+			calls++
+			attempts = append(attempts, retry.AttemptFromContext(ctx))
+			if calls > maxRetries+1 {
+				return 0, retry.RetryableError(io.ErrUnexpectedEOF)
+			}
+			return 0, retry.RetryableError(io.EOF)
+		})
+		if got, want := err, io.ErrUnexpectedEOF; got != want {
+			t.Errorf("expected %#v to be %#v", got, want)
+		}
+
+		if got, want := calls, maxRetries+2; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := attempts, []int{1, 2, 3, 4, 5}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("final_success", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.WithMaxRetries(maxRetries, retry.NewConstant(1*time.Nanosecond))
+
+		var calls int
+		val, err := retry.DoWithFinalAttempt(ctx, b, func(_ context.Context) (int, error) {
+			calls++
+			if calls > maxRetries+1 {
+				return calls, nil
+			}
+			return 0, retry.RetryableError(io.EOF)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got, want := val, maxRetries+2; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("non_retryable", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.WithMaxRetries(maxRetries, retry.NewConstant(1*time.Nanosecond))
+
+		var calls int
+		_, err := retry.DoWithFinalAttempt(ctx, b, func(_ context.Context) (int, error) {
+			calls++
+			return 0, io.EOF
+		})
+		if !errors.Is(err, io.EOF) {
+			t.Errorf("expected %#v to be %#v", err, io.EOF)
+		}
+
+		if got, want := calls, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+func TestDoWithLastData(t *testing.T) {
+	t.Parallel()
+
+	t.Run("context_canceled", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.NewConstant(1 * time.Millisecond)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var i int
+		val, err := retry.DoWithLastData(ctx, b, func(_ context.Context) (int, error) {
+			i++
+			if i == 3 {
+				cancel()
+			}
+			return i, retry.RetryableError(fmt.Errorf("oops"))
+		})
+		if err != context.Canceled {
+			t.Errorf("expected %v to be %v", err, context.Canceled)
+		}
+
+		if got, want := val, 3; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("never_invoked", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.NewConstant(1 * time.Millisecond)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		val, err := retry.DoWithLastData(ctx, b, func(_ context.Context) (string, error) {
+			return "never", nil
+		})
+		if err != context.Canceled {
+			t.Errorf("expected %v to be %v", err, context.Canceled)
+		}
+
+		if got, want := val, ""; got != want {
+			t.Errorf("expected %q to be %q", got, want)
+		}
+	})
+
+	t.Run("backoff_stop", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.WithMaxRetries(1, retry.NewConstant(1*time.Nanosecond))
+
+		val, err := retry.DoWithLastData(ctx, b, func(_ context.Context) (int, error) {
+			return 5, retry.RetryableError(io.EOF)
+		})
+		if got, want := err, io.EOF; !errors.Is(got, want) {
+			t.Errorf("expected %#v to be %#v", got, want)
+		}
+
+		if got, want := val, 0; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+func TestDoWithAttemptTimeout(t *testing.T) {
+	t.Parallel()
+
+	t.Run("retries_timed_out_attempt", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.WithMaxRetries(3, retry.NewConstant(1*time.Nanosecond))
+
+		var i int
+		if err := retry.DoWithAttemptTimeout(ctx, b, 10*time.Millisecond, func(ctx context.Context) error {
+			i++
+			if i < 3 {
+				// Simulate a stuck attempt
+				<-ctx.Done()
+				return ctx.Err()
+			}
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		if got, want := i, 3; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("parent_canceled", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.NewConstant(1 * time.Nanosecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		var i int
+		err := retry.DoWithAttemptTimeout(ctx, b, 1*time.Second, func(ctx context.Context) error {
+			i++
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected %v to be %v", err, context.DeadlineExceeded)
+		}
+
+		if got, want := i, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+func TestDoWithDataStop(t *testing.T) {
+	t.Parallel()
+
+	t.Run("stopped_during_sleep", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.NewConstant(5 * time.Second)
+
+		stop := make(chan struct{})
+		time.AfterFunc(50*time.Millisecond, func() { close(stop) })
+
+		var calls int
+		_, err := retry.DoWithDataStop(ctx, b, stop, func(_ context.Context) (int, error) {
+			calls++
+			return 0, retry.RetryableError(fmt.Errorf("oops"))
+		})
+		if err != retry.ErrStopped {
+			t.Errorf("expected %v to be %v", err, retry.ErrStopped)
+		}
+		if got, want := calls, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("already_stopped", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.NewConstant(1 * time.Nanosecond)
+
+		stop := make(chan struct{})
+		close(stop)
+
+		var calls int
+		_, err := retry.DoWithDataStop(ctx, b, stop, func(_ context.Context) (int, error) {
+			calls++
+			return 0, nil
+		})
+		if err != retry.ErrStopped {
+			t.Errorf("expected %v to be %v", err, retry.ErrStopped)
+		}
+		if got, want := calls, 0; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("context_canceled", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.NewConstant(5 * time.Second)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := retry.DoWithDataStop(ctx, b, make(chan struct{}), func(_ context.Context) (int, error) {
+			return 0, retry.RetryableError(fmt.Errorf("oops"))
+		})
+		if err != context.DeadlineExceeded {
+			t.Errorf("expected %v to be %v", err, context.DeadlineExceeded)
+		}
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.NewConstant(1 * time.Nanosecond)
+
+		val, err := retry.DoWithDataStop(ctx, b, make(chan struct{}), func(_ context.Context) (int, error) {
+			return 42, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := val, 42; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+func TestDoUntilSignal(t *testing.T) {
+	t.Parallel()
+
+	t.Run("signaled_during_sleep", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.NewConstant(5 * time.Second)
+
+		done := make(chan struct{})
+		time.AfterFunc(50*time.Millisecond, func() { close(done) })
+
+		var calls int
+		if err := retry.DoUntilSignal(ctx, b, func(_ context.Context) error {
+			calls++
+			return retry.RetryableError(fmt.Errorf("oops"))
+		}, done); err != nil {
+			t.Errorf("expected %v to be nil", err)
+		}
+		if got, want := calls, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("already_signaled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.NewConstant(1 * time.Nanosecond)
+
+		done := make(chan struct{})
+		close(done)
+
+		var calls int
+		if err := retry.DoUntilSignal(ctx, b, func(_ context.Context) error {
+			calls++
+			return nil
+		}, done); err != nil {
+			t.Errorf("expected %v to be nil", err)
+		}
+		if got, want := calls, 0; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("prefers_cancellation", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		done := make(chan struct{})
+		close(done)
+
+		err := retry.DoUntilSignal(ctx, retry.NewConstant(1*time.Nanosecond), func(_ context.Context) error {
+			return nil
+		}, done)
+		if got, want := err, context.Canceled; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("non_retryable", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.NewConstant(1 * time.Nanosecond)
+
+		err := retry.DoUntilSignal(ctx, b, func(_ context.Context) error {
+			return io.EOF
+		}, make(chan struct{}))
+		if !errors.Is(err, io.EOF) {
+			t.Errorf("expected %v to be %v", err, io.EOF)
+		}
+	})
+}
+
+func TestDoWithDeadlineAsError(t *testing.T) {
+	t.Parallel()
+
+	t.Run("deadline_exceeded", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		b := retry.NewConstant(1 * time.Millisecond)
+
+		_, err := retry.DoWithDeadlineAsError(ctx, b, func(_ context.Context) (int, error) {
+			return 0, retry.RetryableError(fmt.Errorf("oops"))
+		})
+		if !errors.Is(err, retry.ErrDeadlineExceeded) {
+			t.Errorf("expected %v to be %v", err, retry.ErrDeadlineExceeded)
+		}
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected %v to be %v", err, context.DeadlineExceeded)
+		}
+
+		// An outer loop retries it.
+		var outer int
+		_ = retry.Do(context.Background(), retry.WithMaxRetries(1, retry.NewConstant(1*time.Nanosecond)), func(_ context.Context) error {
+			outer++
+			return err
+		})
+		if got, want := outer, 2; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		b := retry.NewConstant(1 * time.Millisecond)
+
+		_, err := retry.DoWithDeadlineAsError(ctx, b, func(_ context.Context) (int, error) {
+			cancel()
+			return 0, retry.RetryableError(fmt.Errorf("oops"))
+		})
+		if got, want := err, context.Canceled; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if errors.Is(err, retry.ErrDeadlineExceeded) {
+			t.Errorf("expected %v not to be %v", err, retry.ErrDeadlineExceeded)
+		}
+	})
+
+	t.Run("attempt_deadline", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.NewConstant(1 * time.Millisecond)
+
+		// A deadline from inside f is not the loop's deadline.
+		_, err := retry.DoWithDeadlineAsError(ctx, b, func(_ context.Context) (int, error) {
+			return 0, context.DeadlineExceeded
+		})
+		if errors.Is(err, retry.ErrDeadlineExceeded) {
+			t.Errorf("expected %v not to be %v", err, retry.ErrDeadlineExceeded)
+		}
+	})
+}
+
+func TestDoWithDataTimeout(t *testing.T) {
+	t.Parallel()
+
+	t.Run("timeout", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.NewConstant(5 * time.Second)
+
+		_, err := retry.DoWithDataTimeout(context.Background(), 50*time.Millisecond, b, func(_ context.Context) (int, error) {
+			return 0, retry.RetryableError(fmt.Errorf("oops"))
+		})
+		if err != context.DeadlineExceeded {
+			t.Errorf("expected %v to be %v", err, context.DeadlineExceeded)
+		}
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.NewConstant(1 * time.Nanosecond)
+
+		val, err := retry.DoWithDataTimeout(context.Background(), 1*time.Second, b, func(ctx context.Context) (bool, error) {
+			_, ok := ctx.Deadline()
+			return ok, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !val {
+			t.Errorf("expected context to have a deadline")
+		}
+	})
+}
+
+func TestDoUntil(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ready", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.NewConstant(1 * time.Nanosecond)
+
+		var i int
+		val, err := retry.DoUntil(ctx, b, func(_ context.Context) (string, error) {
+			i++
+			if i < 3 {
+				return "pending", nil
+			}
+			return "done", nil
+		}, func(val string) bool {
+			return val == "done"
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got, want := val, "done"; got != want {
+			t.Errorf("expected %q to be %q", got, want)
+		}
+		if got, want := i, 3; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("retryable_error", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.NewConstant(1 * time.Nanosecond)
+
+		var i int
+		val, err := retry.DoUntil(ctx, b, func(_ context.Context) (int, error) {
+			i++
+			switch i {
+			case 1:
+				return 0, retry.RetryableError(fmt.Errorf("oops"))
+			case 2:
+				return 1, nil
+			default:
+				return 2, nil
+			}
+		}, func(val int) bool {
+			return val == 2
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got, want := val, 2; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("non_retryable_error", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.NewConstant(1 * time.Nanosecond)
+
+		_, err := retry.DoUntil(ctx, b, func(_ context.Context) (int, error) {
+			return 0, io.EOF
+		}, func(val int) bool {
+			t.Errorf("expected ready not to be called")
+			return false
+		})
+		if !errors.Is(err, io.EOF) {
+			t.Errorf("expected %v to be %v", err, io.EOF)
+		}
+	})
+
+	t.Run("never_ready", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.WithMaxRetries(2, retry.NewConstant(1*time.Nanosecond))
+
+		var i int
+		_, err := retry.DoUntil(ctx, b, func(_ context.Context) (string, error) {
+			i++
+			return "pending", nil
+		}, func(val string) bool {
+			return false
+		})
+		if !errors.Is(err, retry.ErrNotReady) {
+			t.Errorf("expected %#v to be %#v", err, retry.ErrNotReady)
+		}
+		if !errors.Is(err, retry.ErrBackoffStopped) {
+			t.Errorf("expected %#v to be %#v", err, retry.ErrBackoffStopped)
+		}
+		if got, want := i, 3; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+type resetSpy struct {
+	b      retry.Backoff
+	delays []time.Duration
+	resets int
+}
+
+func (s *resetSpy) Next() (time.Duration, bool) {
+	val, stop := s.b.Next()
+	s.delays = append(s.delays, val)
+	return val, stop
+}
+
+func (s *resetSpy) Reset() {
+	s.resets++
+	s.b.(retry.Resettable).Reset()
+}
+
+func TestDoForever(t *testing.T) {
+	t.Parallel()
+
+	t.Run("resets_after_success", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		linear, err := retry.NewLinear(1 * time.Nanosecond)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b := &resetSpy{b: linear}
+
+		var i int
+		err = retry.DoForever(ctx, b, func(_ context.Context) error {
+			i++
+			switch i {
+			case 1, 2, 4:
+				return fmt.Errorf("dropped")
+			case 3:
+				return nil
+			default:
+				cancel()
+				return nil
+			}
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected %#v to be %#v", err, context.Canceled)
+		}
+
+		if got, want := b.delays, []time.Duration{1, 2, 1}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := b.resets, 2; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("permanent", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.NewConstant(1 * time.Nanosecond)
+
+		var i int
+		err := retry.DoForever(ctx, b, func(_ context.Context) error {
+			i++
+			if i < 3 {
+				return retry.RetryableError(fmt.Errorf("oops"))
+			}
+			return retry.PermanentError(io.EOF)
+		})
+		if got, want := errors.Unwrap(err), io.EOF; got != want {
+			t.Errorf("expected %#v to be %#v", got, want)
+		}
+		if got, want := i, 3; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("backoff_stopped", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.WithMaxRetries(2, retry.NewConstant(1*time.Nanosecond))
+
+		err := retry.DoForever(ctx, b, func(_ context.Context) error {
+			return io.EOF
+		})
+		if !errors.Is(err, retry.ErrBackoffStopped) {
+			t.Errorf("expected %#v to be %#v", err, retry.ErrBackoffStopped)
+		}
+		if got, want := errors.Unwrap(err), io.EOF; got != want {
+			t.Errorf("expected %#v to be %#v", got, want)
+		}
+	})
+}
+
+func TestDoCollectErrors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.NewConstant(1 * time.Nanosecond)
+
+		var i int
+		val, err := retry.DoCollectErrors(ctx, b, func(_ context.Context) (int, error) {
+			i++
+			if i < 3 {
+				return 0, retry.RetryableError(fmt.Errorf("oops"))
+			}
+			return i, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := val, 3; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("backoff_stopped", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.WithMaxRetries(2, retry.NewConstant(1*time.Nanosecond))
+
+		errs := []error{
+			fmt.Errorf("first"),
+			fmt.Errorf("second"),
+			fmt.Errorf("third"),
+		}
+
+		var i int
+		_, err := retry.DoCollectErrors(ctx, b, func(_ context.Context) (int, error) {
+			i++
+			return 0, retry.RetryableError(errs[i-1])
+		})
+		for _, e := range errs {
+			if !errors.Is(err, e) {
+				t.Errorf("expected %#v to be %#v", err, e)
+			}
+		}
+		if !errors.Is(err, retry.ErrBackoffStopped) {
+			t.Errorf("expected %#v to be %#v", err, retry.ErrBackoffStopped)
+		}
+		if got, want := err.Error(), "first\nsecond\nthird"; got != want {
+			t.Errorf("expected %q to be %q", got, want)
+		}
+	})
+
+	t.Run("non_retryable", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.NewConstant(1 * time.Nanosecond)
+
+		var i int
+		_, err := retry.DoCollectErrors(ctx, b, func(_ context.Context) (int, error) {
+			i++
+			if i == 1 {
+				return 0, retry.RetryableError(fmt.Errorf("oops"))
+			}
+			return 0, io.EOF
+		})
+		if !errors.Is(err, io.EOF) {
+			t.Errorf("expected %#v to be %#v", err, io.EOF)
+		}
+		if got, want := err.Error(), "oops\nEOF"; got != want {
+			t.Errorf("expected %q to be %q", got, want)
+		}
+	})
+
+	t.Run("single", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.NewConstant(1 * time.Nanosecond)
+
+		_, err := retry.DoCollectErrors(ctx, b, func(_ context.Context) (int, error) {
+			return 0, io.EOF
+		})
+		if got, want := errors.Unwrap(err), io.EOF; got != want {
+			t.Errorf("expected %#v to be %#v", got, want)
+		}
+	})
+
+	t.Run("context_canceled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		b := retry.NewConstant(1 * time.Nanosecond)
+
+		_, err := retry.DoCollectErrors(ctx, b, func(_ context.Context) (int, error) {
+			cancel()
+			return 0, retry.RetryableError(io.EOF)
+		})
+		if !errors.Is(err, io.EOF) {
+			t.Errorf("expected %#v to be %#v", err, io.EOF)
+		}
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected %#v to be %#v", err, context.Canceled)
+		}
+	})
+}
+
+func TestDoSuccessLog(t *testing.T) {
+	t.Parallel()
+
+	t.Run("first_try", func(t *testing.T) {
+		t.Parallel()
+
+		val, errs, err := retry.DoSuccessLog(context.Background(), retry.NewConstant(1*time.Nanosecond), func(_ context.Context) (int, error) {
+			return 1, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := val, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if errs != nil {
+			t.Errorf("expected %v to be nil", errs)
+		}
+	})
+
+	t.Run("after_retries", func(t *testing.T) {
+		t.Parallel()
+
+		attempt := []error{io.EOF, io.ErrUnexpectedEOF}
+
+		var calls int
+		val, errs, err := retry.DoSuccessLog(context.Background(), retry.NewConstant(1*time.Nanosecond), func(_ context.Context) (int, error) {
+			calls++
+			if calls <= len(attempt) {
+				return 0, retry.RetryableError(attempt[calls-1])
+			}
+			return calls, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := val, 3; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if !reflect.DeepEqual(errs, attempt) {
+			t.Errorf("expected %v to be %v", errs, attempt)
+		}
+	})
+
+	t.Run("gives_up", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.WithMaxRetries(2, retry.NewConstant(1*time.Nanosecond))
+		_, errs, err := retry.DoSuccessLog(context.Background(), b, func(_ context.Context) (int, error) {
+			return 0, retry.RetryableError(io.EOF)
+		})
+		if !errors.Is(err, retry.ErrBackoffStopped) {
+			t.Errorf("expected %v to be %v", err, retry.ErrBackoffStopped)
+		}
+		if got, want := len(errs), 3; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+func TestDoStream(t *testing.T) {
+	t.Parallel()
+
+	t.Run("resumes", func(t *testing.T) {
+		t.Parallel()
+
+		// The first two attempts copy three bytes each before failing.
+		data := []byte("hello, world")
+
+		var seen []int
+		offset, err := retry.DoStream(context.Background(), retry.NewConstant(1*time.Nanosecond), func(_ context.Context, offset int) (int, error) {
+			seen = append(seen, offset)
+			if len(seen) <= 2 {
+				return offset + 3, retry.RetryableError(io.ErrUnexpectedEOF)
+			}
+			return len(data), nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := offset, len(data); got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := seen, []int{0, 3, 6}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("gives_up", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.WithMaxRetries(2, retry.NewConstant(1*time.Nanosecond))
+		offset, err := retry.DoStream(context.Background(), b, func(_ context.Context, offset int) (int, error) {
+			return offset + 1, retry.RetryableError(io.EOF)
+		})
+		if !errors.Is(err, io.EOF) {
+			t.Errorf("expected %v to be %v", err, io.EOF)
+		}
+		if got, want := offset, 3; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+func TestDoWithStreak(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		results []error
+		streak  int
+		calls   int
+		err     error
+	}{
+		{
+			name:    "streak",
+			results: []error{nil, nil, nil},
+			streak:  3,
+			calls:   3,
+		},
+		{
+			name: "retryable_resets",
+			results: []error{
+				nil,
+				retry.RetryableError(io.EOF),
+				nil,
+				nil,
+			},
+			streak: 2,
+			calls:  4,
+		},
+		{
+			name:    "non_retryable",
+			results: []error{nil, io.EOF},
+			streak:  3,
+			calls:   2,
+			err:     io.EOF,
+		},
+		{
+			name:    "single",
+			results: []error{nil},
+			streak:  0,
+			calls:   1,
+		},
+		{
+			name:    "backoff_stopped",
+			results: []error{nil, nil, nil, nil, nil},
+			streak:  5,
+			calls:   4,
+			err:     retry.ErrStreakNotReached,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			b := retry.WithMaxRetries(3, retry.NewConstant(1*time.Nanosecond))
+
+			var i int
+			err := retry.DoWithStreak(ctx, b, func(_ context.Context) error {
+				i++
+				return tc.results[i-1]
+			}, tc.streak)
+			if tc.err == nil && err != nil {
+				t.Fatal(err)
+			}
+			if !errors.Is(err, tc.err) {
+				t.Errorf("expected %#v to be %#v", err, tc.err)
+			}
+
+			if got, want := i, tc.calls; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+		})
+	}
+}
+
+func TestDoBool(t *testing.T) {
+	t.Parallel()
+
+	type result struct {
+		done bool
+		err  error
+	}
+
+	cases := []struct {
+		name    string
+		results []result
+		calls   int
+		err     error
+	}{
+		{
+			name:    "done",
+			results: []result{{true, nil}},
+			calls:   1,
+		},
+		{
+			name:    "not_done_retries",
+			results: []result{{false, nil}, {false, nil}, {true, nil}},
+			calls:   3,
+		},
+		{
+			name:    "retryable_retries",
+			results: []result{{false, retry.RetryableError(io.EOF)}, {true, nil}},
+			calls:   2,
+		},
+		{
+			name:    "non_retryable",
+			results: []result{{false, nil}, {false, io.EOF}},
+			calls:   2,
+			err:     io.EOF,
+		},
+		{
+			name:    "done_with_error",
+			results: []result{{true, retry.RetryableError(io.EOF)}},
+			calls:   1,
+			err:     io.EOF,
+		},
+		{
+			name:    "backoff_stopped",
+			results: []result{{false, nil}, {false, nil}, {false, nil}, {false, nil}},
+			calls:   4,
+			err:     retry.ErrNotDone,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			b := retry.WithMaxRetries(3, retry.NewConstant(1*time.Nanosecond))
+
+			var i int
+			err := retry.DoBool(ctx, b, func(_ context.Context) (bool, error) {
+				i++
+				return tc.results[i-1].done, tc.results[i-1].err
+			})
+			if tc.err == nil && err != nil {
+				t.Fatal(err)
+			}
+			if !errors.Is(err, tc.err) {
+				t.Errorf("expected %#v to be %#v", err, tc.err)
+			}
+
+			if got, want := i, tc.calls; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+		})
+	}
+}
+
+func TestDoWithStartupJitter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("within_window", func(t *testing.T) {
+		t.Parallel()
+
+		const window = 20 * time.Millisecond
+		for i := 0; i < 5; i++ {
+			start := time.Now()
+			var elapsed time.Duration
+			if err := retry.DoWithStartupJitter(context.Background(), window, retry.NewConstant(1*time.Nanosecond), func(_ context.Context) error {
+				elapsed = time.Since(start)
+				return nil
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			// Allow for timer and scheduling slack above the window.
+			if max := window + 50*time.Millisecond; elapsed > max {
+				t.Errorf("expected %v to be at most %v", elapsed, max)
+			}
+		}
+	})
+
+	t.Run("no_window", func(t *testing.T) {
+		t.Parallel()
+
+		var calls int
+		if err := retry.DoWithStartupJitter(context.Background(), 0, retry.NewConstant(1*time.Nanosecond), func(_ context.Context) error {
+			calls++
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := calls, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		var calls int
+		err := retry.DoWithStartupJitter(ctx, 1*time.Hour, retry.NewConstant(1*time.Nanosecond), func(_ context.Context) error {
+			calls++
+			return nil
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected %v to be %v", err, context.Canceled)
+		}
+		if got, want := calls, 0; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+func TestDoWithMinInterval(t *testing.T) {
+	t.Parallel()
+
+	t.Run("zero_backoff", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		// Without a floor, this backoff busy-loops through attempts.
+		b := retry.BackoffFunc(func() (time.Duration, bool) {
+			return 0, false
+		})
+
+		var calls int
+		_, err := retry.DoWithMinInterval(ctx, b, 5*time.Millisecond, func(_ context.Context) (int, error) {
+			calls++
+			return 0, retry.RetryableError(io.EOF)
+		})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected %v to be %v", err, context.DeadlineExceeded)
+		}
+
+		// About 10 attempts fit in the window, far from the millions a busy
+		// loop would manage.
+		if max := 12; calls > max {
+			t.Errorf("expected %v to be at most %v", calls, max)
+		}
+	})
+
+	t.Run("longer_delays", func(t *testing.T) {
+		t.Parallel()
+
+		// Delays above the floor are left alone.
+		b := retry.WithMaxRetries(1, retry.NewConstant(20*time.Millisecond))
+
+		start := time.Now()
+		_, _ = retry.DoWithMinInterval(context.Background(), b, 1*time.Millisecond, func(_ context.Context) (int, error) {
+			return 0, retry.RetryableError(io.EOF)
+		})
+		if elapsed, min := time.Since(start), 20*time.Millisecond; elapsed < min {
+			t.Errorf("expected %v to be at least %v", elapsed, min)
+		}
+	})
+}
+
+func TestDoAfter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("delays_first_attempt", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.NewConstant(1 * time.Nanosecond)
+
+		start := time.Now()
+		var elapsed time.Duration
+		if err := retry.DoAfter(ctx, 20*time.Millisecond, b, func(_ context.Context) error {
+			elapsed = time.Since(start)
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		if min := 20 * time.Millisecond; elapsed < min {
+			t.Errorf("expected %v to be at least %v", elapsed, min)
+		}
+	})
+
+	t.Run("deadline_during_delay", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		b := retry.NewConstant(1 * time.Nanosecond)
+
+		var calls int
+		err := retry.DoAfter(ctx, 1*time.Second, b, func(_ context.Context) error {
+			calls++
+			return nil
+		})
+		if got, want := err, context.DeadlineExceeded; got != want {
+			t.Errorf("expected %#v to be %#v", got, want)
+		}
+		if got, want := calls, 0; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+func BenchmarkDoSuccess(b *testing.B) {
+	ctx := context.Background()
+	backoff := retry.NewConstant(1 * time.Second)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = retry.Do(ctx, backoff, func(_ context.Context) error {
+			return nil
+		})
+	}
+}
+
+func BenchmarkDoWithDataSuccess(b *testing.B) {
+	ctx := context.Background()
+	backoff := retry.NewConstant(1 * time.Second)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, _ = retry.DoWithData(ctx, backoff, func(_ context.Context) (int, error) {
+			return 0, nil
+		})
+	}
+}
+
+func TestDo_allocs(t *testing.T) {
+	ctx := context.Background()
+	backoff := retry.NewConstant(1 * time.Second)
+
+	// Do shares the loop with DoWithData without boxing a value into an
+	// interface, so it costs no extra allocations on the success path.
+	do := testing.AllocsPerRun(100, func() {
+		_ = retry.Do(ctx, backoff, func(_ context.Context) error {
+			return nil
+		})
+	})
+	withData := testing.AllocsPerRun(100, func() {
+		_, _ = retry.DoWithData(ctx, backoff, func(_ context.Context) (int, error) {
+			return 0, nil
+		})
+	})
+	if do > withData {
+		t.Errorf("expected Do to allocate at most %v times, got %v", withData, do)
+	}
+}
+
+func BenchmarkDoWithData(b *testing.B) {
+	ctx := context.Background()
+	err := retry.RetryableError(fmt.Errorf("oops"))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		backoff := retry.WithMaxRetries(10, retry.NewConstant(1*time.Nanosecond))
+		_, _ = retry.DoWithData(ctx, backoff, func(_ context.Context) (int, error) {
+			return 0, err
+		})
+	}
+}
+
+func BenchmarkDoWithData_zeroDelay(b *testing.B) {
+	ctx := context.Background()
+	err := retry.RetryableError(fmt.Errorf("oops"))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		backoff := retry.WithMaxRetries(10, retry.BackoffFunc(func() (time.Duration, bool) {
+			return 0, false
+		}))
+		_, _ = retry.DoWithData(ctx, backoff, func(_ context.Context) (int, error) {
+			return 0, err
+		})
+	}
+}
+
+func TestWrap(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	var calls int
+	f := retry.Wrap(retry.WithMaxRetries(1, retry.NewConstant(1*time.Nanosecond)), func(_ context.Context) (int, error) {
+		calls++
+		if calls%2 == 1 {
+			return 0, retry.RetryableError(io.EOF)
+		}
+		return calls, nil
+	})
+
+	// Each call gets a fresh clone, so the retry used by the first call does not
+	// count against the second.
+	for i, want := range []int{2, 4} {
+		val, err := f(ctx)
+		if err != nil {
+			t.Fatalf("call %d: %v", i, err)
+		}
+		if got := val; got != want {
+			t.Errorf("call %d: expected %v to be %v", i, got, want)
+		}
+	}
+}
+
+type userRepository struct {
+	calls int
+}
+
+func (r *userRepository) CountUsers(_ context.Context) (int, error) {
+	r.calls++
+	if r.calls < 3 {
+		return 0, retry.RetryableError(fmt.Errorf("database unavailable"))
+	}
+	return 42, nil
+}
+
+func ExampleWrap() {
+	ctx := context.Background()
+
+	repo := &userRepository{}
+	b := retry.WithMaxRetries(3, retry.NewConstant(1*time.Nanosecond))
+
+	// countUsers has the same signature as repo.CountUsers, but retries.
+	countUsers := retry.Wrap(b, repo.CountUsers)
+
+	n, err := countUsers(ctx)
+	if err != nil {
+		// handle error
+	}
+	fmt.Println(n)
+
+	// Output:
+	// 42
+}
+
+func ExampleDo_simple() {
+	ctx := context.Background()
+
+	b := retry.NewFibonacci(1 * time.Nanosecond)
+
+	i := 0
+	if err := retry.Do(ctx, retry.WithMaxRetries(3, b), func(ctx context.Context) error {
+		fmt.Printf("%d\n", i)
+		i++
+		return retry.RetryableError(fmt.Errorf("oops"))
+	}); err != nil {
+		// handle error
+	}
+
+	// Output:
+	// 0
+	// 1
+	// 2
+	// 3
+}
+
+func ExampleDo_customRetry() {
+	ctx := context.Background()
+
+	b := retry.NewFibonacci(1 * time.Nanosecond)
+
+	// This example demonstrates selectively retrying specific errors. Only errors
+	// wrapped with RetryableError are eligible to be retried.
+	if err := retry.Do(ctx, retry.WithMaxRetries(3, b), func(ctx context.Context) error {
+		resp, err := http.Get("https://google.com/")
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		switch resp.StatusCode / 100 {
+		case 4:
+			return fmt.Errorf("bad response: %v", resp.StatusCode)
+		case 5:
+			return retry.RetryableError(fmt.Errorf("bad response: %v", resp.StatusCode))
+		default:
+			return nil
+		}
+	}); err != nil {
+		// handle error
+	}
+}
+
+func TestCancel(t *testing.T) {
+	for i := 0; i < 100000; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		calls := 0
+		rf := func(ctx context.Context) error {
+			calls++
+			// Never succeed.
+			// Always return a RetryableError
+			return retry.RetryableError(errors.New("nope"))
+		}
+
+		const delay time.Duration = time.Millisecond
+		b := retry.NewConstant(delay)
+
+		const maxRetries = 5
+		b = retry.WithMaxRetries(maxRetries, b)
+
+		const jitter time.Duration = 5 * time.Millisecond
+		b = retry.WithJitter(jitter, b)
+
+		// Here we cancel the Context *before* the call to Do
+		cancel()
+		retry.Do(ctx, b, rf)
+
+		if calls > 1 {
+			t.Errorf("rf was called %d times instead of 0 or 1", calls)
+		}
+	}
+}
+
+func TestCancel_stress(t *testing.T) {
+	t.Parallel()
+
+	t.Run("from_within", func(t *testing.T) {
+		t.Parallel()
+
+		r := rand.New(rand.NewSource(time.Now().UnixNano()))
+		for i := 0; i < 1_000; i++ {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancelAt := r.Intn(5) + 1
+
+			var calls int
+			b := retry.NewConstant(time.Duration(r.Intn(50)+1) * time.Microsecond)
+			_ = retry.Do(ctx, b, func(_ context.Context) error {
+				calls++
+				if calls == cancelAt {
+					cancel()
+				}
+				return retry.RetryableError(io.EOF)
+			})
+			cancel()
+
+			// Once ctx is done, f must never be invoked again.
+			if calls != cancelAt {
+				t.Fatalf("iteration %d: expected %d calls, got %d", i, cancelAt, calls)
+			}
+		}
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		t.Parallel()
+
+		// This relies on real timers, which are too unreliable under load.
+		if testing.Short() {
+			t.Skip("skipping timing-sensitive test in short mode")
+		}
+
+		r := rand.New(rand.NewSource(time.Now().UnixNano()))
+		var delays, waits []time.Duration
+		for i := 0; i < 200; i++ {
+			delays = append(delays, time.Duration(r.Intn(2000)+1)*time.Microsecond)
+			waits = append(waits, time.Duration(r.Intn(5000))*time.Microsecond)
+		}
+
+		for i := range delays {
+			ctx, cancel := context.WithCancel(context.Background())
+
+			var (
+				l        sync.Mutex
+				canceled bool
+				after    int
+			)
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				_ = retry.Do(ctx, retry.NewConstant(delays[i]), func(_ context.Context) error {
+					l.Lock()
+					if canceled {
+						after++
+					}
+					l.Unlock()
+					return retry.RetryableError(io.EOF)
+				})
+			}()
+
+			time.Sleep(waits[i])
+			cancel()
+			l.Lock()
+			canceled = true
+			l.Unlock()
+			start := time.Now()
+
+			select {
+			case <-done:
+			case <-time.After(1 * time.Second):
+				t.Fatalf("iteration %d: Do did not return after cancellation", i)
+			}
+
+			// The loop must return promptly rather than finishing its sleep.
+			if got, max := time.Since(start), 250*time.Millisecond; got > max {
+				t.Errorf("iteration %d: returned %v after cancellation, expected at most %v", i, got, max)
+			}
+
+			// A call that passed the context check just before cancel may still
+			// start, but no call may follow it.
+			if after > 1 {
+				t.Errorf("iteration %d: expected at most 1 call after cancellation, got %d", i, after)
+			}
+		}
+	})
 }
@@ -0,0 +1,7 @@
+module github.com/sethvargo/go-retry/retrycenkalti
+
+go 1.20
+
+require github.com/sethvargo/go-retry v0.3.0
+
+require github.com/cenkalti/backoff/v4 v4.3.0
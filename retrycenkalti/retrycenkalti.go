@@ -0,0 +1,48 @@
+// Package retrycenkalti adapts backoffs from github.com/cenkalti/backoff, so
+// existing policies can drive this package's retry loop during a migration. It
+// lives in its own module so that the core retry package remains free of
+// dependencies.
+package retrycenkalti
+
+import (
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/sethvargo/go-retry"
+)
+
+// FromCenkalti adapts b to a retry.Backoff. Each call to Next returns the value
+// of b.NextBackOff, and signals stop when it returns backoff.Stop. The adapter
+// implements retry.Resettable by calling b.Reset, but not retry.Cloneable,
+// since a BackOff cannot be copied; use a separate adapter per retry loop.
+//
+// Like the BackOff implementations themselves, the adapter is not safe for
+// concurrent use unless b is. Wrap it in retry.Sync if it must be shared.
+func FromCenkalti(b backoff.BackOff) retry.Backoff {
+	return &adapter{b: b}
+}
+
+type adapter struct {
+	b backoff.BackOff
+}
+
+var _ retry.Resettable = (*adapter)(nil)
+
+// Next implements retry.Backoff.
+func (a *adapter) Next() (time.Duration, bool) {
+	d := a.b.NextBackOff()
+	if d == backoff.Stop {
+		return 0, true
+	}
+	return d, false
+}
+
+// Reset implements retry.Resettable.
+func (a *adapter) Reset() {
+	a.b.Reset()
+}
+
+// String implements fmt.Stringer.
+func (a *adapter) String() string {
+	return "Cenkalti()"
+}
@@ -0,0 +1,76 @@
+package retrycenkalti_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/sethvargo/go-retry"
+	"github.com/sethvargo/go-retry/retrycenkalti"
+)
+
+func TestFromCenkalti(t *testing.T) {
+	t.Parallel()
+
+	t.Run("stop", func(t *testing.T) {
+		t.Parallel()
+
+		b := retrycenkalti.FromCenkalti(backoff.WithMaxRetries(backoff.NewConstantBackOff(1*time.Second), 2))
+		if got, want := retry.Simulate(b, 10), []time.Duration{1 * time.Second, 1 * time.Second}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("stop_backoff", func(t *testing.T) {
+		t.Parallel()
+
+		b := retrycenkalti.FromCenkalti(&backoff.StopBackOff{})
+		if _, stop := b.Next(); !stop {
+			t.Error("expected stop")
+		}
+	})
+
+	t.Run("zero", func(t *testing.T) {
+		t.Parallel()
+
+		// A zero delay is a valid delay, not a stop.
+		b := retrycenkalti.FromCenkalti(&backoff.ZeroBackOff{})
+		if val, stop := b.Next(); stop || val != 0 {
+			t.Errorf("expected (0, false), got (%v, %t)", val, stop)
+		}
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		t.Parallel()
+
+		b := retrycenkalti.FromCenkalti(backoff.WithMaxRetries(backoff.NewConstantBackOff(1*time.Second), 1))
+		retry.Simulate(b, 10)
+		b.(retry.Resettable).Reset()
+
+		if val, stop := b.Next(); stop || val != 1*time.Second {
+			t.Errorf("expected (%v, false), got (%v, %t)", 1*time.Second, val, stop)
+		}
+	})
+
+	t.Run("retry_loop", func(t *testing.T) {
+		t.Parallel()
+
+		b := retrycenkalti.FromCenkalti(backoff.WithMaxRetries(backoff.NewConstantBackOff(1*time.Millisecond), 3))
+
+		var calls int
+		err := retry.Do(context.Background(), b, func(_ context.Context) error {
+			calls++
+			return retry.RetryableError(io.EOF)
+		})
+		if !errors.Is(err, retry.ErrBackoffStopped) {
+			t.Errorf("expected %v to be %v", err, retry.ErrBackoffStopped)
+		}
+		if got, want := calls, 4; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
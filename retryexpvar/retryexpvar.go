@@ -0,0 +1,127 @@
+// Package retryexpvar publishes aggregate retry statistics through expvar, for
+// a dependency-free look at retry health at /debug/vars. It is a separate
+// package because importing expvar registers that handler on
+// http.DefaultServeMux.
+package retryexpvar
+
+import (
+	"context"
+	"expvar"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+// buckets are the upper bounds of the delay histogram. Each delay is counted in
+// the first bucket it is less than, or in the overflow bucket, "inf".
+var buckets = []struct {
+	Name  string
+	Bound time.Duration
+}{
+	{"lt_10ms", 10 * time.Millisecond},
+	{"lt_100ms", 100 * time.Millisecond},
+	{"lt_1s", 1 * time.Second},
+	{"lt_10s", 10 * time.Second},
+	{"lt_1m", 1 * time.Minute},
+}
+
+// Collector counts the transitions of the retry loops it is given, and the
+// delays between their attempts, in an expvar.Map. It implements retry.Metrics.
+// It is safe for concurrent use.
+type Collector struct {
+	m      *expvar.Map
+	delays *expvar.Map
+}
+
+var _ retry.Metrics = (*Collector)(nil)
+
+// PublishExpvar creates a Collector and publishes its map under name. The map
+// has the counters "attempts", "retries", "successes", and "give_ups", and a
+// "delays" map counting delays below 10ms, 100ms, 1s, 10s, and 1m, in
+// "lt_10ms" through "lt_1m", and longer ones in "inf".
+//
+// Like expvar.Publish, it panics if name is already in use, so call it once,
+// for example from a package-level variable or init function.
+func PublishExpvar(name string) *Collector {
+	delays := new(expvar.Map).Init()
+	for _, b := range buckets {
+		delays.Add(b.Name, 0)
+	}
+	delays.Add("inf", 0)
+
+	m := expvar.NewMap(name)
+	m.Add("attempts", 0)
+	m.Add("retries", 0)
+	m.Add("successes", 0)
+	m.Add("give_ups", 0)
+	m.Set("delays", delays)
+
+	return &Collector{
+		m:      m,
+		delays: delays,
+	}
+}
+
+// IncAttempt implements retry.Metrics.
+func (c *Collector) IncAttempt() {
+	c.m.Add("attempts", 1)
+}
+
+// IncRetry implements retry.Metrics.
+func (c *Collector) IncRetry() {
+	c.m.Add("retries", 1)
+}
+
+// IncSuccess implements retry.Metrics.
+func (c *Collector) IncSuccess() {
+	c.m.Add("successes", 1)
+}
+
+// IncGiveUp implements retry.Metrics.
+func (c *Collector) IncGiveUp() {
+	c.m.Add("give_ups", 1)
+}
+
+// ObserveDelay counts d in its delay bucket. It is a retry.DelayObserver.
+func (c *Collector) ObserveDelay(d time.Duration) {
+	for _, b := range buckets {
+		if d < b.Bound {
+			c.delays.Add(b.Name, 1)
+			return
+		}
+	}
+	c.delays.Add("inf", 1)
+}
+
+// Do is like retry.Do, but records the retry loop in c.
+func Do(ctx context.Context, c *Collector, b retry.Backoff, f retry.RetryFunc) error {
+	_, err := DoWithData(ctx, c, b, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, f(ctx)
+	})
+	return err
+}
+
+// DoWithData is like retry.DoWithData, but records the retry loop in c: each
+// attempt, each retry, how the loop ended, and the delay before each retry, as
+// actually slept after any adjustment for the context's deadline.
+func DoWithData[T any](ctx context.Context, c *Collector, b retry.Backoff, f retry.RetryWithDataFunc[T]) (T, error) {
+	if b == nil {
+		var zero T
+		return zero, retry.ErrNilBackoff
+	}
+
+	val, err := retry.DoWithDelayObserver(ctx, b, func(ctx context.Context) (T, error) {
+		c.IncAttempt()
+		return f(ctx)
+	}, func(d time.Duration) {
+		c.IncRetry()
+		c.ObserveDelay(d)
+	})
+	if err != nil {
+		c.IncGiveUp()
+		return val, err
+	}
+
+	c.IncSuccess()
+	return val, nil
+}
@@ -0,0 +1,151 @@
+package retryexpvar_test
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+	"github.com/sethvargo/go-retry/retryexpvar"
+)
+
+// snapshot decodes the published map called name.
+func snapshot(t *testing.T, name string) map[string]any {
+	t.Helper()
+
+	var m map[string]any
+	if err := json.Unmarshal([]byte(expvar.Get(name).String()), &m); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestDoWithData(t *testing.T) {
+	t.Parallel()
+
+	c := retryexpvar.PublishExpvar("retryexpvar_test_do")
+	ctx := context.Background()
+
+	var calls int
+	val, err := retryexpvar.DoWithData(ctx, c, retry.NewConstant(1*time.Millisecond), func(_ context.Context) (int, error) {
+		calls++
+		if calls < 3 {
+			return 0, retry.RetryableError(io.EOF)
+		}
+		return calls, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := val, 3; got != want {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+
+	b := retry.WithMaxRetries(1, retry.NewConstant(200*time.Millisecond))
+	if err := retryexpvar.Do(ctx, c, b, func(_ context.Context) error {
+		return retry.RetryableError(io.EOF)
+	}); err == nil {
+		t.Fatal("expected error")
+	}
+
+	got := snapshot(t, "retryexpvar_test_do")
+	want := map[string]any{
+		"attempts":  float64(5),
+		"retries":   float64(3),
+		"successes": float64(1),
+		"give_ups":  float64(1),
+		"delays": map[string]any{
+			"lt_10ms":  float64(2),
+			"lt_100ms": float64(0),
+			"lt_1s":    float64(1),
+			"lt_10s":   float64(0),
+			"lt_1m":    float64(0),
+			"inf":      float64(0),
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+}
+
+func TestDoWithData_backoffHooks(t *testing.T) {
+	t.Parallel()
+
+	c := retryexpvar.PublishExpvar("retryexpvar_test_hooks")
+
+	// The per-error limit relies on the retry loop passing each error to the
+	// backoff.
+	b := retry.WithMaxRetriesByError(map[error]int{io.EOF: 1}, 5, retry.NewConstant(1*time.Nanosecond))
+
+	var calls int
+	_ = retryexpvar.Do(context.Background(), c, b, func(_ context.Context) error {
+		calls++
+		return retry.RetryableError(io.EOF)
+	})
+	if got, want := calls, 2; got != want {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+}
+
+func TestDoWithData_nilBackoff(t *testing.T) {
+	t.Parallel()
+
+	c := retryexpvar.PublishExpvar("retryexpvar_test_nil")
+
+	_, err := retryexpvar.DoWithData(context.Background(), c, nil, func(_ context.Context) (int, error) {
+		t.Error("expected f not to be called")
+		return 0, nil
+	})
+	if got, want := err, retry.ErrNilBackoff; got != want {
+		t.Errorf("expected %#v to be %#v", got, want)
+	}
+	if got, want := snapshot(t, "retryexpvar_test_nil")["attempts"], float64(0); got != want {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+}
+
+func TestCollector_ObserveDelay(t *testing.T) {
+	t.Parallel()
+
+	c := retryexpvar.PublishExpvar("retryexpvar_test_buckets")
+	for _, d := range []time.Duration{0, 10 * time.Millisecond, 2 * time.Second, 1 * time.Hour} {
+		c.ObserveDelay(d)
+	}
+
+	got := snapshot(t, "retryexpvar_test_buckets")["delays"]
+	want := map[string]any{
+		"lt_10ms":  float64(1),
+		"lt_100ms": float64(1),
+		"lt_1s":    float64(0),
+		"lt_10s":   float64(1),
+		"lt_1m":    float64(0),
+		"inf":      float64(1),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+}
+
+func TestCollector_metrics(t *testing.T) {
+	t.Parallel()
+
+	// The collector also works with the core DoWithMetrics.
+	c := retryexpvar.PublishExpvar("retryexpvar_test_metrics")
+	if err := retry.DoWithMetrics(context.Background(), retry.NewConstant(1*time.Millisecond), func(_ context.Context) error {
+		return nil
+	}, c); err != nil {
+		t.Fatal(err)
+	}
+
+	got := snapshot(t, "retryexpvar_test_metrics")
+	if got, want := got["attempts"], float64(1); got != want {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+	if got, want := got["successes"], float64(1); got != want {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+}
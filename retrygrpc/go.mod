@@ -0,0 +1,14 @@
+module github.com/sethvargo/go-retry/retrygrpc
+
+go 1.20
+
+require (
+	github.com/sethvargo/go-retry v0.3.0
+	google.golang.org/grpc v1.64.0
+)
+
+require (
+	golang.org/x/sys v0.30.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240318140521-94a12d6c2237 // indirect
+	google.golang.org/protobuf v1.34.2 // indirect
+)
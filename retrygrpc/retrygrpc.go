@@ -0,0 +1,41 @@
+// Package retrygrpc classifies gRPC errors for retrying, marking transient
+// status codes as retryable. It lives in its own module so that the core retry
+// package remains free of dependencies.
+package retrygrpc
+
+import (
+	"github.com/sethvargo/go-retry"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RetryableCodes are the status codes that RetryableGRPC marks as retryable.
+var RetryableCodes = []codes.Code{
+	codes.Unavailable,
+	codes.ResourceExhausted,
+	codes.Aborted,
+	codes.DeadlineExceeded,
+}
+
+// RetryableGRPC returns err marked with retry.RetryableError if its gRPC status
+// code is one of RetryableCodes, and err unchanged otherwise, including errors
+// that do not carry a gRPC status. It returns nil if err is nil. Since it is a
+// retry.TransformFunc, it can be passed to retry.DoWithTransform to classify
+// every error of a client, or called inside a RetryFunc.
+func RetryableGRPC(err error) error {
+	if err == nil || retry.IsRetryable(err) {
+		return err
+	}
+
+	s, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	for _, c := range RetryableCodes {
+		if s.Code() == c {
+			return retry.RetryableError(err)
+		}
+	}
+	return err
+}
@@ -0,0 +1,79 @@
+package retrygrpc_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+	"github.com/sethvargo/go-retry/retrygrpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRetryableGRPC(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name      string
+		err       error
+		retryable bool
+	}{
+		{name: "unavailable", err: status.Error(codes.Unavailable, "down"), retryable: true},
+		{name: "resource_exhausted", err: status.Error(codes.ResourceExhausted, "quota"), retryable: true},
+		{name: "aborted", err: status.Error(codes.Aborted, "conflict"), retryable: true},
+		{name: "deadline_exceeded", err: status.Error(codes.DeadlineExceeded, "slow"), retryable: true},
+		{name: "wrapped", err: fmt.Errorf("call: %w", status.Error(codes.Unavailable, "down")), retryable: true},
+		{name: "not_found", err: status.Error(codes.NotFound, "missing")},
+		{name: "invalid_argument", err: status.Error(codes.InvalidArgument, "bad")},
+		{name: "permission_denied", err: status.Error(codes.PermissionDenied, "denied")},
+		{name: "internal", err: status.Error(codes.Internal, "bug")},
+		{name: "non_grpc", err: io.EOF},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := retrygrpc.RetryableGRPC(tc.err)
+			if got, want := retry.IsRetryable(err), tc.retryable; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+			if !errors.Is(err, tc.err) {
+				t.Errorf("expected %#v to be %#v", err, tc.err)
+			}
+		})
+	}
+
+	t.Run("nil", func(t *testing.T) {
+		t.Parallel()
+
+		if err := retrygrpc.RetryableGRPC(nil); err != nil {
+			t.Errorf("expected %v to be nil", err)
+		}
+	})
+
+	t.Run("transform", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.WithMaxRetries(2, retry.NewConstant(1*time.Nanosecond))
+
+		var calls int
+		_, err := retry.DoWithTransform(ctx, b, func(_ context.Context) (int, error) {
+			calls++
+			return 0, status.Error(codes.Unavailable, "down")
+		}, retrygrpc.RetryableGRPC)
+		if !errors.Is(err, retry.ErrBackoffStopped) {
+			t.Errorf("expected %#v to be %#v", err, retry.ErrBackoffStopped)
+		}
+		if got, want := calls, 3; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
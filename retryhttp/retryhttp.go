@@ -0,0 +1,137 @@
+// Package retryhttp retries HTTP requests that fail with a transient status
+// code, such as 429 Too Many Requests or 503 Service Unavailable. It honors the
+// Retry-After header and rewinds the request body before each retry.
+package retryhttp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+// DefaultRetryStatuses are the status codes retried by DoHTTP when no status
+// codes are given.
+var DefaultRetryStatuses = []int{
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// ErrBodyNotRewindable is returned by DoHTTP when a request with a body must be
+// retried, but the request has no GetBody function to rewind the body with.
+var ErrBodyNotRewindable = errors.New("retryhttp: request body cannot be rewound")
+
+// StatusError is the underlying error returned by DoHTTP when a response with a
+// retryable status code was received and the backoff stopped before a
+// successful response.
+type StatusError struct {
+	// StatusCode is the status code of the last response.
+	StatusCode int
+}
+
+// Error returns the error string.
+func (e *StatusError) Error() string {
+	return "retryhttp: received retryable status " + strconv.Itoa(e.StatusCode) + " " + http.StatusText(e.StatusCode)
+}
+
+// DoHTTP sends req with client, and retries according to b while the response
+// status code is one of retryStatuses, or DefaultRetryStatuses if none are
+// given. Errors from the client, such as connection failures, are also
+// retried, unless ctx is done. A response with any other status code is
+// returned directly, and the caller must close its body.
+//
+// If a retryable response has a Retry-After header, in either seconds or an
+// HTTP date, the next attempt waits at least that long. The body of each
+// retryable response is drained and closed. Before each retry, the request body
+// is rewound with req.GetBody, which http.NewRequest sets for common body
+// types; if a retry is needed and req has a body but no GetBody, DoHTTP returns
+// ErrBodyNotRewindable. If client is nil, http.DefaultClient is used.
+func DoHTTP(ctx context.Context, b retry.Backoff, client *http.Client, req *http.Request, retryStatuses ...int) (*http.Response, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if len(retryStatuses) == 0 {
+		retryStatuses = DefaultRetryStatuses
+	}
+
+	var attempt int
+	return retry.DoWithData(ctx, b, func(ctx context.Context) (*http.Response, error) {
+		attempt++
+
+		r := req.Clone(ctx)
+		if attempt > 1 && req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				return nil, ErrBodyNotRewindable
+			}
+
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r.Body = body
+		}
+
+		resp, err := client.Do(r)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			return nil, retry.RetryableError(err)
+		}
+
+		if !contains(retryStatuses, resp.StatusCode) {
+			return resp, nil
+		}
+
+		// Drain a bounded amount of the body so the connection can be reused.
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4<<10))
+		resp.Body.Close()
+
+		serr := &StatusError{StatusCode: resp.StatusCode}
+		if d, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			return nil, retry.RetryableErrorAfter(serr, d)
+		}
+		return nil, retry.RetryableError(serr)
+	})
+}
+
+// parseRetryAfter parses the value of a Retry-After header, which is either a
+// number of seconds or an HTTP date, relative to now.
+func parseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.ParseInt(v, 10, 64); err == nil {
+		if secs < 0 || secs > math.MaxInt64/int64(time.Second) {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+	if d := t.Sub(now); d > 0 {
+		return d, true
+	}
+	return 0, true
+}
+
+func contains(codes []int, code int) bool {
+	for _, c := range codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,206 @@
+package retryhttp_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+	"github.com/sethvargo/go-retry/retryhttp"
+)
+
+func TestDoHTTP(t *testing.T) {
+	t.Parallel()
+
+	t.Run("retries_and_rewinds_body", func(t *testing.T) {
+		t.Parallel()
+
+		var l sync.Mutex
+		var bodies []string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+
+			l.Lock()
+			bodies = append(bodies, string(body))
+			n := len(bodies)
+			l.Unlock()
+
+			if n < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		t.Cleanup(srv.Close)
+
+		ctx := context.Background()
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, srv.URL, strings.NewReader("payload"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		b := retry.WithMaxRetries(5, retry.NewConstant(1*time.Millisecond))
+		resp, err := retryhttp.DoHTTP(ctx, b, srv.Client(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if got, want := resp.StatusCode, http.StatusOK; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+
+		l.Lock()
+		defer l.Unlock()
+		if got, want := len(bodies), 3; got != want {
+			t.Fatalf("expected %v to be %v", got, want)
+		}
+		for i, body := range bodies {
+			if got, want := body, "payload"; got != want {
+				t.Errorf("attempt %d: expected %q to be %q", i+1, got, want)
+			}
+		}
+	})
+
+	t.Run("non_retryable_status", func(t *testing.T) {
+		t.Parallel()
+
+		var calls int
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		t.Cleanup(srv.Close)
+
+		ctx := context.Background()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		b := retry.WithMaxRetries(5, retry.NewConstant(1*time.Millisecond))
+		resp, err := retryhttp.DoHTTP(ctx, b, srv.Client(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if got, want := resp.StatusCode, http.StatusNotFound; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := calls, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("custom_statuses", func(t *testing.T) {
+		t.Parallel()
+
+		var calls int
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusConflict)
+		}))
+		t.Cleanup(srv.Close)
+
+		ctx := context.Background()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		b := retry.WithMaxRetries(2, retry.NewConstant(1*time.Millisecond))
+		_, err = retryhttp.DoHTTP(ctx, b, srv.Client(), req, http.StatusConflict)
+		if !errors.Is(err, retry.ErrBackoffStopped) {
+			t.Errorf("expected %#v to be %#v", err, retry.ErrBackoffStopped)
+		}
+
+		var serr *retryhttp.StatusError
+		if !errors.As(err, &serr) {
+			t.Fatalf("expected %#v to be a StatusError", err)
+		}
+		if got, want := serr.StatusCode, http.StatusConflict; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := calls, 3; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("retry_after", func(t *testing.T) {
+		t.Parallel()
+
+		var calls int
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls == 1 {
+				w.Header().Set("Retry-After", "1")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		t.Cleanup(srv.Close)
+
+		ctx := context.Background()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		start := time.Now()
+		b := retry.NewConstant(1 * time.Millisecond)
+		resp, err := retryhttp.DoHTTP(ctx, b, srv.Client(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if got, want := time.Since(start), 1*time.Second; got < want {
+			t.Errorf("expected %v to be at least %v", got, want)
+		}
+	})
+
+	t.Run("body_not_rewindable", func(t *testing.T) {
+		t.Parallel()
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		t.Cleanup(srv.Close)
+
+		ctx := context.Background()
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, srv.URL, strings.NewReader("payload"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.GetBody = nil
+
+		b := retry.WithMaxRetries(2, retry.NewConstant(1*time.Millisecond))
+		if _, err := retryhttp.DoHTTP(ctx, b, srv.Client(), req); !errors.Is(err, retryhttp.ErrBodyNotRewindable) {
+			t.Errorf("expected %#v to be %#v", err, retryhttp.ErrBodyNotRewindable)
+		}
+	})
+}
+
+func ExampleDoHTTP() {
+	ctx := context.Background()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://example.com", nil)
+	if err != nil {
+		// handle error
+	}
+
+	b := retry.WithMaxRetries(3, retry.NewExponential(100*time.Millisecond))
+	resp, err := retryhttp.DoHTTP(ctx, b, http.DefaultClient, req)
+	if err != nil {
+		// handle error
+	}
+	defer resp.Body.Close()
+}
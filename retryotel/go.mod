@@ -0,0 +1,17 @@
+module github.com/sethvargo/go-retry/retryotel
+
+go 1.20
+
+require (
+	github.com/sethvargo/go-retry v0.3.0
+	go.opentelemetry.io/otel v1.24.0
+	go.opentelemetry.io/otel/sdk v1.24.0
+	go.opentelemetry.io/otel/trace v1.24.0
+)
+
+require (
+	github.com/go-logr/logr v1.4.4 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	go.opentelemetry.io/otel/metric v1.24.0 // indirect
+	golang.org/x/sys v0.30.0 // indirect
+)
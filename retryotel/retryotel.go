@@ -0,0 +1,90 @@
+// Package retryotel provides OpenTelemetry tracing for retry loops. It lives in
+// its own module so that the core retry package remains free of dependencies.
+package retryotel
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// AttemptKey is the attribute key for the 1-based attempt number.
+	AttemptKey = attribute.Key("retry.attempt")
+
+	// DelayKey is the attribute key for the delay before the next attempt, in
+	// milliseconds.
+	DelayKey = attribute.Key("retry.delay_ms")
+
+	// FinalKey is the attribute key for whether the attempt was the last one.
+	FinalKey = attribute.Key("retry.final")
+
+	// ExhaustedKey is the attribute key for whether the retry loop gave up
+	// because the backoff signaled stop.
+	ExhaustedKey = attribute.Key("retry.exhausted")
+)
+
+// DoWithTracer is like retry.Do, but wraps the retry loop in a span named
+// "retry" and each invocation of f in a child span named "retry.attempt". Each
+// attempt span records the attempt number, the delay before the next attempt,
+// and whether it was the final attempt. Span statuses are set from the errors
+// returned by f and by the retry loop.
+func DoWithTracer(ctx context.Context, b retry.Backoff, tracer trace.Tracer, f retry.RetryFunc) error {
+	if b == nil {
+		return retry.ErrNilBackoff
+	}
+
+	ctx, span := tracer.Start(ctx, "retry")
+	defer span.End()
+
+	var attempt int
+	var attemptSpan trace.Span
+
+	// endAttempt ends the span for the current attempt, if any. The retry loop
+	// only notifies before a retry, so any attempt span still open when it
+	// returns belongs to the final attempt.
+	endAttempt := func(final bool) {
+		if attemptSpan == nil {
+			return
+		}
+		attemptSpan.SetAttributes(FinalKey.Bool(final))
+		attemptSpan.End()
+		attemptSpan = nil
+	}
+	err := retry.DoWithNotify(ctx, b, func(ctx context.Context) error {
+		attempt++
+
+		ctx, attemptSpan = tracer.Start(ctx, "retry.attempt",
+			trace.WithAttributes(AttemptKey.Int(attempt)))
+
+		err := f(ctx)
+		if err != nil {
+			attemptSpan.RecordError(err)
+			attemptSpan.SetStatus(codes.Error, err.Error())
+		} else {
+			attemptSpan.SetStatus(codes.Ok, "")
+		}
+		return err
+	}, func(_ int, _ error, next time.Duration) {
+		attemptSpan.SetAttributes(DelayKey.Int64(next.Milliseconds()))
+		endAttempt(false)
+	})
+	endAttempt(true)
+
+	span.SetAttributes(AttemptKey.Int(attempt))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.Is(err, retry.ErrBackoffStopped) {
+			span.SetAttributes(ExhaustedKey.Bool(true))
+		}
+	} else {
+		span.SetStatus(codes.Ok, "")
+	}
+	return err
+}
@@ -0,0 +1,162 @@
+package retryotel_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+	"github.com/sethvargo/go-retry/retryotel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestDoWithTracer(t *testing.T) {
+	t.Parallel()
+
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	ctx := context.Background()
+	b := retry.WithMaxRetries(2, retry.NewConstant(1*time.Millisecond))
+
+	var i int
+	if err := retryotel.DoWithTracer(ctx, b, tracer, func(_ context.Context) error {
+		i++
+		if i < 3 {
+			return retry.RetryableError(fmt.Errorf("oops"))
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := recorder.Ended()
+	if got, want := len(spans), 4; got != want {
+		t.Fatalf("expected %v to be %v", got, want)
+	}
+
+	for i, span := range spans[:3] {
+		if got, want := span.Name(), "retry.attempt"; got != want {
+			t.Errorf("expected %q to be %q", got, want)
+		}
+
+		attrs := attributes(span.Attributes())
+		if got, want := attrs[retryotel.AttemptKey], attribute.IntValue(i+1); got != want {
+			t.Errorf("expected %v to be %v", got.Emit(), want.Emit())
+		}
+
+		final := i == 2
+		if got, want := attrs[retryotel.FinalKey], attribute.BoolValue(final); got != want {
+			t.Errorf("attempt %d: expected %v to be %v", i+1, got.Emit(), want.Emit())
+		}
+
+		if final {
+			if got, want := span.Status().Code, codes.Ok; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+		} else {
+			if got, want := span.Status().Code, codes.Error; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+			if got, want := attrs[retryotel.DelayKey], attribute.Int64Value(1); got != want {
+				t.Errorf("expected %v to be %v", got.Emit(), want.Emit())
+			}
+		}
+
+		if got, want := span.Parent().SpanID(), spans[3].SpanContext().SpanID(); got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	}
+
+	parent := spans[3]
+	if got, want := parent.Name(), "retry"; got != want {
+		t.Errorf("expected %q to be %q", got, want)
+	}
+	if got, want := parent.Status().Code, codes.Ok; got != want {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+}
+
+func TestDoWithTracer_exhausted(t *testing.T) {
+	t.Parallel()
+
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	ctx := context.Background()
+	b := retry.WithMaxRetries(1, retry.NewConstant(1*time.Millisecond))
+
+	if err := retryotel.DoWithTracer(ctx, b, tracer, func(_ context.Context) error {
+		return retry.RetryableError(fmt.Errorf("oops"))
+	}); err == nil {
+		t.Fatal("expected err")
+	}
+
+	spans := recorder.Ended()
+	if got, want := len(spans), 3; got != want {
+		t.Fatalf("expected %v to be %v", got, want)
+	}
+
+	last := attributes(spans[1].Attributes())
+	if got, want := last[retryotel.FinalKey], attribute.BoolValue(true); got != want {
+		t.Errorf("expected %v to be %v", got.Emit(), want.Emit())
+	}
+
+	parent := spans[2]
+	if got, want := parent.Status().Code, codes.Error; got != want {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+	if got, want := attributes(parent.Attributes())[retryotel.ExhaustedKey], attribute.BoolValue(true); got != want {
+		t.Errorf("expected %v to be %v", got.Emit(), want.Emit())
+	}
+}
+
+func TestDoWithTracer_backoffHooks(t *testing.T) {
+	t.Parallel()
+
+	tracer := sdktrace.NewTracerProvider().Tracer("test")
+
+	// The per-error limit relies on the retry loop passing each error to the
+	// backoff.
+	errOops := fmt.Errorf("oops")
+	b := retry.WithMaxRetriesByError(map[error]int{errOops: 1}, 5, retry.NewConstant(1*time.Nanosecond))
+
+	var calls int
+	_ = retryotel.DoWithTracer(context.Background(), b, tracer, func(_ context.Context) error {
+		calls++
+		return retry.RetryableError(errOops)
+	})
+	if got, want := calls, 2; got != want {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+}
+
+func TestDoWithTracer_nilBackoff(t *testing.T) {
+	t.Parallel()
+
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	err := retryotel.DoWithTracer(context.Background(), nil, tracer, func(_ context.Context) error {
+		t.Error("expected f not to be called")
+		return nil
+	})
+	if got, want := err, retry.ErrNilBackoff; got != want {
+		t.Errorf("expected %#v to be %#v", got, want)
+	}
+	if got, want := len(recorder.Ended()), 0; got != want {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+}
+
+func attributes(kvs []attribute.KeyValue) map[attribute.Key]attribute.Value {
+	m := make(map[attribute.Key]attribute.Value, len(kvs))
+	for _, kv := range kvs {
+		m[kv.Key] = kv.Value
+	}
+	return m
+}
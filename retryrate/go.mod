@@ -0,0 +1,7 @@
+module github.com/sethvargo/go-retry/retryrate
+
+go 1.20
+
+require github.com/sethvargo/go-retry v0.3.0
+
+require golang.org/x/time v0.5.0
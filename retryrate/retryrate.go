@@ -0,0 +1,51 @@
+// Package retryrate paces retries with a golang.org/x/time/rate limiter, so
+// that retries share the same rate limit as the rest of a client. It lives in
+// its own module so that the core retry package remains free of dependencies.
+package retryrate
+
+import (
+	"context"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+	"golang.org/x/time/rate"
+)
+
+// NewRateLimited creates a new backoff that takes a reservation from l on each
+// call to Next and returns its delay, so retries naturally pace to the rate the
+// limiter allows. It signals stop if the limiter can never grant the
+// reservation, for example when its burst is zero.
+func NewRateLimited(l *rate.Limiter) retry.Backoff {
+	return retry.BackoffFunc(func() (time.Duration, bool) {
+		r := l.Reserve()
+		if !r.OK() {
+			return 0, true
+		}
+		return r.Delay(), false
+	})
+}
+
+// NewRateLimitedContext is like NewRateLimited, but also signals stop if ctx is
+// done, or if the reservation would not be granted before ctx's deadline. In
+// those cases the reservation is canceled, so the tokens are returned to l for
+// other callers.
+func NewRateLimitedContext(ctx context.Context, l *rate.Limiter) retry.Backoff {
+	return retry.BackoffFunc(func() (time.Duration, bool) {
+		if ctx.Err() != nil {
+			return 0, true
+		}
+
+		now := time.Now()
+		r := l.ReserveN(now, 1)
+		if !r.OK() {
+			return 0, true
+		}
+
+		delay := r.DelayFrom(now)
+		if deadline, ok := ctx.Deadline(); ok && now.Add(delay).After(deadline) {
+			r.CancelAt(now)
+			return 0, true
+		}
+		return delay, false
+	})
+}
@@ -0,0 +1,104 @@
+package retryrate_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+	"github.com/sethvargo/go-retry/retryrate"
+	"golang.org/x/time/rate"
+)
+
+func TestNewRateLimited(t *testing.T) {
+	t.Parallel()
+
+	t.Run("paces", func(t *testing.T) {
+		t.Parallel()
+
+		l := rate.NewLimiter(rate.Every(100*time.Millisecond), 1)
+		b := retryrate.NewRateLimited(l)
+
+		// The burst allows the first reservation immediately.
+		if val, stop := b.Next(); stop || val != 0 {
+			t.Errorf("expected (0, false), got (%v, %v)", val, stop)
+		}
+
+		val, stop := b.Next()
+		if stop {
+			t.Fatalf("should not stop")
+		}
+		if val <= 50*time.Millisecond || val > 100*time.Millisecond {
+			t.Errorf("expected %v to be about %v", val, 100*time.Millisecond)
+		}
+	})
+
+	t.Run("never_allowed", func(t *testing.T) {
+		t.Parallel()
+
+		l := rate.NewLimiter(rate.Every(time.Second), 0)
+		if _, stop := retryrate.NewRateLimited(l).Next(); !stop {
+			t.Errorf("should stop")
+		}
+	})
+}
+
+func TestNewRateLimitedContext(t *testing.T) {
+	t.Parallel()
+
+	t.Run("deadline", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		l := rate.NewLimiter(rate.Every(time.Hour), 1)
+		b := retryrate.NewRateLimitedContext(ctx, l)
+
+		if _, stop := b.Next(); stop {
+			t.Fatalf("should not stop")
+		}
+
+		// The next token is an hour away, past the deadline.
+		if _, stop := b.Next(); !stop {
+			t.Errorf("should stop")
+		}
+
+		// The canceled reservation did not consume a token.
+		if got, want := l.Tokens(), 0.0; got < want-0.01 {
+			t.Errorf("expected %v to be about %v", got, want)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		l := rate.NewLimiter(rate.Inf, 1)
+		if _, stop := retryrate.NewRateLimitedContext(ctx, l).Next(); !stop {
+			t.Errorf("should stop")
+		}
+	})
+
+	t.Run("retry", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		l := rate.NewLimiter(rate.Every(time.Millisecond), 1)
+
+		var calls int
+		err := retry.Do(ctx, retry.WithMaxRetries(3, retryrate.NewRateLimitedContext(ctx, l)), func(_ context.Context) error {
+			calls++
+			return retry.RetryableError(errors.New("oops"))
+		})
+		if !errors.Is(err, retry.ErrBackoffStopped) {
+			t.Errorf("expected %v to be %v", err, retry.ErrBackoffStopped)
+		}
+		if got, want := calls, 4; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
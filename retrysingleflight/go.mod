@@ -0,0 +1,7 @@
+module github.com/sethvargo/go-retry/retrysingleflight
+
+go 1.21
+
+require github.com/sethvargo/go-retry v0.3.0
+
+require golang.org/x/sync v0.6.0
@@ -0,0 +1,40 @@
+// Package retrysingleflight combines retry loops with
+// golang.org/x/sync/singleflight, so concurrent callers retrying the same
+// operation, such as refreshing a shared token, share a single retry loop
+// instead of each running their own. It lives in its own module so that the
+// core retry package remains free of dependencies.
+package retrysingleflight
+
+import (
+	"context"
+
+	"github.com/sethvargo/go-retry"
+	"golang.org/x/sync/singleflight"
+)
+
+// DoShared is like retry.DoWithData, but concurrent callers with the same key
+// share a single retry loop and all receive its result. Only the first caller's
+// backoff and function are used; callers that arrive while the loop is running
+// wait for it instead. f should therefore be idempotent, and all callers of a
+// key must use the same type T.
+//
+// The shared loop runs with a context that keeps the values of the first
+// caller's ctx but not its cancellation or deadline, so one caller giving up
+// does not cancel the work for the others. Each caller returns its own
+// ctx.Err() as soon as its ctx is done, while the loop keeps running for the
+// rest. Since the loop outlives its callers, b should bound it, for example
+// with retry.WithMaxRetries or retry.WithMaxDuration.
+func DoShared[T any](ctx context.Context, g *singleflight.Group, key string, b retry.Backoff, f retry.RetryWithDataFunc[T]) (T, error) {
+	ch := g.DoChan(key, func() (any, error) {
+		return retry.DoWithData(context.WithoutCancel(ctx), b, f)
+	})
+
+	select {
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	case res := <-ch:
+		val, _ := res.Val.(T)
+		return val, res.Err
+	}
+}
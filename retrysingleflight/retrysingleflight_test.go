@@ -0,0 +1,125 @@
+package retrysingleflight_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+	"github.com/sethvargo/go-retry/retrysingleflight"
+	"golang.org/x/sync/singleflight"
+)
+
+func TestDoShared(t *testing.T) {
+	t.Parallel()
+
+	t.Run("dedupes", func(t *testing.T) {
+		t.Parallel()
+
+		const callers = 10
+
+		var g singleflight.Group
+		var calls int32
+		started := make(chan struct{})
+		release := make(chan struct{})
+
+		f := func(_ context.Context) (string, error) {
+			// The first attempt fails, so the dedup covers the whole retry loop
+			// rather than a single call.
+			if atomic.AddInt32(&calls, 1) == 1 {
+				close(started)
+				<-release
+				return "", retry.RetryableError(io.EOF)
+			}
+			return "token", nil
+		}
+		b := retry.NewConstant(1 * time.Nanosecond)
+
+		var wg sync.WaitGroup
+		results := make([]string, callers)
+		errs := make([]error, callers)
+		run := func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = retrysingleflight.DoShared(context.Background(), &g, "key", b, f)
+		}
+
+		wg.Add(callers)
+		go run(0)
+		<-started
+		for i := 1; i < callers; i++ {
+			go run(i)
+		}
+
+		// Give the other callers a moment to join the loop in flight.
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		for i := range results {
+			if errs[i] != nil {
+				t.Fatal(errs[i])
+			}
+			if got, want := results[i], "token"; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+		}
+		if got, want := atomic.LoadInt32(&calls), int32(2); got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("independent_cancellation", func(t *testing.T) {
+		t.Parallel()
+
+		var g singleflight.Group
+		started := make(chan struct{})
+		release := make(chan struct{})
+
+		var sharedErr error
+		f := func(ctx context.Context) (int, error) {
+			close(started)
+			<-release
+			sharedErr = ctx.Err()
+			return 1, nil
+		}
+		b := retry.NewConstant(1 * time.Nanosecond)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		leader := make(chan error, 1)
+		go func() {
+			_, err := retrysingleflight.DoShared(ctx, &g, "key", b, f)
+			leader <- err
+		}()
+		<-started
+
+		follower := make(chan int, 1)
+		go func() {
+			val, err := retrysingleflight.DoShared(context.Background(), &g, "key", b, f)
+			if err != nil {
+				t.Error(err)
+			}
+			follower <- val
+		}()
+
+		// Canceling the first caller returns right away without canceling the
+		// shared loop.
+		cancel()
+		if err := <-leader; !errors.Is(err, context.Canceled) {
+			t.Errorf("expected %v to be %v", err, context.Canceled)
+		}
+
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+
+		if got, want := <-follower, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if sharedErr != nil {
+			t.Errorf("expected %v to be nil", sharedErr)
+		}
+	})
+}
@@ -0,0 +1,5 @@
+module github.com/sethvargo/go-retry/retryslog
+
+go 1.21
+
+require github.com/sethvargo/go-retry v0.3.0
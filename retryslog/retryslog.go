@@ -0,0 +1,81 @@
+// Package retryslog logs retry loops with log/slog, so every retry in a
+// service is logged with the same fields. It lives in its own module because
+// log/slog requires a newer Go than the core retry package.
+package retryslog
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+const (
+	// AttemptKey is the attribute key for the 1-based number of the attempt
+	// that failed.
+	AttemptKey = "attempt"
+
+	// DelayKey is the attribute key for the delay before the next attempt.
+	DelayKey = "delay"
+
+	// ErrorKey is the attribute key for the error.
+	ErrorKey = "error"
+)
+
+// Do is like retry.Do, but logs the retry loop to logger as DoWithData does.
+func Do(ctx context.Context, logger *slog.Logger, b retry.Backoff, f retry.RetryFunc, attrs ...any) error {
+	_, err := DoWithData(ctx, logger, b, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, f(ctx)
+	}, attrs...)
+	return err
+}
+
+// DoWithData is like retry.DoWithData, but logs each retry to logger at the
+// Debug level with the attempt that failed, the delay before the next one, and
+// the error, and the final give-up at the Warn level with the number of
+// attempts and the error. A successful loop logs nothing else.
+//
+// Each call logs through a child logger, logger.With(attrs...), so that
+// request-scoped attributes given here appear on every entry, and each entry
+// is logged with ctx for handlers that read values from it. If logger is nil,
+// slog.Default() is used.
+func DoWithData[T any](ctx context.Context, logger *slog.Logger, b retry.Backoff, f retry.RetryWithDataFunc[T], attrs ...any) (T, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if len(attrs) > 0 {
+		logger = logger.With(attrs...)
+	}
+
+	var attempt int
+	var lastErr error
+
+	val, err := retry.DoWithDelayObserver(ctx, b, func(ctx context.Context) (T, error) {
+		attempt++
+
+		val, err := f(ctx)
+		lastErr = err
+		if inner, ok := retry.UnwrapRetryable(err); ok {
+			lastErr = inner
+		}
+		return val, err
+	}, func(delay time.Duration) {
+		logger.LogAttrs(ctx, slog.LevelDebug, "retrying",
+			slog.Int(AttemptKey, attempt),
+			slog.Duration(DelayKey, delay),
+			slog.Any(ErrorKey, lastErr))
+	})
+	if err != nil {
+		attempts := attempt
+		var aerr retry.AttemptsError
+		if errors.As(err, &aerr) {
+			attempts = aerr.Attempts()
+		}
+		logger.LogAttrs(ctx, slog.LevelWarn, "giving up",
+			slog.Int(AttemptKey, attempts),
+			slog.Any(ErrorKey, err))
+	}
+	return val, err
+}
@@ -0,0 +1,129 @@
+package retryslog_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+	"github.com/sethvargo/go-retry/retryslog"
+)
+
+// entries decodes the JSON log lines in buf.
+func entries(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+
+	var out []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var m map[string]any
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatal(err)
+		}
+		delete(m, "time")
+		out = append(out, m)
+	}
+	return out
+}
+
+func newLogger(buf *bytes.Buffer) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+func TestDoWithData(t *testing.T) {
+	t.Parallel()
+
+	t.Run("retries_then_succeeds", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		var calls int
+		val, err := retryslog.DoWithData(context.Background(), newLogger(&buf), retry.NewConstant(1*time.Millisecond), func(_ context.Context) (int, error) {
+			calls++
+			if calls < 3 {
+				return 0, retry.RetryableError(io.EOF)
+			}
+			return calls, nil
+		}, "request_id", "abc")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := val, 3; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+
+		got := entries(t, &buf)
+		if got, want := len(got), 2; got != want {
+			t.Fatalf("expected %v entries to be %v", got, want)
+		}
+		for i, e := range got {
+			if got, want := e["level"], "DEBUG"; got != want {
+				t.Errorf("entry %d: expected %v to be %v", i, got, want)
+			}
+			if got, want := e["msg"], "retrying"; got != want {
+				t.Errorf("entry %d: expected %v to be %v", i, got, want)
+			}
+			if got, want := e["attempt"], float64(i+1); got != want {
+				t.Errorf("entry %d: expected %v to be %v", i, got, want)
+			}
+			if got, want := e["delay"], float64(time.Millisecond); got != want {
+				t.Errorf("entry %d: expected %v to be %v", i, got, want)
+			}
+			if got, want := e["error"], "EOF"; got != want {
+				t.Errorf("entry %d: expected %v to be %v", i, got, want)
+			}
+			if got, want := e["request_id"], "abc"; got != want {
+				t.Errorf("entry %d: expected %v to be %v", i, got, want)
+			}
+		}
+	})
+
+	t.Run("gives_up", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		b := retry.WithMaxRetries(1, retry.NewConstant(1*time.Millisecond))
+		err := retryslog.Do(context.Background(), newLogger(&buf), b, func(_ context.Context) error {
+			return retry.RetryableError(io.EOF)
+		})
+		if err == nil {
+			t.Fatal("expected error")
+		}
+
+		got := entries(t, &buf)
+		if got, want := len(got), 2; got != want {
+			t.Fatalf("expected %v entries to be %v", got, want)
+		}
+		last := got[1]
+		if got, want := last["level"], "WARN"; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := last["msg"], "giving up"; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := last["attempt"], float64(2); got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("success_is_quiet", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		if err := retryslog.Do(context.Background(), newLogger(&buf), retry.NewConstant(1*time.Millisecond), func(_ context.Context) error {
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); got != "" {
+			t.Errorf("expected no output, got %q", got)
+		}
+	})
+}
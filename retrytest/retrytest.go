@@ -0,0 +1,126 @@
+// Package retrytest provides helpers for testing code that uses the retry
+// package. The helpers are deterministic and never sleep, so tests stay fast.
+package retrytest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+// CountingBackoff is a deterministic backoff that yields a fixed list of steps
+// and then stops. Instead of sleeping for each step, it returns a zero duration
+// and records the step, so tests can assert on the delays a retry loop would
+// have used without waiting for them. It is safe for concurrent use.
+type CountingBackoff struct {
+	lock  sync.Mutex
+	steps []time.Duration
+	calls int
+}
+
+var _ retry.Resettable = (*CountingBackoff)(nil)
+
+// NewCountingBackoff creates a new CountingBackoff that yields steps in order
+// and then stops. The steps are copied, so the caller may reuse the slice.
+func NewCountingBackoff(steps []time.Duration) *CountingBackoff {
+	s := make([]time.Duration, len(steps))
+	copy(s, steps)
+
+	return &CountingBackoff{
+		steps: s,
+	}
+}
+
+// Next implements retry.Backoff. It always returns a zero duration, and signals
+// stop once all steps have been yielded.
+func (b *CountingBackoff) Next() (time.Duration, bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.calls++
+	if b.calls > len(b.steps) {
+		return 0, true
+	}
+	return 0, false
+}
+
+// Reset implements retry.Resettable.
+func (b *CountingBackoff) Reset() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.calls = 0
+}
+
+// Calls returns the number of times Next was called, including the call that
+// signaled stop.
+func (b *CountingBackoff) Calls() int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.calls
+}
+
+// Steps returns the steps yielded so far, which are the delays the retry loop
+// would have slept for.
+func (b *CountingBackoff) Steps() []time.Duration {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	n := b.calls
+	if n > len(b.steps) {
+		n = len(b.steps)
+	}
+
+	steps := make([]time.Duration, n)
+	copy(steps, b.steps)
+	return steps
+}
+
+// FailNTimes returns a RetryFunc that returns a retryable error for its first n
+// invocations, and finalErr on every invocation after that. Pass a nil finalErr
+// to succeed after n failures. It is safe for concurrent use.
+func FailNTimes(n int, finalErr error) retry.RetryFunc {
+	var calls int64
+
+	return func(_ context.Context) error {
+		if i := atomic.AddInt64(&calls, 1); i <= int64(n) {
+			return retry.RetryableError(fmt.Errorf("retrytest: failure %d of %d", i, n))
+		}
+		return finalErr
+	}
+}
+
+// Sleeper is a retry.Sleeper that records each requested duration and returns
+// immediately, unless the context is already done. Use it with
+// retry.DoWithSleeper to test retry-heavy code without real sleeps. It is safe
+// for concurrent use.
+type Sleeper struct {
+	lock  sync.Mutex
+	slept []time.Duration
+}
+
+var _ retry.Sleeper = (*Sleeper)(nil)
+
+// Sleep implements retry.Sleeper.
+func (s *Sleeper) Sleep(ctx context.Context, d time.Duration) error {
+	s.lock.Lock()
+	s.slept = append(s.slept, d)
+	s.lock.Unlock()
+
+	return ctx.Err()
+}
+
+// Slept returns the durations requested so far, in order.
+func (s *Sleeper) Slept() []time.Duration {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	slept := make([]time.Duration, len(s.slept))
+	copy(slept, s.slept)
+	return slept
+}
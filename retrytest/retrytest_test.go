@@ -0,0 +1,117 @@
+package retrytest_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+	"github.com/sethvargo/go-retry/retrytest"
+)
+
+func TestCountingBackoff(t *testing.T) {
+	t.Parallel()
+
+	steps := []time.Duration{1 * time.Second, 2 * time.Second}
+	b := retrytest.NewCountingBackoff(steps)
+
+	for range steps {
+		val, stop := b.Next()
+		if stop {
+			t.Fatalf("should not stop")
+		}
+		if val != 0 {
+			t.Errorf("expected %v to be 0", val)
+		}
+	}
+	if _, stop := b.Next(); !stop {
+		t.Errorf("should stop")
+	}
+
+	if got, want := b.Calls(), 3; got != want {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+	if got, want := b.Steps(), steps; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+
+	b.Reset()
+	if got, want := b.Calls(), 0; got != want {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+}
+
+func TestFailNTimes(t *testing.T) {
+	t.Parallel()
+
+	t.Run("succeeds", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retrytest.NewCountingBackoff([]time.Duration{1 * time.Second, 2 * time.Second, 4 * time.Second})
+
+		if err := retry.Do(ctx, b, retrytest.FailNTimes(2, nil)); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := b.Steps(), []time.Duration{1 * time.Second, 2 * time.Second}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("final_error", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retrytest.NewCountingBackoff([]time.Duration{1 * time.Second, 2 * time.Second})
+
+		if err := retry.Do(ctx, b, retrytest.FailNTimes(1, io.EOF)); !errors.Is(err, io.EOF) {
+			t.Errorf("expected %#v to be %#v", err, io.EOF)
+		}
+	})
+
+	t.Run("exhausted", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retrytest.NewCountingBackoff([]time.Duration{1 * time.Second})
+
+		err := retry.Do(ctx, b, retrytest.FailNTimes(5, nil))
+		if !errors.Is(err, retry.ErrBackoffStopped) {
+			t.Errorf("expected %#v to be %#v", err, retry.ErrBackoffStopped)
+		}
+		if got, want := b.Calls(), 2; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+func TestSleeper(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	b := retry.WithMaxRetries(2, retry.NewExponential(1*time.Hour))
+
+	var s retrytest.Sleeper
+	f := retrytest.FailNTimes(2, nil)
+	if _, err := retry.DoWithSleeper(ctx, b, &s, func(ctx context.Context) (int, error) {
+		return 0, f(ctx)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := s.Slept(), []time.Duration{1 * time.Hour, 2 * time.Hour}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+}
+
+func ExampleFailNTimes() {
+	ctx := context.Background()
+	b := retrytest.NewCountingBackoff([]time.Duration{1 * time.Second, 2 * time.Second})
+
+	if err := retry.Do(ctx, b, retrytest.FailNTimes(1, nil)); err != nil {
+		// handle error
+	}
+}
@@ -0,0 +1,55 @@
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+// Sleeper waits between attempts of a retry loop. Sleep blocks for d or until
+// ctx is done, and returns the context's error in the latter case. Injecting a
+// Sleeper with DoWithSleeper lets tests record the requested durations and
+// return instantly instead of sleeping for real.
+type Sleeper interface {
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+var _ Sleeper = (SleeperFunc)(nil)
+
+// SleeperFunc is a Sleeper expressed as a function. It is a convenient way to
+// plug in a fake or virtual clock, so a retry loop advances simulated time
+// instead of waiting. An implementation should check ctx before waiting, so
+// that cancellation keeps taking precedence.
+type SleeperFunc func(ctx context.Context, d time.Duration) error
+
+// Sleep implements Sleeper.
+func (s SleeperFunc) Sleep(ctx context.Context, d time.Duration) error {
+	return s(ctx, d)
+}
+
+// DefaultSleeper is a Sleeper backed by a real timer, equivalent to how
+// DoWithData waits between attempts.
+var DefaultSleeper Sleeper = SleeperFunc(func(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+})
+
+// DoWithSleeper is like DoWithData, but waits between attempts by calling
+// s.Sleep instead of using a timer. s is called for every delay, including
+// zero, after the delay has been adjusted for the context's deadline and any
+// RetryAfterError. If Sleep returns an error, the retry loop returns it.
+func DoWithSleeper[T any](ctx context.Context, b Backoff, s Sleeper, f RetryWithDataFunc[T]) (T, error) {
+	return doWithData(ctx, b, f, loopOptions{
+		sleeper: s,
+	})
+}
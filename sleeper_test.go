@@ -0,0 +1,118 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestDoWithSleeper(t *testing.T) {
+	t.Parallel()
+
+	t.Run("records", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.WithMaxRetries(3, retry.NewExponential(1*time.Hour))
+
+		var slept []time.Duration
+		s := retry.SleeperFunc(func(_ context.Context, d time.Duration) error {
+			slept = append(slept, d)
+			return nil
+		})
+
+		start := time.Now()
+		_, err := retry.DoWithSleeper(ctx, b, s, func(_ context.Context) (int, error) {
+			return 0, retry.RetryableError(fmt.Errorf("oops"))
+		})
+		if !errors.Is(err, retry.ErrBackoffStopped) {
+			t.Errorf("expected %v to be %v", err, retry.ErrBackoffStopped)
+		}
+
+		if got, want := slept, []time.Duration{1 * time.Hour, 2 * time.Hour, 4 * time.Hour}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if elapsed := time.Since(start); elapsed > 1*time.Second {
+			t.Errorf("expected not to sleep, took %v", elapsed)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.NewConstant(1 * time.Second)
+
+		errSleep := errors.New("sleep failed")
+		s := retry.SleeperFunc(func(_ context.Context, _ time.Duration) error {
+			return errSleep
+		})
+
+		var calls int
+		_, err := retry.DoWithSleeper(ctx, b, s, func(_ context.Context) (int, error) {
+			calls++
+			return 0, retry.RetryableError(fmt.Errorf("oops"))
+		})
+		if got, want := err, errSleep; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := calls, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+func TestDefaultSleeper(t *testing.T) {
+	t.Parallel()
+
+	t.Run("sleeps", func(t *testing.T) {
+		t.Parallel()
+
+		start := time.Now()
+		if err := retry.DefaultSleeper.Sleep(context.Background(), 10*time.Millisecond); err != nil {
+			t.Fatal(err)
+		}
+		if elapsed, min := time.Since(start), 10*time.Millisecond; elapsed < min {
+			t.Errorf("expected %v to be at least %v", elapsed, min)
+		}
+	})
+
+	t.Run("context_canceled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		if got, want := retry.DefaultSleeper.Sleep(ctx, 1*time.Hour), context.Canceled; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+func ExampleSleeperFunc() {
+	ctx := context.Background()
+
+	// Advance a virtual clock instead of sleeping.
+	var now time.Duration
+	s := retry.SleeperFunc(func(ctx context.Context, d time.Duration) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		now += d
+		return nil
+	})
+
+	b := retry.WithMaxRetries(3, retry.NewExponential(1*time.Second))
+	_, _ = retry.DoWithSleeper(ctx, b, s, func(_ context.Context) (int, error) {
+		return 0, retry.RetryableError(errors.New("not ready"))
+	})
+
+	fmt.Println(now)
+	// Output:
+	// 7s
+}
@@ -0,0 +1,76 @@
+package retry
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// StateMarshaler is a Backoff whose progress can be serialized and restored,
+// so that a retry schedule can survive a process restart. NewExponential,
+// NewExponentialBase, and NewFibonacci return backoffs that implement
+// StateMarshaler.
+//
+// The serialized state is a JSON object with a "v" field holding the format
+// version, which is currently 1. The format is stable: newer releases of this
+// package restore state written by older ones, and a version that is not
+// understood is rejected with an error rather than misread. The state only
+// records progress, such as the attempt count; the configuration, such as the
+// base, comes from the constructor and must match on restore.
+type StateMarshaler interface {
+	Backoff
+
+	// MarshalState returns the serialized progress of the backoff.
+	MarshalState() ([]byte, error)
+
+	// RestoreState replaces the progress of the backoff with data returned by
+	// MarshalState. It returns an error if data is malformed, or was produced
+	// by a different kind of backoff or configuration.
+	RestoreState(data []byte) error
+}
+
+var (
+	_ StateMarshaler = (*exponentialBackoff)(nil)
+	_ StateMarshaler = (*multiplierBackoff)(nil)
+	_ StateMarshaler = (*fibonacciBackoff)(nil)
+)
+
+// stateVersion is the current version of the serialized state format.
+const stateVersion = 1
+
+// backoffState is the serialized form of a backoff's progress. Fields are only
+// ever added, never renamed or repurposed, to keep the format stable.
+type backoffState struct {
+	Version    int           `json:"v"`
+	Kind       string        `json:"kind"`
+	Base       time.Duration `json:"base"`
+	Multiplier float64       `json:"multiplier,omitempty"`
+	Attempt    uint64        `json:"attempt,omitempty"`
+	Prev       time.Duration `json:"prev,omitempty"`
+	Curr       time.Duration `json:"curr,omitempty"`
+}
+
+func marshalState(s backoffState) ([]byte, error) {
+	s.Version = stateVersion
+	return json.Marshal(s)
+}
+
+// unmarshalState decodes data and checks that it was produced by a backoff of
+// the given kind and base.
+func unmarshalState(data []byte, kind string, base time.Duration) (backoffState, error) {
+	var s backoffState
+	if err := json.Unmarshal(data, &s); err != nil {
+		return s, fmt.Errorf("failed to decode state: %w", err)
+	}
+
+	if s.Version != stateVersion {
+		return s, fmt.Errorf("unsupported state version %d", s.Version)
+	}
+	if s.Kind != kind {
+		return s, fmt.Errorf("state is for a %q backoff, not %q", s.Kind, kind)
+	}
+	if s.Base != base {
+		return s, fmt.Errorf("state is for base %s, not %s", s.Base, base)
+	}
+	return s, nil
+}
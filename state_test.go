@@ -0,0 +1,127 @@
+package retry_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestStateMarshaler(t *testing.T) {
+	t.Parallel()
+
+	mustBackoff := func(b retry.Backoff, err error) retry.Backoff {
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+
+	cases := []struct {
+		name       string
+		newBackoff func() retry.Backoff
+	}{
+		{
+			name: "exponential",
+			newBackoff: func() retry.Backoff {
+				return retry.NewExponential(1 * time.Second)
+			},
+		},
+		{
+			name: "multiplier",
+			newBackoff: func() retry.Backoff {
+				return mustBackoff(retry.NewExponentialBase(1*time.Second, 1.5))
+			},
+		},
+		{
+			name: "fibonacci",
+			newBackoff: func() retry.Backoff {
+				return retry.NewFibonacci(1 * time.Second)
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			b := tc.newBackoff().(retry.StateMarshaler)
+			retry.Simulate(b, 4)
+
+			data, err := b.MarshalState()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			restored := tc.newBackoff().(retry.StateMarshaler)
+			if err := restored.RestoreState(data); err != nil {
+				t.Fatal(err)
+			}
+
+			if got, want := retry.Simulate(restored, 5), retry.Simulate(b, 5); !reflect.DeepEqual(got, want) {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		exp, err := retry.NewExponential(1 * time.Second).(retry.StateMarshaler).MarshalState()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		cases := []struct {
+			name string
+			b    retry.Backoff
+			data []byte
+		}{
+			{
+				name: "malformed",
+				b:    retry.NewExponential(1 * time.Second),
+				data: []byte("{"),
+			},
+			{
+				name: "version",
+				b:    retry.NewExponential(1 * time.Second),
+				data: []byte(`{"v":99,"kind":"exponential","base":1000000000}`),
+			},
+			{
+				name: "kind",
+				b:    retry.NewFibonacci(1 * time.Second),
+				data: exp,
+			},
+			{
+				name: "base",
+				b:    retry.NewExponential(2 * time.Second),
+				data: exp,
+			},
+			{
+				name: "multiplier",
+				b:    mustBackoff(retry.NewExponentialBase(1*time.Second, 1.5)),
+				data: exp,
+			},
+			{
+				name: "fibonacci_state",
+				b:    retry.NewFibonacci(1 * time.Second),
+				data: []byte(`{"v":1,"kind":"fibonacci","base":1000000000,"prev":5000000000,"curr":1000000000}`),
+			},
+		}
+
+		for _, tc := range cases {
+			tc := tc
+
+			t.Run(tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				if err := tc.b.(retry.StateMarshaler).RestoreState(tc.data); err == nil {
+					t.Error("expected error")
+				}
+			})
+		}
+	})
+}